@@ -0,0 +1,248 @@
+package interceptors
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carousell/Orion/orion/modifiers"
+	"github.com/carousell/Orion/utils/headers"
+	"github.com/carousell/Orion/utils/log"
+	"github.com/golang/protobuf/jsonpb"
+	protov1 "github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	//RedactedValue replaces the value of redacted string fields in logged payloads
+	RedactedValue = "[REDACTED]"
+)
+
+var (
+	globalAccessLog = &accessLogger{}
+)
+
+//AccessLogConfig is the configuration for access logs
+type AccessLogConfig struct {
+	// Enabled turns on access logs
+	Enabled bool
+	// Metadata is the list of metadata keys (HTTP headers for HTTP requests) included in access logs
+	Metadata []string
+	// PayloadSampleRate is the fraction of requests, between 0 and 1, logged with their request and response payloads
+	PayloadSampleRate float64
+	// RedactFields are the proto field names ("email") or full names ("pkg.User.email") redacted from payloads,
+	// fields marked with the [debug_redact = true] field option are always redacted
+	RedactFields []string
+}
+
+type accessLogger struct {
+	mu     sync.RWMutex
+	config AccessLogConfig
+	redact map[string]bool
+}
+
+func newAccessLogger(config AccessLogConfig) *accessLogger {
+	a := &accessLogger{}
+	a.update(config)
+	return a
+}
+
+func (a *accessLogger) update(config AccessLogConfig) {
+	redact := make(map[string]bool)
+	for _, field := range config.RedactFields {
+		redact[strings.TrimSpace(field)] = true
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.config = config
+	a.redact = redact
+}
+
+func (a *accessLogger) get() (AccessLogConfig, map[string]bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.config, a.redact
+}
+
+// metadataValue fetches key from incoming metadata or, for HTTP requests, from the whitelisted request headers
+func metadataValue(ctx context.Context, key string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return headers.RequestHeadersFromContext(ctx).Get(key)
+}
+
+func (a *accessLogger) fields(ctx context.Context, config AccessLogConfig, fullMethod string, err error, took time.Duration) []interface{} {
+	transport := "grpc"
+	if modifiers.IsHTTPRequest(ctx) {
+		transport = "http"
+	}
+	fields := []interface{}{"access", fullMethod, "transport", transport, "code", status.Code(err).String(), "took", took}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields = append(fields, "peer", p.Addr.String())
+	}
+	for _, key := range config.Metadata {
+		if value := metadataValue(ctx, key); value != "" {
+			fields = append(fields, strings.ToLower(key), value)
+		}
+	}
+	if err != nil {
+		fields = append(fields, "error", err.Error())
+	}
+	return fields
+}
+
+func sampled(rate float64) bool {
+	return rate > 0 && (rate >= 1 || rand.Float64() < rate)
+}
+
+//AccessLogInterceptor logs method, peer, status code, request/response sizes and the configured metadata of
+//each request, payloads of sampled requests are logged as JSON with sensitive fields redacted
+func AccessLogInterceptor(config AccessLogConfig) grpc.UnaryServerInterceptor {
+	return accessLogInterceptor(newAccessLogger(config))
+}
+
+func accessLogInterceptor(a *accessLogger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		config, redact := a.get()
+		if !config.Enabled {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		fields := a.fields(ctx, config, info.FullMethod, err, time.Since(start))
+		if m, ok := req.(protov1.Message); ok {
+			fields = append(fields, "req_size", proto.Size(protov1.MessageV2(m)))
+		}
+		if m, ok := resp.(protov1.Message); ok && err == nil {
+			fields = append(fields, "resp_size", proto.Size(protov1.MessageV2(m)))
+		}
+		if sampled(config.PayloadSampleRate) {
+			fields = append(fields, "request", RedactedJSON(req, redact))
+			if err == nil {
+				fields = append(fields, "response", RedactedJSON(resp, redact))
+			}
+		}
+		log.Info(ctx, fields...)
+		return resp, err
+	}
+}
+
+type accessLogStream struct {
+	grpc.ServerStream
+	sent, received int
+}
+
+func (s *accessLogStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent++
+	}
+	return err
+}
+
+func (s *accessLogStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received++
+	}
+	return err
+}
+
+//AccessLogStreamInterceptor logs method, peer, status code, message counts and the configured metadata of each stream
+func AccessLogStreamInterceptor(config AccessLogConfig) grpc.StreamServerInterceptor {
+	return accessLogStreamInterceptor(newAccessLogger(config))
+}
+
+func accessLogStreamInterceptor(a *accessLogger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		config, _ := a.get()
+		if !config.Enabled {
+			return handler(srv, stream)
+		}
+		start := time.Now()
+		wrapped := &accessLogStream{ServerStream: stream}
+		err := handler(srv, wrapped)
+		fields := a.fields(stream.Context(), config, info.FullMethod, err, time.Since(start))
+		fields = append(fields, "msgs_received", wrapped.received, "msgs_sent", wrapped.sent)
+		log.Info(stream.Context(), fields...)
+		return err
+	}
+}
+
+//SetAccessLog updates the configuration used by GlobalAccessLogInterceptor
+func SetAccessLog(config AccessLogConfig) {
+	globalAccessLog.update(config)
+}
+
+//GlobalAccessLogInterceptor logs requests with the configuration set through SetAccessLog
+func GlobalAccessLogInterceptor() grpc.UnaryServerInterceptor {
+	return accessLogInterceptor(globalAccessLog)
+}
+
+//GlobalAccessLogStreamInterceptor logs streams with the configuration set through SetAccessLog
+func GlobalAccessLogStreamInterceptor() grpc.StreamServerInterceptor {
+	return accessLogStreamInterceptor(globalAccessLog)
+}
+
+//RedactedJSON renders a proto message as JSON with jsonpb, fields in redact (by name or full name)
+//and fields marked with [debug_redact = true] are redacted
+func RedactedJSON(msg interface{}, redact map[string]bool) string {
+	m, ok := msg.(protov1.Message)
+	if !ok || m == nil {
+		return ""
+	}
+	clone := proto.Clone(protov1.MessageV2(m))
+	redactMessage(clone.ProtoReflect(), redact)
+	data, err := (&jsonpb.Marshaler{OrigName: true}).MarshalToString(protov1.MessageV1(clone))
+	if err != nil {
+		return ""
+	}
+	return data
+}
+
+func shouldRedact(fd protoreflect.FieldDescriptor, redact map[string]bool) bool {
+	if opts, ok := fd.Options().(*descriptorpb.FieldOptions); ok && opts.GetDebugRedact() {
+		return true
+	}
+	return redact[string(fd.Name())] || redact[string(fd.FullName())]
+}
+
+func redactMessage(m protoreflect.Message, redact map[string]bool) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case shouldRedact(fd, redact):
+			if fd.Kind() == protoreflect.StringKind && !fd.IsList() && !fd.IsMap() {
+				m.Set(fd, protoreflect.ValueOfString(RedactedValue))
+			} else {
+				m.Clear(fd)
+			}
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					redactMessage(mv.Message(), redact)
+					return true
+				})
+			}
+		case fd.IsList():
+			if fd.Message() != nil {
+				for i := 0; i < v.List().Len(); i++ {
+					redactMessage(v.List().Get(i).Message(), redact)
+				}
+			}
+		case fd.Message() != nil:
+			redactMessage(v.Message(), redact)
+		}
+		return true
+	})
+}
@@ -0,0 +1,131 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/carousell/Orion/utils/log"
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type recordingLogger struct {
+	entries [][]interface{}
+}
+
+func (r *recordingLogger) Log(ctx context.Context, level loggers.Level, skip int, args ...interface{}) {
+	r.entries = append(r.entries, args)
+}
+
+func (r *recordingLogger) SetLevel(level loggers.Level) {}
+
+func (r *recordingLogger) GetLevel() loggers.Level {
+	return loggers.DebugLevel
+}
+
+func setupAccessLog(t *testing.T) *recordingLogger {
+	previous := log.GetLogger()
+	recorder := &recordingLogger{}
+	log.SetLogger(log.NewLogger(recorder))
+	t.Cleanup(func() { log.SetLogger(previous) })
+	return recorder
+}
+
+func logFields(args []interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	for i := 0; i+1 < len(args); i += 2 {
+		fields[args[i].(string)] = args[i+1]
+	}
+	return fields
+}
+
+func TestRedactedJSON(t *testing.T) {
+	req := &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "email", Description: "user@example.com is taken"},
+		},
+	}
+	assert.Equal(t, `{"field_violations":[{"field":"email","description":"[REDACTED]"}]}`,
+		RedactedJSON(req, map[string]bool{"description": true}))
+	assert.Equal(t, `{"field_violations":[{"field":"[REDACTED]","description":"user@example.com is taken"}]}`,
+		RedactedJSON(req, map[string]bool{"google.rpc.BadRequest.FieldViolation.field": true}))
+	// repeated and message fields are cleared
+	assert.Equal(t, `{}`, RedactedJSON(req, map[string]bool{"field_violations": true}))
+	// the original message is untouched
+	assert.Equal(t, "user@example.com is taken", req.FieldViolations[0].Description)
+	assert.Equal(t, "", RedactedJSON("not a proto", nil))
+}
+
+func TestAccessLogInterceptor(t *testing.T) {
+	recorder := setupAccessLog(t)
+	interceptor := AccessLogInterceptor(AccessLogConfig{
+		Enabled:           true,
+		Metadata:          []string{"x-user-id"},
+		PayloadSampleRate: 1,
+		RedactFields:      []string{"description"},
+	})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-user-id", "42"))
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234}})
+	req := &errdetails.BadRequest_FieldViolation{Field: "email", Description: "secret"}
+	resp := &errdetails.BadRequest_FieldViolation{Field: "ok"}
+
+	_, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return resp, nil
+		})
+	assert.NoError(t, err)
+	if assert.Len(t, recorder.entries, 1) {
+		fields := logFields(recorder.entries[0])
+		assert.Equal(t, "/pkg.Svc/Get", fields["access"])
+		assert.Equal(t, "grpc", fields["transport"])
+		assert.Equal(t, "OK", fields["code"])
+		assert.Equal(t, "10.0.0.1:1234", fields["peer"])
+		assert.Equal(t, "42", fields["x-user-id"])
+		assert.Equal(t, 15, fields["req_size"])
+		assert.Equal(t, 4, fields["resp_size"])
+		assert.Equal(t, `{"field":"email","description":"[REDACTED]"}`, fields["request"])
+		assert.Equal(t, `{"field":"ok"}`, fields["response"])
+	}
+
+	// errors are logged without a response
+	_, err = interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.NotFound, "missing")
+		})
+	assert.Error(t, err)
+	if assert.Len(t, recorder.entries, 2) {
+		fields := logFields(recorder.entries[1])
+		assert.Equal(t, "NotFound", fields["code"])
+		assert.Nil(t, fields["response"])
+		assert.Nil(t, fields["resp_size"])
+	}
+}
+
+func TestAccessLogDisabled(t *testing.T) {
+	recorder := setupAccessLog(t)
+	SetAccessLog(AccessLogConfig{})
+	_, err := GlobalAccessLogInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+	assert.NoError(t, err)
+	assert.Empty(t, recorder.entries)
+
+	SetAccessLog(AccessLogConfig{Enabled: true})
+	defer SetAccessLog(AccessLogConfig{})
+	GlobalAccessLogInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+	if assert.Len(t, recorder.entries, 1) {
+		// payloads are not sampled by default
+		assert.Nil(t, logFields(recorder.entries[0])["request"])
+	}
+}
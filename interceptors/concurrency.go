@@ -0,0 +1,233 @@
+package interceptors
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	//DefaultConcurrencyInitialLimit is the initial concurrency limit used when none is configured
+	DefaultConcurrencyInitialLimit = 100
+	//DefaultConcurrencyMaxLimit is the maximum concurrency limit used when none is configured
+	DefaultConcurrencyMaxLimit = 1000
+	//DefaultConcurrencyBackoffRatio is the ratio the limit is multiplied with on overload when none is configured
+	DefaultConcurrencyBackoffRatio = 0.9
+	//DefaultConcurrencyLatencyThreshold is the latency above which a request is treated as overload when none is configured
+	DefaultConcurrencyLatencyThreshold = time.Second
+)
+
+var (
+	globalConcurrencyLimiter = NewConcurrencyLimiter("server", ConcurrencyLimitConfig{})
+
+	concurrencyMetricsOnce sync.Once
+	concurrencyLimitGauge  = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "orion",
+		Name:      "concurrency_limit",
+		Help:      "The current limit of in flight requests.",
+	}, []string{"limiter"})
+	concurrencyInflightGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "orion",
+		Name:      "concurrency_inflight",
+		Help:      "The number of requests in flight.",
+	}, []string{"limiter"})
+	concurrencyShedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "orion",
+		Name:      "concurrency_shed_total",
+		Help:      "The number of requests rejected by the concurrency limiter.",
+	}, []string{"limiter"})
+)
+
+//ConcurrencyLimitConfig is the configuration for the AIMD concurrency limiter
+type ConcurrencyLimitConfig struct {
+	// Enabled turns on load shedding, requests are never rejected when false
+	Enabled bool
+	// InitialLimit is the number of in flight requests allowed at start
+	InitialLimit int
+	// MinLimit is the lowest the limit is reduced to
+	MinLimit int
+	// MaxLimit is the highest the limit is increased to
+	MaxLimit int
+	// BackoffRatio is multiplied with the limit when overload is detected, between 0.5 and 1
+	BackoffRatio float64
+	// LatencyThreshold in milliseconds, requests slower than this or failing with DeadlineExceeded are treated as overload
+	LatencyThreshold int
+}
+
+func (c ConcurrencyLimitConfig) withDefaults() ConcurrencyLimitConfig {
+	if c.MinLimit <= 0 {
+		c.MinLimit = 1
+	}
+	if c.MaxLimit <= 0 {
+		c.MaxLimit = DefaultConcurrencyMaxLimit
+	}
+	if c.MaxLimit < c.MinLimit {
+		c.MaxLimit = c.MinLimit
+	}
+	if c.InitialLimit <= 0 {
+		c.InitialLimit = DefaultConcurrencyInitialLimit
+	}
+	c.InitialLimit = int(math.Min(math.Max(float64(c.InitialLimit), float64(c.MinLimit)), float64(c.MaxLimit)))
+	if c.BackoffRatio < 0.5 || c.BackoffRatio >= 1 {
+		c.BackoffRatio = DefaultConcurrencyBackoffRatio
+	}
+	if c.LatencyThreshold <= 0 {
+		c.LatencyThreshold = int(DefaultConcurrencyLatencyThreshold / time.Millisecond)
+	}
+	return c
+}
+
+//ConcurrencyLimiter bounds the number of in flight requests, the limit is adapted using
+//additive increase/multiplicative decrease based on observed latency
+type ConcurrencyLimiter struct {
+	mu       sync.Mutex
+	name     string
+	config   ConcurrencyLimitConfig
+	limit    float64
+	inflight int
+}
+
+//NewConcurrencyLimiter creates a new ConcurrencyLimiter, name is used as the 'limiter' label of exported metrics
+func NewConcurrencyLimiter(name string, config ConcurrencyLimitConfig) *ConcurrencyLimiter {
+	concurrencyMetricsOnce.Do(func() {
+		prometheus.Register(concurrencyLimitGauge)
+		prometheus.Register(concurrencyInflightGauge)
+		prometheus.Register(concurrencyShedCounter)
+	})
+	l := &ConcurrencyLimiter{name: name}
+	l.Update(config)
+	return l
+}
+
+//Update replaces the configuration of this ConcurrencyLimiter and resets the limit to InitialLimit
+func (l *ConcurrencyLimiter) Update(config ConcurrencyLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.config = config.withDefaults()
+	l.limit = float64(l.config.InitialLimit)
+	l.report()
+}
+
+//Limit returns the current limit and number of in flight requests
+func (l *ConcurrencyLimiter) Limit() (limit int, inflight int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit), l.inflight
+}
+
+// report must be called with l.mu held
+func (l *ConcurrencyLimiter) report() {
+	concurrencyLimitGauge.WithLabelValues(l.name).Set(math.Floor(l.limit))
+	concurrencyInflightGauge.WithLabelValues(l.name).Set(float64(l.inflight))
+}
+
+//Acquire reserves a slot for a request, it returns false when the request should be shed.
+//The returned function must be called with the outcome of the request once it finishes
+func (l *ConcurrencyLimiter) Acquire() (func(latency time.Duration, err error), bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.config.Enabled {
+		return func(time.Duration, error) {}, true
+	}
+	if l.inflight >= int(l.limit) {
+		concurrencyShedCounter.WithLabelValues(l.name).Inc()
+		return nil, false
+	}
+	l.inflight++
+	l.report()
+	var once sync.Once
+	return func(latency time.Duration, err error) {
+		once.Do(func() { l.release(latency, err) })
+	}, true
+}
+
+func (l *ConcurrencyLimiter) release(latency time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	inflight := l.inflight
+	if l.inflight > 0 {
+		l.inflight--
+	}
+	threshold := time.Duration(l.config.LatencyThreshold) * time.Millisecond
+	switch {
+	case latency < 0:
+		// no latency sample (streams), only track in flight requests
+	case status.Code(err) == codes.DeadlineExceeded || latency > threshold:
+		l.limit = math.Max(float64(l.config.MinLimit), l.limit*l.config.BackoffRatio)
+	case inflight*2 >= int(l.limit):
+		// only grow the limit when it is being used
+		l.limit = math.Min(float64(l.config.MaxLimit), l.limit+1)
+	}
+	l.report()
+}
+
+func isFilteredMethod(fullMethod string) bool {
+	for _, name := range FilterMethods {
+		if strings.Contains(fullMethod, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func shedError(fullMethod string) error {
+	return status.Error(codes.Unavailable, "server overloaded, request to "+fullMethod+" shed")
+}
+
+//ConcurrencyLimitInterceptor fails fast with codes.Unavailable when the limiter has no capacity left,
+//health checks are never shed
+func ConcurrencyLimitInterceptor(limiter *ConcurrencyLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		if isFilteredMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		done, ok := limiter.Acquire()
+		if !ok {
+			return nil, shedError(info.FullMethod)
+		}
+		defer func(start time.Time) {
+			done(time.Since(start), err)
+		}(time.Now())
+		return handler(ctx, req)
+	}
+}
+
+//ConcurrencyLimitStreamInterceptor sheds streams when the limiter has no capacity left,
+//streams count as in flight requests but their duration does not change the limit
+func ConcurrencyLimitStreamInterceptor(limiter *ConcurrencyLimiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		if isFilteredMethod(info.FullMethod) {
+			return handler(srv, stream)
+		}
+		done, ok := limiter.Acquire()
+		if !ok {
+			return shedError(info.FullMethod)
+		}
+		defer func() {
+			done(-1, err)
+		}()
+		return handler(srv, stream)
+	}
+}
+
+//SetConcurrencyLimit updates the configuration of the global concurrency limiter
+func SetConcurrencyLimit(config ConcurrencyLimitConfig) {
+	globalConcurrencyLimiter.Update(config)
+}
+
+//GlobalConcurrencyLimitInterceptor applies the global concurrency limiter configured through SetConcurrencyLimit
+func GlobalConcurrencyLimitInterceptor() grpc.UnaryServerInterceptor {
+	return ConcurrencyLimitInterceptor(globalConcurrencyLimiter)
+}
+
+//GlobalConcurrencyLimitStreamInterceptor applies the global concurrency limiter configured through SetConcurrencyLimit to streams
+func GlobalConcurrencyLimitStreamInterceptor() grpc.StreamServerInterceptor {
+	return ConcurrencyLimitStreamInterceptor(globalConcurrencyLimiter)
+}
@@ -0,0 +1,85 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConcurrencyLimiterDisabled(t *testing.T) {
+	l := NewConcurrencyLimiter("test", ConcurrencyLimitConfig{InitialLimit: 1})
+	for i := 0; i < 5; i++ {
+		_, ok := l.Acquire()
+		assert.True(t, ok)
+	}
+	_, inflight := l.Limit()
+	assert.Equal(t, 0, inflight)
+}
+
+func TestConcurrencyLimiterAIMD(t *testing.T) {
+	l := NewConcurrencyLimiter("test", ConcurrencyLimitConfig{
+		Enabled:          true,
+		InitialLimit:     2,
+		MinLimit:         1,
+		MaxLimit:         3,
+		BackoffRatio:     0.5,
+		LatencyThreshold: 100,
+	})
+	done1, ok := l.Acquire()
+	assert.True(t, ok)
+	done2, ok := l.Acquire()
+	assert.True(t, ok)
+	_, ok = l.Acquire()
+	assert.False(t, ok, "requests above the limit should be shed")
+
+	// fast requests while the limit is in use increase it
+	done1(time.Millisecond, nil)
+	limit, inflight := l.Limit()
+	assert.Equal(t, 3, limit)
+	assert.Equal(t, 1, inflight)
+
+	// slow requests decrease it
+	done2(time.Second, nil)
+	limit, inflight = l.Limit()
+	assert.Equal(t, 1, limit)
+	assert.Equal(t, 0, inflight)
+
+	// release is idempotent
+	done2(time.Second, nil)
+	_, inflight = l.Limit()
+	assert.Equal(t, 0, inflight)
+
+	done, _ := l.Acquire()
+	done(time.Millisecond, status.Error(codes.DeadlineExceeded, "timeout"))
+	limit, _ = l.Limit()
+	assert.Equal(t, 1, limit, "limit should not go below MinLimit")
+}
+
+func TestConcurrencyLimitInterceptor(t *testing.T) {
+	l := NewConcurrencyLimiter("test", ConcurrencyLimitConfig{Enabled: true, InitialLimit: 1, MaxLimit: 1})
+	interceptor := ConcurrencyLimitInterceptor(l)
+	block := make(chan struct{})
+	started := make(chan struct{})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		close(started)
+		<-block
+		return "ok", nil
+	}
+	go interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"}, handler)
+	<-started
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"}, handler)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// health checks are never shed
+	resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return "healthy", nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "healthy", resp)
+	close(block)
+}
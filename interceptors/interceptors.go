@@ -14,7 +14,7 @@ import (
 
 var (
 	//FilterMethods is the list of methods that are filtered by default
-	FilterMethods = []string{"Healthcheck", "HealthCheck"}
+	FilterMethods = []string{"Healthcheck", "HealthCheck", "grpc.health.v1.Health"}
 )
 
 func filterFromZipkin(ctx context.Context, fullMethodName string) bool {
@@ -32,7 +32,12 @@ func DefaultInterceptors() []grpc.UnaryServerInterceptor {
 		ResponseTimeLoggingInterceptor(),
 		grpc_ctxtags.UnaryServerInterceptor(),
 		grpc_opentracing.UnaryServerInterceptor(grpc_opentracing.WithFilterFunc(filterFromZipkin)),
+		OpenTelemetryInterceptor(),
+		GlobalAccessLogInterceptor(),
 		grpc_prometheus.UnaryServerInterceptor,
+		GlobalRateLimitInterceptor(),
+		GlobalConcurrencyLimitInterceptor(),
+		GlobalTimeoutInterceptor(),
 		ServerErrorInterceptor(),
 		NewRelicInterceptor(),
 		PanicRecoveryInterceptor(),
@@ -50,20 +55,24 @@ func DefaultClientInterceptors(address string) []grpc.UnaryClientInterceptor {
 		// Because the trace headers propagated to the caller service should be of the current span,
 		// not the upstream span present in the incoming metadata.
 		GRPCClientInterceptor(),
+		OpenTelemetryClientInterceptor(),
 	}
 }
 
 //DefaultStreamClientInterceptors are the set of default interceptors that should be applied to all client streaming calls
 func DefaultStreamClientInterceptors() []grpc.StreamClientInterceptor {
 	/*
-		compare to DefaultClientInterceptors, we don't have hystrix and newrelic interceptors here
+		compare to DefaultClientInterceptors, we don't have a newrelic interceptor here
 		because a stream call includes three parts: create connection, streaming, and close
-		as an interceptor, it's not easy to differentiate them
+		as an interceptor, it's not easy to differentiate them, hystrix only tracks the stream
+		until its first message (see HystrixStreamClientInterceptor)
 	*/
 	return []grpc.StreamClientInterceptor{
 		grpc_retry.StreamClientInterceptor(),
+		HystrixStreamClientInterceptor(),
 		grpc_opentracing.StreamClientInterceptor(),
 		ForwardMetadataStreamClientInterceptor(),
+		OpenTelemetryStreamClientInterceptor(),
 	}
 }
 
@@ -73,7 +82,12 @@ func DefaultStreamInterceptors() []grpc.StreamServerInterceptor {
 		ResponseTimeLoggingStreamInterceptor(),
 		grpc_ctxtags.StreamServerInterceptor(),
 		grpc_opentracing.StreamServerInterceptor(),
+		OpenTelemetryStreamInterceptor(),
+		GlobalAccessLogStreamInterceptor(),
 		grpc_prometheus.StreamServerInterceptor,
+		GlobalRateLimitStreamInterceptor(),
+		GlobalConcurrencyLimitStreamInterceptor(),
+		GlobalTimeoutStreamInterceptor(),
 		ServerErrorStreamInterceptor(),
 	}
 }
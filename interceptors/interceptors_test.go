@@ -10,7 +10,7 @@ import (
 
 	"google.golang.org/grpc/codes"
 
-	"github.com/afex/hystrix-go/hystrix"
+	"github.com/carousell/Orion/utils/breaker"
 	"github.com/carousell/Orion/utils/errors"
 	"google.golang.org/grpc"
 )
@@ -52,7 +52,7 @@ func TestHystrixClientInterceptorTimeoutError(t *testing.T) {
 		invoker,
 	)
 
-	if !strings.Contains(err.Error(), hystrix.ErrTimeout.Error()) {
+	if !strings.Contains(err.Error(), breaker.ErrTimeout.Error()) {
 		t.Errorf("hystrixInterceptor doesn't propagate the hystrix timeout error properly, got:%v", err)
 	}
 }
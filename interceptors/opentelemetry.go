@@ -0,0 +1,178 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carousell/Orion/utils/errors/notifier"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	//InstrumentationName is the name of the OpenTelemetry tracer and meter used by Orion
+	InstrumentationName = "github.com/carousell/Orion"
+)
+
+var (
+	otelInstrumentsOnce sync.Once
+	otelServerDuration  metric.Float64Histogram
+	otelClientDuration  metric.Float64Histogram
+)
+
+// metadataCarrier adapts gRPC metadata to propagation.TextMapCarrier
+type metadataCarrier metadata.MD
+
+func (m metadataCarrier) Get(key string) string {
+	if values := metadata.MD(m).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (m metadataCarrier) Set(key, value string) {
+	metadata.MD(m).Set(key, value)
+}
+
+func (m metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func otelInstruments() {
+	otelInstrumentsOnce.Do(func() {
+		// the global meter delegates to the provider set by the initializer, even if it is set later
+		meter := otel.Meter(InstrumentationName)
+		otelServerDuration, _ = meter.Float64Histogram("rpc.server.duration",
+			metric.WithDescription("Duration of handled requests."), metric.WithUnit("ms"))
+		otelClientDuration, _ = meter.Float64Histogram("rpc.client.duration",
+			metric.WithDescription("Duration of outgoing requests."), metric.WithUnit("ms"))
+	})
+}
+
+func rpcAttributes(fullMethod string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.String("rpc.system", "grpc")}
+	parts := strings.SplitN(strings.TrimPrefix(fullMethod, "/"), "/", 2)
+	if len(parts) == 2 {
+		attrs = append(attrs, attribute.String("rpc.service", parts[0]), attribute.String("rpc.method", parts[1]))
+	}
+	return attrs
+}
+
+func startOTelSpan(ctx context.Context, fullMethod string, kind trace.SpanKind) (context.Context, trace.Span) {
+	if kind == trace.SpanKindServer && !trace.SpanContextFromContext(ctx).IsValid() {
+		// HTTP requests are extracted by the HTTP handler, gRPC requests carry traceparent in metadata
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+		}
+	}
+	ctx, span := otel.Tracer(InstrumentationName).Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(kind), trace.WithAttributes(rpcAttributes(fullMethod)...))
+	if kind == trace.SpanKindServer {
+		if sc := span.SpanContext(); sc.HasTraceID() {
+			// correlate logs and error notifications with the trace
+			ctx = notifier.UpdateTraceId(ctx, sc.TraceID().String())
+		}
+	}
+	return ctx, span
+}
+
+func endOTelSpan(ctx context.Context, span trace.Span, histogram metric.Float64Histogram, fullMethod string, start time.Time, err error) {
+	statusCode := attribute.Int64("rpc.grpc.status_code", int64(status.Code(err)))
+	span.SetAttributes(statusCode)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+	if histogram != nil {
+		histogram.Record(ctx, float64(time.Since(start))/float64(time.Millisecond),
+			metric.WithAttributes(append(rpcAttributes(fullMethod), statusCode)...))
+	}
+}
+
+func injectOTel(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	// Set replaces the upstream traceparent forwarded by ForwardMetadataInterceptor
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+//OpenTelemetryInterceptor starts an OpenTelemetry server span for each request, continuing the
+//W3C trace context sent by the caller, and records request durations
+func OpenTelemetryInterceptor() grpc.UnaryServerInterceptor {
+	otelInstruments()
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		if isFilteredMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		ctx, span := startOTelSpan(ctx, info.FullMethod, trace.SpanKindServer)
+		defer func() {
+			endOTelSpan(ctx, span, otelServerDuration, info.FullMethod, start, err)
+		}()
+		return handler(ctx, req)
+	}
+}
+
+//OpenTelemetryStreamInterceptor starts an OpenTelemetry server span that covers the entire stream
+func OpenTelemetryStreamInterceptor() grpc.StreamServerInterceptor {
+	otelInstruments()
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		if isFilteredMethod(info.FullMethod) {
+			return handler(srv, stream)
+		}
+		start := time.Now()
+		ctx, span := startOTelSpan(stream.Context(), info.FullMethod, trace.SpanKindServer)
+		defer func() {
+			endOTelSpan(ctx, span, otelServerDuration, info.FullMethod, start, err)
+		}()
+		return handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+//OpenTelemetryClientInterceptor starts an OpenTelemetry client span for each call and propagates
+//the W3C trace context to the called service
+func OpenTelemetryClientInterceptor() grpc.UnaryClientInterceptor {
+	otelInstruments()
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) (err error) {
+		start := time.Now()
+		ctx, span := startOTelSpan(ctx, method, trace.SpanKindClient)
+		defer func() {
+			endOTelSpan(ctx, span, otelClientDuration, method, start, err)
+		}()
+		return invoker(injectOTel(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+//OpenTelemetryStreamClientInterceptor propagates the W3C trace context to called streams
+func OpenTelemetryStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(injectOTel(ctx), desc, cc, method, opts...)
+	}
+}
+
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (c *contextServerStream) Context() context.Context {
+	return c.ctx
+}
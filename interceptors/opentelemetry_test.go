@@ -0,0 +1,84 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/carousell/Orion/utils/errors/notifier"
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func setupOTel(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+	return recorder
+}
+
+func TestOpenTelemetryInterceptor(t *testing.T) {
+	recorder := setupOTel(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", testTraceParent))
+	ctx = loggers.AddToLogContext(ctx, "", "")
+
+	var handlerCtx context.Context
+	_, err := OpenTelemetryInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			handlerCtx = ctx
+			return nil, status.Error(codes.NotFound, "missing")
+		})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		span := spans[0]
+		assert.Equal(t, "pkg.Svc/Get", span.Name())
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	}
+	// trace id is used for log and error correlation
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", notifier.GetTraceId(handlerCtx))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", loggers.FromContext(handlerCtx)["trace"])
+
+	// health checks are not traced
+	OpenTelemetryInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+	assert.Len(t, recorder.Ended(), 1)
+}
+
+func TestOpenTelemetryClientInterceptor(t *testing.T) {
+	recorder := setupOTel(t)
+	// upstream traceparent forwarded by ForwardMetadataInterceptor should be replaced
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "traceparent", testTraceParent)
+
+	var outgoing metadata.MD
+	err := OpenTelemetryClientInterceptor()(ctx, "/pkg.Svc/Get", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			outgoing, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		})
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) && assert.Len(t, outgoing.Get("traceparent"), 1) {
+		assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+		assert.Contains(t, outgoing.Get("traceparent")[0], spans[0].SpanContext().SpanID().String())
+	}
+}
@@ -0,0 +1,219 @@
+package interceptors
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	//RetryAfterKey is the trailer key that carries the number of seconds a rate limited caller should wait
+	RetryAfterKey = "retry-after"
+
+	// buckets above this count trigger a sweep of idle buckets
+	maxRateLimitBuckets = 10000
+)
+
+var (
+	globalRateLimiter = NewRateLimiter()
+)
+
+//RateLimitRule configures a token bucket for a set of methods
+type RateLimitRule struct {
+	// Method is the full gRPC method ("/pkg.Service/Method"), a service wildcard ("/pkg.Service/*") or "*" for all methods,
+	// the most specific rule matching a call is applied. All methods matching a wildcard share a single bucket
+	Method string
+	// Rate is the number of requests allowed per second, rules with Rate <= 0 do not limit
+	Rate float64
+	// Burst is the maximum number of requests allowed at once, defaults to Rate rounded up
+	Burst int
+	// Key when set keeps a separate bucket for each value of this metadata/header key (e.g. "x-user-id"),
+	// callers without the key share a single bucket
+	Key string
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+//RateLimiter applies token bucket limits to gRPC methods
+type RateLimiter struct {
+	mu      sync.Mutex
+	rules   []RateLimitRule
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+//NewRateLimiter creates a new RateLimiter with the given rules
+func NewRateLimiter(rules ...RateLimitRule) *RateLimiter {
+	r := &RateLimiter{now: time.Now}
+	r.Update(rules...)
+	return r
+}
+
+//Update replaces the rules used by this RateLimiter, all buckets are reset
+func (r *RateLimiter) Update(rules ...RateLimitRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules = append([]RateLimitRule{}, rules...)
+	r.buckets = make(map[string]*tokenBucket)
+}
+
+//Rules returns the rules currently used by this RateLimiter
+func (r *RateLimiter) Rules() []RateLimitRule {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RateLimitRule{}, r.rules...)
+}
+
+// match returns the index of the most specific rule for fullMethod or -1
+func (r *RateLimiter) match(fullMethod string) int {
+	found, specificity := -1, 0
+	for i, rule := range r.rules {
+		if s := methodSpecificity(rule.Method, fullMethod); s > specificity {
+			found, specificity = i, s
+		}
+	}
+	return found
+}
+
+// methodSpecificity returns how specifically pattern matches fullMethod, 0 when it does not match,
+// pattern is a full method, a service wildcard ("/pkg.Service/*") or "*"
+func methodSpecificity(pattern, fullMethod string) int {
+	switch {
+	case pattern == fullMethod:
+		return 3
+	case strings.HasSuffix(pattern, "/*") && strings.HasPrefix(fullMethod, strings.TrimSuffix(pattern, "*")):
+		return 2
+	case pattern == "*":
+		return 1
+	}
+	return 0
+}
+
+//Allow takes a token for fullMethod, when no token is available it returns false along with the time to wait for one
+func (r *RateLimiter) Allow(ctx context.Context, fullMethod string) (bool, time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	idx := r.match(fullMethod)
+	if idx < 0 || r.rules[idx].Rate <= 0 {
+		return true, 0
+	}
+	rule := r.rules[idx]
+	burst := float64(rule.Burst)
+	if burst <= 0 {
+		burst = math.Max(1, math.Ceil(rule.Rate))
+	}
+
+	// buckets belong to rules, methods matching a wildcard rule share its budget
+	key := strconv.Itoa(idx) + "|"
+	if rule.Key != "" {
+		key += metadataValue(ctx, rule.Key)
+	}
+	now := r.now()
+	b, ok := r.buckets[key]
+	if !ok {
+		if len(r.buckets) >= maxRateLimitBuckets {
+			r.sweep(now)
+		}
+		b = &tokenBucket{tokens: burst, last: now}
+		r.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rule.Rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / rule.Rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep drops buckets that have refilled completely, they behave exactly like new buckets
+func (r *RateLimiter) sweep(now time.Time) {
+	for key, b := range r.buckets {
+		idx, _ := strconv.Atoi(key[:strings.Index(key, "|")])
+		rule := r.rules[idx]
+		burst := float64(rule.Burst)
+		if burst <= 0 {
+			burst = math.Max(1, math.Ceil(rule.Rate))
+		}
+		if b.tokens+now.Sub(b.last).Seconds()*rule.Rate >= burst {
+			delete(r.buckets, key)
+		}
+	}
+}
+
+func rateLimitError(ctx context.Context, fullMethod string, wait time.Duration) error {
+	seconds := int64(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	// fails when there is no transport stream in context, the RetryInfo detail still carries the delay
+	grpc.SetTrailer(ctx, metadata.Pairs(RetryAfterKey, strconv.FormatInt(seconds, 10)))
+	st := status.New(codes.ResourceExhausted, "rate limit exceeded for "+fullMethod)
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(wait)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+//RetryAfterFromError returns the delay a caller should wait before retrying, as set by RateLimitInterceptor
+func RetryAfterFromError(err error) (time.Duration, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st == nil {
+		return 0, false
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+//RateLimitInterceptor rejects calls exceeding the limits of the given RateLimiter with codes.ResourceExhausted
+func RateLimitInterceptor(limiter *RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if ok, wait := limiter.Allow(ctx, info.FullMethod); !ok {
+			return nil, rateLimitError(ctx, info.FullMethod, wait)
+		}
+		return handler(ctx, req)
+	}
+}
+
+//RateLimitStreamInterceptor rejects streams exceeding the limits of the given RateLimiter with codes.ResourceExhausted
+func RateLimitStreamInterceptor(limiter *RateLimiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if ok, wait := limiter.Allow(stream.Context(), info.FullMethod); !ok {
+			return rateLimitError(stream.Context(), info.FullMethod, wait)
+		}
+		return handler(srv, stream)
+	}
+}
+
+//SetRateLimits updates the rules used by the global rate limiter
+func SetRateLimits(rules ...RateLimitRule) {
+	globalRateLimiter.Update(rules...)
+}
+
+//GlobalRateLimitInterceptor applies the global rate limiter configured through SetRateLimits
+func GlobalRateLimitInterceptor() grpc.UnaryServerInterceptor {
+	return RateLimitInterceptor(globalRateLimiter)
+}
+
+//GlobalRateLimitStreamInterceptor applies the global rate limiter configured through SetRateLimits to streams
+func GlobalRateLimitStreamInterceptor() grpc.StreamServerInterceptor {
+	return RateLimitStreamInterceptor(globalRateLimiter)
+}
@@ -0,0 +1,116 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newTestLimiter(rules ...RateLimitRule) (*RateLimiter, *time.Time) {
+	now := time.Unix(1000, 0)
+	r := NewRateLimiter(rules...)
+	r.now = func() time.Time { return now }
+	return r, &now
+}
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	r, now := newTestLimiter(RateLimitRule{Method: "/pkg.Svc/Get", Rate: 2, Burst: 2})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		ok, _ := r.Allow(ctx, "/pkg.Svc/Get")
+		assert.True(t, ok)
+	}
+	ok, wait := r.Allow(ctx, "/pkg.Svc/Get")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// other methods are not limited
+	ok, _ = r.Allow(ctx, "/pkg.Svc/List")
+	assert.True(t, ok)
+
+	*now = now.Add(500 * time.Millisecond)
+	ok, _ = r.Allow(ctx, "/pkg.Svc/Get")
+	assert.True(t, ok)
+}
+
+func TestRateLimiterMostSpecificRule(t *testing.T) {
+	r, _ := newTestLimiter(
+		RateLimitRule{Method: "*", Rate: 1},
+		RateLimitRule{Method: "/pkg.Svc/*", Rate: 2},
+		RateLimitRule{Method: "/pkg.Svc/Health", Rate: 0},
+	)
+	ctx := context.Background()
+	allowed := func(method string) int {
+		n := 0
+		for i := 0; i < 5; i++ {
+			if ok, _ := r.Allow(ctx, method); ok {
+				n++
+			}
+		}
+		return n
+	}
+	assert.Equal(t, 1, allowed("/other.Svc/Get"))
+	assert.Equal(t, 2, allowed("/pkg.Svc/Get"))
+	assert.Equal(t, 5, allowed("/pkg.Svc/Health"))
+}
+
+func TestRateLimiterWildcardSharesBucket(t *testing.T) {
+	r, _ := newTestLimiter(RateLimitRule{Method: "/pkg.Svc/*", Rate: 2}, RateLimitRule{Method: "/pkg.Svc/Get", Rate: 1})
+	ctx := context.Background()
+	ok, _ := r.Allow(ctx, "/pkg.Svc/List")
+	assert.True(t, ok)
+	ok, _ = r.Allow(ctx, "/pkg.Svc/Create")
+	assert.True(t, ok)
+	ok, _ = r.Allow(ctx, "/pkg.Svc/Delete")
+	assert.False(t, ok, "the service budget is shared by all its methods")
+	// methods with their own rule have their own budget
+	ok, _ = r.Allow(ctx, "/pkg.Svc/Get")
+	assert.True(t, ok)
+}
+
+func TestRateLimiterKey(t *testing.T) {
+	r, _ := newTestLimiter(RateLimitRule{Method: "*", Rate: 1, Key: "x-user-id"})
+	user := func(id string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-user-id", id))
+	}
+	ok, _ := r.Allow(user("1"), "/pkg.Svc/Get")
+	assert.True(t, ok)
+	ok, _ = r.Allow(user("1"), "/pkg.Svc/Get")
+	assert.False(t, ok)
+	ok, _ = r.Allow(user("2"), "/pkg.Svc/Get")
+	assert.True(t, ok)
+
+	// reloading rules resets buckets
+	r.Update(RateLimitRule{Method: "*", Rate: 1, Key: "x-user-id"})
+	ok, _ = r.Allow(user("1"), "/pkg.Svc/Get")
+	assert.True(t, ok)
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	r, _ := newTestLimiter(RateLimitRule{Method: "*", Rate: 0.5, Burst: 1})
+	interceptor := RateLimitInterceptor(r)
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	resp, err := interceptor(context.Background(), nil, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = interceptor(context.Background(), nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	wait, ok := RetryAfterFromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, wait)
+
+	_, ok = RetryAfterFromError(status.Error(codes.Internal, "boom"))
+	assert.False(t, ok)
+}
@@ -2,11 +2,14 @@ package interceptors
 
 import (
 	"context"
+	"io"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc/metadata"
 
 	"github.com/carousell/Orion/orion/modifiers"
+	"github.com/carousell/Orion/utils/breaker"
 	"github.com/carousell/Orion/utils/errors/notifier"
 	"github.com/carousell/Orion/utils/log"
 	"github.com/carousell/Orion/utils/log/loggers"
@@ -37,7 +40,8 @@ func ServerErrorStreamInterceptor() grpc.StreamServerInterceptor {
 			t.Set("trace", traceID)
 			ctx = loggers.AddToLogContext(ctx, "trace", traceID)
 		}
-		err = handler(srv, stream)
+		ctx = notifier.SetRequestScope(ctx, info.FullMethod)
+		err = handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
 		if !modifiers.HasDontLogError(ctx) {
 			notifier.Notify(err, ctx)
 		}
@@ -62,3 +66,89 @@ func ForwardMetadataStreamClientInterceptor() grpc.StreamClientInterceptor {
 		return streamer(ctx, desc, cc, method, opts...)
 	}
 }
+
+//HystrixStreamClientInterceptor applies circuit breakers to client streams, it accepts the same call options as
+//HystrixClientInterceptor (WithHystrixName, WithHystrixIgnorableErrors, WithHystrixIgnorableGRPCCodes, WithHystrixFallbackFunc).
+//Streams are rejected while the circuit is open, the outcome of a stream is reported once, as a success when the first message
+//is received or the stream ends cleanly and as a failure when establishing the stream fails or it ends with an error before
+//the first message. Streams are long lived so they are not subject to breaker timeouts and max concurrency.
+func HystrixStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		options := hystrixOptions{
+			cmdName: method,
+		}
+		for _, opt := range opts {
+			if opt != nil {
+				if o, ok := opt.(hystrixOption); ok {
+					o.process(&options)
+				}
+			}
+		}
+		circuit := breaker.Get(options.cmdName)
+		start := time.Now()
+		if !circuit.AllowRequest() {
+			return nil, options.fallback(circuit, start, []string{breaker.EventShortCircuit}, breaker.ErrCircuitOpen)
+		}
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			if ctx.Err() != nil {
+				circuit.ReportEvent([]string{breaker.EventContextCanceled}, start, time.Since(start))
+				return nil, err
+			}
+			if options.canIgnore(err) {
+				circuit.ReportEvent([]string{breaker.EventSuccess}, start, time.Since(start))
+				return nil, err
+			}
+			return nil, options.fallback(circuit, start, []string{breaker.EventFailure}, err)
+		}
+		return &hystrixClientStream{ClientStream: cs, ctx: ctx, circuit: circuit, options: options, start: start}, nil
+	}
+}
+
+// fallback reports events along with the outcome of the fallback func and returns the error of the stream,
+// err is returned when the fallback func recovers since there is no stream to return
+func (ho *hystrixOptions) fallback(circuit *breaker.Breaker, start time.Time, events []string, err error) error {
+	if ho.fallbackFunc == nil {
+		circuit.ReportEvent(events, start, time.Since(start))
+		return err
+	}
+	if ferr := ho.fallbackFunc(err); ferr != nil {
+		circuit.ReportEvent(append(events, breaker.EventFallbackFailure), start, time.Since(start))
+		return ferr
+	}
+	circuit.ReportEvent(append(events, breaker.EventFallbackSuccess), start, time.Since(start))
+	return err
+}
+
+type hystrixClientStream struct {
+	grpc.ClientStream
+	ctx      context.Context
+	circuit  *breaker.Breaker
+	options  hystrixOptions
+	start    time.Time
+	reported int32
+}
+
+func (h *hystrixClientStream) RecvMsg(m interface{}) error {
+	err := h.ClientStream.RecvMsg(m)
+	if atomic.LoadInt32(&h.reported) == 1 {
+		return err
+	}
+	switch {
+	case err == nil || err == io.EOF || h.options.canIgnore(err):
+		h.report([]string{breaker.EventSuccess})
+	case h.ctx.Err() != nil:
+		// canceled by the caller, the downstream service is not at fault
+		h.report([]string{breaker.EventContextCanceled})
+	default:
+		h.report([]string{breaker.EventFailure})
+	}
+	return err
+}
+
+// report reports events once for this stream
+func (h *hystrixClientStream) report(events []string) {
+	if atomic.CompareAndSwapInt32(&h.reported, 0, 1) {
+		h.circuit.ReportEvent(events, h.start, time.Since(h.start))
+	}
+}
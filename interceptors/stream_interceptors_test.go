@@ -0,0 +1,90 @@
+package interceptors
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/carousell/Orion/utils/breaker"
+	"github.com/carousell/Orion/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeClientStream struct {
+	grpc.ClientStream
+	errs []error
+}
+
+func (f *fakeClientStream) RecvMsg(m interface{}) error {
+	if len(f.errs) == 0 {
+		return io.EOF
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+func streamerWith(err error, recvErrs ...error) grpc.Streamer {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err != nil {
+			return nil, err
+		}
+		return &fakeClientStream{errs: recvErrs}, nil
+	}
+}
+
+func openStream(name string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	opts = append(opts, WithHystrixName(name))
+	return HystrixStreamClientInterceptor()(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil, "/svc/Stream", streamer, opts...)
+}
+
+func TestHystrixStreamClientInterceptorOpensCircuit(t *testing.T) {
+	name := "TestHystrixStreamClientInterceptorOpensCircuit"
+	breaker.ConfigureCommand(name, breaker.CommandConfig{RequestVolumeThreshold: 2, ErrorPercentThreshold: 50, SleepWindow: 60000})
+	defer breaker.Flush()
+	circuit := breaker.Get(name)
+
+	unavailable := status.Error(codes.Unavailable, "down")
+	_, err := openStream(name, streamerWith(unavailable))
+	assert.Equal(t, unavailable, err)
+
+	// fails before the first message
+	cs, err := openStream(name, streamerWith(nil, unavailable))
+	assert.NoError(t, err)
+	assert.Equal(t, unavailable, cs.RecvMsg(nil))
+
+	assert.Eventually(t, circuit.IsOpen, time.Second, 10*time.Millisecond)
+	_, err = openStream(name, streamerWith(nil))
+	assert.Equal(t, breaker.ErrCircuitOpen, err)
+
+	fallbackErr := errors.New("fallback")
+	_, err = openStream(name, streamerWith(nil), WithHystrixFallbackFunc(func(error) error { return fallbackErr }))
+	assert.Equal(t, fallbackErr, err)
+}
+
+func TestHystrixStreamClientInterceptorSuccess(t *testing.T) {
+	name := "TestHystrixStreamClientInterceptorSuccess"
+	breaker.ConfigureCommand(name, breaker.CommandConfig{RequestVolumeThreshold: 2, ErrorPercentThreshold: 50, SleepWindow: 60000})
+	defer breaker.Flush()
+	circuit := breaker.Get(name)
+
+	notFound := status.Error(codes.NotFound, "missing")
+	unavailable := status.Error(codes.Unavailable, "down")
+	for i := 0; i < 5; i++ {
+		// ignorable errors and errors after the first message do not count as failures
+		_, err := openStream(name, streamerWith(notFound), WithHystrixIgnorableGRPCCodes(codes.NotFound))
+		assert.Equal(t, notFound, err)
+		cs, err := openStream(name, streamerWith(nil, nil, unavailable))
+		assert.NoError(t, err)
+		assert.NoError(t, cs.RecvMsg(nil))
+		assert.Equal(t, unavailable, cs.RecvMsg(nil))
+		cs, _ = openStream(name, streamerWith(nil))
+		assert.Equal(t, io.EOF, cs.RecvMsg(nil))
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, circuit.IsOpen())
+}
@@ -0,0 +1,142 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carousell/Orion/utils/options"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	//TimeoutOption is the method option that sets the default timeout of a method, e.g. "ORION:OPTION: TIMEOUT=500ms"
+	TimeoutOption = "TIMEOUT"
+)
+
+var (
+	globalTimeouts = &timeoutRules{}
+)
+
+//TimeoutRule configures the default timeout for a set of methods
+type TimeoutRule struct {
+	// Method is the full gRPC method ("/pkg.Service/Method"), a service wildcard ("/pkg.Service/*") or "*" for all methods,
+	// the most specific rule matching a call is applied
+	Method string
+	// Timeout is the default timeout for matching methods, a go duration with a unit e.g. "500ms",
+	// streams get the timeout for their whole duration
+	Timeout time.Duration
+}
+
+type timeoutRules struct {
+	mu    sync.RWMutex
+	rules []TimeoutRule
+}
+
+func (t *timeoutRules) get(fullMethod string) time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	timeout, specificity := time.Duration(0), 0
+	for _, rule := range t.rules {
+		if s := methodSpecificity(rule.Method, fullMethod); s > specificity {
+			timeout, specificity = rule.Timeout, s
+		}
+	}
+	return timeout
+}
+
+// timeout returns the timeout for a call, a TIMEOUT method option takes precedence over the rules
+func (t *timeoutRules) timeout(ctx context.Context, fullMethod string) time.Duration {
+	if value, ok := options.FromContext(ctx).Get(TimeoutOption); ok {
+		if d, ok := value.(time.Duration); ok {
+			return d
+		}
+	}
+	return t.get(fullMethod)
+}
+
+//ParseTimeout parses a timeout given as a go duration ("1.5s", "500ms"), numbers without a unit are rejected
+//the same way as in [[orion.Timeouts]]
+func ParseTimeout(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	// protoc-gen-orion upper cases options
+	d, err := time.ParseDuration(strings.ToLower(value))
+	return d, err == nil && d > 0
+}
+
+//ParseTimeoutOption parses a "TIMEOUT=<duration>" method option
+func ParseTimeoutOption(option string) (time.Duration, bool) {
+	parts := strings.SplitN(option, "=", 2)
+	if len(parts) != 2 || strings.ToUpper(strings.TrimSpace(parts[0])) != TimeoutOption {
+		return 0, false
+	}
+	return ParseTimeout(parts[1])
+}
+
+//TimeoutInterceptor applies the method timeout to the request context, the timeout set by a TIMEOUT
+//method option takes precedence over the configured rules. Deadlines set by clients are only ever shortened.
+//Requests whose deadline expires fail with codes.DeadlineExceeded
+func TimeoutInterceptor(rules ...TimeoutRule) grpc.UnaryServerInterceptor {
+	return timeoutInterceptor(&timeoutRules{rules: rules})
+}
+
+func timeoutInterceptor(rules *timeoutRules) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if timeout := rules.timeout(ctx, info.FullMethod); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		resp, err := handler(ctx, req)
+		if ctx.Err() == context.DeadlineExceeded && status.Code(err) != codes.DeadlineExceeded {
+			return nil, status.Error(codes.DeadlineExceeded, "deadline exceeded for "+info.FullMethod)
+		}
+		return resp, err
+	}
+}
+
+//TimeoutStreamInterceptor applies the method timeout to the context of whole streams, the same way
+//TimeoutInterceptor does for unary calls. Stream handlers have to watch the stream context to stop on time
+func TimeoutStreamInterceptor(rules ...TimeoutRule) grpc.StreamServerInterceptor {
+	return timeoutStreamInterceptor(&timeoutRules{rules: rules})
+}
+
+func timeoutStreamInterceptor(rules *timeoutRules) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := stream.Context()
+		timeout := rules.timeout(ctx, info.FullMethod)
+		if timeout <= 0 {
+			return handler(srv, stream)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = ctx
+		err := handler(srv, wrapped)
+		if ctx.Err() == context.DeadlineExceeded && status.Code(err) != codes.DeadlineExceeded {
+			return status.Error(codes.DeadlineExceeded, "deadline exceeded for "+info.FullMethod)
+		}
+		return err
+	}
+}
+
+//SetTimeouts updates the rules used by GlobalTimeoutInterceptor
+func SetTimeouts(rules ...TimeoutRule) {
+	globalTimeouts.mu.Lock()
+	defer globalTimeouts.mu.Unlock()
+	globalTimeouts.rules = append([]TimeoutRule{}, rules...)
+}
+
+//GlobalTimeoutInterceptor applies the timeouts configured through SetTimeouts
+func GlobalTimeoutInterceptor() grpc.UnaryServerInterceptor {
+	return timeoutInterceptor(globalTimeouts)
+}
+
+//GlobalTimeoutStreamInterceptor applies the timeouts configured through SetTimeouts to streams
+func GlobalTimeoutStreamInterceptor() grpc.StreamServerInterceptor {
+	return timeoutStreamInterceptor(globalTimeouts)
+}
@@ -0,0 +1,99 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/carousell/Orion/utils/options"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseTimeout(t *testing.T) {
+	_, ok := ParseTimeout("1500")
+	assert.False(t, ok, "timeouts need a unit")
+	d, ok := ParseTimeout("1.5s")
+	assert.True(t, ok)
+	assert.Equal(t, 1500*time.Millisecond, d)
+	_, ok = ParseTimeout("")
+	assert.False(t, ok)
+	_, ok = ParseTimeout("-1s")
+	assert.False(t, ok)
+
+	d, ok = ParseTimeoutOption("TIMEOUT=500MS")
+	assert.True(t, ok)
+	assert.Equal(t, 500*time.Millisecond, d)
+	_, ok = ParseTimeoutOption("IGNORE_NR")
+	assert.False(t, ok)
+}
+
+func TestTimeoutInterceptor(t *testing.T) {
+	interceptor := TimeoutInterceptor(
+		TimeoutRule{Method: "*", Timeout: time.Hour},
+		TimeoutRule{Method: "/pkg.Svc/Get", Timeout: 20 * time.Millisecond},
+	)
+	wait := func(ctx context.Context, req interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	deadline := func(ctx context.Context, req interface{}) (interface{}, error) {
+		d, _ := ctx.Deadline()
+		return time.Until(d), nil
+	}
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"}, wait)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/List"}, deadline)
+	assert.NoError(t, err)
+	assert.True(t, resp.(time.Duration) > time.Minute)
+
+	// method options take precedence over rules
+	ctx := options.AddToOptions(context.Background(), TimeoutOption, 10*time.Millisecond)
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/List"}, wait)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	// client deadlines are not extended
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	resp, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/List"}, deadline)
+	assert.NoError(t, err)
+	assert.True(t, resp.(time.Duration) <= 10*time.Millisecond)
+}
+
+type timeoutStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *timeoutStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestTimeoutStreamInterceptor(t *testing.T) {
+	interceptor := TimeoutStreamInterceptor(TimeoutRule{Method: "/pkg.Svc/Watch", Timeout: 20 * time.Millisecond})
+	wait := func(srv interface{}, stream grpc.ServerStream) error {
+		<-stream.Context().Done()
+		return stream.Context().Err()
+	}
+	stream := &timeoutStream{ctx: context.Background()}
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/pkg.Svc/Watch"}, wait)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	// method options take precedence over rules
+	stream = &timeoutStream{ctx: options.AddToOptions(context.Background(), TimeoutOption, 10*time.Millisecond)}
+	err = interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/pkg.Svc/List"}, wait)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	// no rule, no deadline
+	stream = &timeoutStream{ctx: context.Background()}
+	err = interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/pkg.Svc/List"}, func(srv interface{}, stream grpc.ServerStream) error {
+		_, ok := stream.Context().Deadline()
+		assert.False(t, ok)
+		return nil
+	})
+	assert.NoError(t, err)
+}
@@ -5,9 +5,9 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/afex/hystrix-go/hystrix"
 	"github.com/carousell/Orion/orion/modifiers"
 	"github.com/carousell/Orion/utils"
+	"github.com/carousell/Orion/utils/breaker"
 	"github.com/carousell/Orion/utils/errors"
 	"github.com/carousell/Orion/utils/errors/notifier"
 	"github.com/carousell/Orion/utils/log"
@@ -74,6 +74,7 @@ func ServerErrorInterceptor() grpc.UnaryServerInterceptor {
 			t.Set("trace", traceID)
 			ctx = loggers.AddToLogContext(ctx, "trace", traceID)
 		}
+		ctx = notifier.SetRequestScope(ctx, info.FullMethod)
 		// dont log Error for HTTP request, let HTTP Handler manage it
 		if modifiers.IsHTTPRequest(ctx) {
 			return handler(ctx, req)
@@ -98,7 +99,11 @@ func PanicRecoveryInterceptor() grpc.UnaryServerInterceptor {
 					err = errors.New(fmt.Sprintf("panic: %s", r))
 				}
 				utils.FinishNRTransaction(ctx, err)
-				notifier.NotifyWithLevel(err, "critical", info.FullMethod, ctx)
+				notifier.NotifyPanic(r, info.FullMethod, ctx, notifier.Tags{"method": info.FullMethod})
+				if n, ok := err.(errors.NotifyExt); ok {
+					// reported above, do not report again in ServerErrorInterceptor
+					n.Notified(true)
+				}
 			}
 		}(ctx)
 
@@ -141,7 +146,7 @@ func HystrixClientInterceptor() grpc.UnaryClientInterceptor {
 		newCtx, cancel := context.WithCancel(ctx)
 		defer cancel()
 		var err error
-		herr := hystrix.Do(options.cmdName, func() (e error) {
+		herr := breaker.Do(options.cmdName, func() (e error) {
 			defer func() {
 				if r := recover(); r != nil {
 					err = errors.Wrap(fmt.Errorf("panic inside hystrix Method: %s, req: %v, reply: %v", method, req, reply), "Hystrix")
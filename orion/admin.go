@@ -0,0 +1,188 @@
+package orion
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/carousell/Orion/utils/log"
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/spf13/viper"
+)
+
+const (
+	//AdminLogLevelPath is the path on the pprof port to GET/PUT the global log level
+	AdminLogLevelPath = "/orion/admin/loglevel"
+	//AdminConfigPath is the path on the pprof port that serves the effective config with secrets masked
+	AdminConfigPath = "/orion/admin/config"
+	//AdminReloadPath is the path on the pprof port that triggers the same reload as SIGHUP on POST
+	AdminReloadPath = "/orion/admin/reload"
+
+	maskedValue = "********"
+)
+
+var (
+	adminOnce    sync.Once
+	adminMu      sync.RWMutex
+	adminEnabled bool
+	adminServer  Server
+
+	// config keys containing any of these are masked by the admin API
+	secretKeyParts = []string{"token", "dsn", "apikey", "secret", "password", "credential"}
+)
+
+//Reloader is implemented by servers that can reload their config at runtime
+type Reloader interface {
+	Reload() error
+}
+
+//AdminInitializer returns a Initializer implementation that serves admin endpoints on the pprof port
+//to change the log level, inspect the config and trigger a reload, enabled by EnableAdminAPI
+func AdminInitializer() Initializer {
+	return &adminInitializer{}
+}
+
+type adminInitializer struct{}
+
+func (a *adminInitializer) Init(svr Server) error {
+	setAdmin(svr, svr.GetOrionConfig().EnableAdminAPI)
+	return nil
+}
+
+func (a *adminInitializer) ReInit(svr Server) error {
+	return a.Init(svr)
+}
+
+func setAdmin(svr Server, enabled bool) {
+	adminMu.Lock()
+	adminServer = svr
+	adminEnabled = enabled
+	adminMu.Unlock()
+	if !enabled {
+		return
+	}
+	// http.DefaultServeMux panics when a path is registered twice
+	adminOnce.Do(func() {
+		http.HandleFunc(AdminLogLevelPath, adminHandler(logLevelHandler))
+		http.HandleFunc(AdminConfigPath, adminHandler(configHandler))
+		http.HandleFunc(AdminReloadPath, adminHandler(reloadHandler))
+	})
+}
+
+func adminHandler(h func(http.ResponseWriter, *http.Request, Server)) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		adminMu.RLock()
+		svr, enabled := adminServer, adminEnabled
+		adminMu.RUnlock()
+		if !enabled || svr == nil {
+			http.NotFound(resp, req)
+			return
+		}
+		h(resp, req, svr)
+	}
+}
+
+func logLevelHandler(resp http.ResponseWriter, req *http.Request, svr Server) {
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		level := req.URL.Query().Get("level")
+		if level == "" {
+			body := struct {
+				Level string `json:"level"`
+			}{}
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				http.Error(resp, "level is required", http.StatusBadRequest)
+				return
+			}
+			level = body.Level
+		}
+		lvl, err := loggers.ParseLevel(level)
+		if err != nil {
+			http.Error(resp, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.GetLogger().SetLevel(lvl)
+		log.Info(context.Background(), "admin", "log level changed", "level", lvl.String())
+	default:
+		http.Error(resp, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(resp, map[string]string{"level": log.GetLogger().GetLevel().String()})
+}
+
+func configHandler(resp http.ResponseWriter, req *http.Request, svr Server) {
+	if req.Method != http.MethodGet {
+		http.Error(resp, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	config := make(map[string]interface{})
+	if data, err := json.Marshal(svr.GetOrionConfig()); err == nil {
+		json.Unmarshal(data, &config)
+	}
+	writeJSON(resp, map[string]interface{}{
+		"config":   MaskSecrets(config),
+		"settings": MaskSecrets(viper.AllSettings()),
+	})
+}
+
+func reloadHandler(resp http.ResponseWriter, req *http.Request, svr Server) {
+	if req.Method != http.MethodPost {
+		http.Error(resp, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	reloader, ok := svr.(Reloader)
+	if !ok {
+		http.Error(resp, "reload not supported", http.StatusNotImplemented)
+		return
+	}
+	log.Info(context.Background(), "admin", "reload requested", "remote", req.RemoteAddr)
+	if err := reloader.Reload(); err != nil {
+		code := http.StatusInternalServerError
+		if err == ErrHotReloadDisabled {
+			code = http.StatusConflict
+		}
+		http.Error(resp, err.Error(), code)
+		return
+	}
+	writeJSON(resp, map[string]string{"status": "reloaded"})
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range secretKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+//MaskSecrets returns a copy of settings with the values of secret looking keys (tokens, DSNs, API keys...) masked
+func MaskSecrets(settings map[string]interface{}) map[string]interface{} {
+	masked := make(map[string]interface{}, len(settings))
+	for key, value := range settings {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			masked[key] = MaskSecrets(v)
+		case []interface{}:
+			values := make([]interface{}, len(v))
+			for i, item := range v {
+				if m, ok := item.(map[string]interface{}); ok {
+					item = MaskSecrets(m)
+				}
+				values[i] = item
+			}
+			masked[key] = values
+		default:
+			if isSecretKey(key) && value != nil && value != "" {
+				masked[key] = maskedValue
+			} else {
+				masked[key] = value
+			}
+		}
+	}
+	return masked
+}
@@ -0,0 +1,92 @@
+package orion
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/carousell/Orion/utils/log"
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func adminRequest(method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestMaskSecrets(t *testing.T) {
+	masked := MaskSecrets(map[string]interface{}{
+		"rollbar-token": "abc",
+		"sentrydsn":     "",
+		"env":           "prod",
+		"NewRelicConfig": map[string]interface{}{
+			"APIKey":      "key",
+			"ServiceName": "svc",
+		},
+		"list": []interface{}{map[string]interface{}{"password": "p"}, "plain"},
+	})
+	assert.Equal(t, maskedValue, masked["rollbar-token"])
+	assert.Equal(t, "", masked["sentrydsn"])
+	assert.Equal(t, "prod", masked["env"])
+	assert.Equal(t, maskedValue, masked["NewRelicConfig"].(map[string]interface{})["APIKey"])
+	assert.Equal(t, "svc", masked["NewRelicConfig"].(map[string]interface{})["ServiceName"])
+	assert.Equal(t, maskedValue, masked["list"].([]interface{})[0].(map[string]interface{})["password"])
+}
+
+func TestAdminAPI(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("orion.SentryDSN", "https://secret@sentry.io/1")
+	svr := GetDefaultServerWithConfig(Config{
+		GRPCOnly:       true,
+		HTTPOnly:       true,
+		SentryDSN:      "https://secret@sentry.io/1",
+		RollbarToken:   "rollbar",
+		EnableAdminAPI: true,
+	})
+	initializer := AdminInitializer()
+	assert.NoError(t, initializer.Init(svr))
+	defer setAdmin(nil, false)
+
+	previous := log.GetLogger().GetLevel()
+	defer log.GetLogger().SetLevel(previous)
+
+	rec := adminRequest("PUT", AdminLogLevelPath, `{"level": "debug"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, loggers.Level(loggers.DebugLevel), log.GetLogger().GetLevel())
+	rec = adminRequest("PUT", AdminLogLevelPath+"?level=warn", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level": "warning"}`, rec.Body.String())
+	rec = adminRequest("PUT", AdminLogLevelPath, `{"level": "loud"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = adminRequest("GET", AdminLogLevelPath, "")
+	assert.JSONEq(t, `{"level": "warning"}`, rec.Body.String())
+
+	rec = adminRequest("GET", AdminConfigPath, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "secret@sentry.io")
+	assert.NotContains(t, rec.Body.String(), `"rollbar"`)
+	body := struct {
+		Config   map[string]interface{}
+		Settings map[string]interface{}
+	}{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, maskedValue, body.Config["SentryDSN"])
+	assert.Equal(t, maskedValue, body.Settings["orion"].(map[string]interface{})["sentrydsn"])
+
+	// hot reload is disabled
+	rec = adminRequest("POST", AdminReloadPath, "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = adminRequest("GET", AdminReloadPath, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	// disabled on reload
+	svr.(*DefaultServerImpl).config.EnableAdminAPI = false
+	assert.NoError(t, initializer.ReInit(svr))
+	rec = adminRequest("GET", AdminLogLevelPath, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -4,11 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
-	"github.com/afex/hystrix-go/hystrix"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 
+	"github.com/carousell/Orion/interceptors"
+	"github.com/carousell/Orion/orion/handlers"
+	"github.com/carousell/Orion/utils/breaker"
+	"github.com/carousell/Orion/utils/errors/notifier"
 	"github.com/carousell/Orion/utils/log"
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/carousell/Orion/utils/log/loggers/sampler"
 )
 
 var (
@@ -65,10 +74,19 @@ type Config struct {
 	RollbarToken string
 	//SentryDSN is the token used by sentry for error reporting
 	SentryDSN string
+	//SentryRelease is the release reported to sentry, sentry derives it from the environment (SENTRY_RELEASE) or git when empty
+	SentryRelease string
 	//Env is the environment this service is running in
 	Env string
+	//NotifierDedup is the configuration for deduplicating reported errors, reloaded on SIGHUP
+	NotifierDedup notifier.DedupConfig
+	//NotifierMinSeverity is the minimum severity reported by each error reporter (rollbar, sentry), keyed by reporter name
+	NotifierMinSeverity map[string]string
 	// DefaultJSONPB sets jsonpb as the encoder/decoder for application/json request/response bodies
 	DefaultJSONPB bool
+	// HTTPErrorStatus overrides the HTTP status code of gRPC codes in HTTP error responses, keyed by gRPC code name
+	// e.g. FailedPrecondition = 412
+	HTTPErrorStatus map[string]int
 	// DisableDefaultInterceptors disables the default interceptors for all handlers
 	DisableDefaultInterceptors bool
 	// Receive message Size is used to update the default limit of message that can be received
@@ -80,15 +98,59 @@ type Config struct {
 	// SessionTrackingConfig is the optional configuration for Kafka-based session tracking.
 	// SessionInitializer is a no-op when KafkaBrokers is empty.
 	SessionTrackingConfig SessionTrackingConfig
+	// DisableHealthCheck disables grpc.health.v1.Health on gRPC handler and /healthz, /readyz on HTTP handler
+	DisableHealthCheck bool
+	// TLSConfig is the configuration for serving HTTP and gRPC over TLS
+	TLSConfig TLSConfig
+	// SinglePort serves both gRPC and HTTP on HTTPPort, connections are dispatched based on protocol
+	SinglePort bool
+	// RateLimits are the token bucket limits applied to incoming requests, reloaded on SIGHUP
+	RateLimits []interceptors.RateLimitRule
+	// ConcurrencyLimit is the configuration for adaptive load shedding of incoming requests, reloaded on SIGHUP
+	ConcurrencyLimit interceptors.ConcurrencyLimitConfig
+	// Timeouts are the default timeouts of methods, reloaded on SIGHUP
+	Timeouts []interceptors.TimeoutRule
+	// AccessLog is the configuration for structured access logs of incoming requests, reloaded on SIGHUP
+	AccessLog interceptors.AccessLogConfig
+	// LogLevelOverride controls which callers can raise the log level of their requests, reloaded on SIGHUP
+	LogLevelOverride handlers.LogLevelOverrideConfig
+	// EnableAdminAPI serves admin endpoints under '/orion/admin/' on pprof port to change the log level,
+	// inspect the config and trigger a reload
+	EnableAdminAPI bool
+	// LogSampling is the configuration for sampling repeated logs of the global logger, reloaded on SIGHUP
+	LogSampling sampler.Config
+	// Scrub is the configuration for masking sensitive data in logs and error reports, reloaded on SIGHUP
+	Scrub loggers.ScrubConfig
+	//OpenTelemetryConfig is the configuration for OpenTelemetry tracing and metrics
+	OpenTelemetryConfig OpenTelemetryConfig
 }
 
-// HystrixConfig is configuration used by hystrix
+// TLSConfig is the configuration for TLS and mutual TLS, TLS is enabled when both CertFile and KeyFile are set
+// certificates are reloaded from disk when the server receives SIGHUP
+type TLSConfig struct {
+	// CertFile is the path to the PEM encoded server certificate
+	CertFile string
+	// KeyFile is the path to the PEM encoded server private key
+	KeyFile string
+	// ClientCAFile is the path to the PEM encoded CA bundle used to verify client certificates
+	ClientCAFile string
+	// RequireClientCert rejects connections that do not present a valid client certificate
+	RequireClientCert bool
+}
+
+// Enabled returns true if TLS should be used for listeners
+func (t TLSConfig) Enabled() bool {
+	return strings.TrimSpace(t.CertFile) != "" && strings.TrimSpace(t.KeyFile) != ""
+}
+
+// HystrixConfig is configuration used by circuit breakers
 type HystrixConfig struct {
-	//Port is the port to start hystrix stream handler on
+	//Port is the port to start the hystrix dashboard stream handler on
 	Port string
-	//CommandConfig is configuration for individual commands
-	CommandConfig map[string]hystrix.CommandConfig
-	//StatsdAddr is the address of the statsd hosts to send hystrix data to
+	//CommandConfig is configuration for individual commands, keyed by command name, e.g. [orion.HystrixCommandConfig.GetUser] with
+	//Timeout, MaxConcurrentRequests, RequestVolumeThreshold, SleepWindow and ErrorPercentThreshold keys
+	CommandConfig map[string]breaker.CommandConfig
+	//StatsdAddr is the address of the statsd hosts to send circuit breaker data to
 	StatsdAddr string
 	// DefaultTimeout is how long to wait for command to complete, in milliseconds
 	DefaultTimeout int
@@ -102,6 +164,24 @@ type HystrixConfig struct {
 	DefaultErrorPercentThreshold int
 }
 
+// OpenTelemetryConfig is the configuration for OpenTelemetry, when enabled it replaces zipkin for tracing
+type OpenTelemetryConfig struct {
+	//Enabled selects OpenTelemetry instead of zipkin
+	Enabled bool
+	//Protocol is the OTLP protocol to export with, "grpc" (default) or "http"
+	Protocol string
+	//Endpoint is the host:port of the OTLP collector, defaults to OTEL_EXPORTER_OTLP_ENDPOINT or the protocol default
+	Endpoint string
+	//Insecure disables TLS when connecting to the collector
+	Insecure bool
+	//ServiceName is the name reported to OpenTelemetry, defaults to OrionServerName
+	ServiceName string
+	//SampleRatio is the ratio of traces sampled when the caller did not decide, defaults to 1
+	SampleRatio float64
+	//DisableMetrics disables exporting metrics over OTLP
+	DisableMetrics bool
+}
+
 // ZipkinConfig is the configuration for the zipkin collector
 type ZipkinConfig struct {
 	//Addr is the address of the zipkin collector
@@ -122,6 +202,11 @@ type NewRelicConfig struct {
 func BuildDefaultConfig(name string) Config {
 	setup(name)
 	readConfig(name)
+	return buildConfig(name)
+}
+
+// buildConfig builds the config object from the config that has already been read
+func buildConfig(name string) Config {
 	return Config{
 		GRPCOnly:                   viper.GetBool("orion.GRPCOnly"),
 		HTTPOnly:                   viper.GetBool("orion.HTTPOnly"),
@@ -135,16 +220,175 @@ func BuildDefaultConfig(name string) Config {
 		RollbarToken:               viper.GetString("orion.rollbar-token"),
 		Env:                        viper.GetString("orion.Env"),
 		SentryDSN:                  viper.GetString("orion.SentryDSN"),
+		SentryRelease:              viper.GetString("orion.SentryRelease"),
+		NotifierMinSeverity:        BuildDefaultNotifierMinSeverity(),
+		NotifierDedup:              BuildDefaultNotifierDedupConfig(),
 		OrionServerName:            name,
 		HystrixConfig:              BuildDefaultHystrixConfig(),
 		ZipkinConfig:               BuildDefaultZipkinConfig(),
+		OpenTelemetryConfig:        BuildDefaultOpenTelemetryConfig(),
 		NewRelicConfig:             BuildDefaultNewRelicConfig(),
 		DefaultJSONPB:              viper.GetBool("orion.DefaultJSONPB"),
+		HTTPErrorStatus:            BuildDefaultHTTPErrorStatus(),
 		DisableDefaultInterceptors: viper.GetBool("orion.DisableDefaultInterceptors"),
 		MaxRecvMsgSize:             viper.GetInt("orion.MaxRecvMsgSize"),
 		ReadTimeout:                viper.GetInt("orion.ReadTimeout"),
 		WriteTimeout:               viper.GetInt("orion.WriteTimeout"),
 		SessionTrackingConfig:      BuildDefaultSessionTrackingConfig(),
+		DisableHealthCheck:         viper.GetBool("orion.DisableHealthCheck"),
+		TLSConfig:                  BuildDefaultTLSConfig(),
+		SinglePort:                 viper.GetBool("orion.SinglePort"),
+		RateLimits:                 BuildDefaultRateLimits(),
+		ConcurrencyLimit:           BuildDefaultConcurrencyLimitConfig(),
+		Timeouts:                   BuildDefaultTimeouts(),
+		AccessLog:                  BuildDefaultAccessLogConfig(),
+		LogLevelOverride:           BuildDefaultLogLevelOverrideConfig(),
+		EnableAdminAPI:             viper.GetBool("orion.EnableAdminAPI"),
+		LogSampling:                BuildDefaultLogSamplingConfig(),
+		Scrub:                      BuildDefaultScrubConfig(),
+	}
+}
+
+// BuildDefaultRateLimits reads the rate limit rules configured as [[orion.RateLimits]] tables
+func BuildDefaultRateLimits() []interceptors.RateLimitRule {
+	rules := make([]interceptors.RateLimitRule, 0)
+	if err := viper.UnmarshalKey("orion.RateLimits", &rules); err != nil {
+		log.Error(context.Background(), "config", "could not parse orion.RateLimits", "error", err)
+	}
+	return rules
+}
+
+// durationHook decodes strings into durations and rejects numbers, a bare number would be read as nanoseconds
+func durationHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(time.Duration(0)) {
+		return data, nil
+	}
+	switch from.Kind() {
+	case reflect.String:
+		return time.ParseDuration(data.(string))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return nil, fmt.Errorf("duration %v has no unit, use a go duration e.g. \"500ms\"", data)
+	}
+	return data, nil
+}
+
+// BuildDefaultTimeouts reads the method timeouts configured as [[orion.Timeouts]] tables,
+// timeouts are go durations with a unit e.g. Timeout = "500ms", rules with bare numbers are logged and skipped
+func BuildDefaultTimeouts() []interceptors.TimeoutRule {
+	rules := make([]interceptors.TimeoutRule, 0)
+	entries := make([]map[string]interface{}, 0)
+	if err := viper.UnmarshalKey("orion.Timeouts", &entries); err != nil {
+		log.Error(context.Background(), "config", "could not parse orion.Timeouts", "error", err)
+		return rules
+	}
+	for i, entry := range entries {
+		rule := interceptors.TimeoutRule{}
+		// decode each rule on its own so that one invalid rule does not drop the others
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook:       durationHook,
+			WeaklyTypedInput: true,
+			Result:           &rule,
+		})
+		if err == nil {
+			err = decoder.Decode(entry)
+		}
+		if err != nil {
+			log.Error(context.Background(), "config", "ignoring invalid orion.Timeouts entry", "index", i, "error", err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// BuildDefaultNotifierMinSeverity reads the per reporter severity thresholds configured as
+// [orion.NotifierMinSeverity] tables e.g. rollbar = "warning"
+func BuildDefaultNotifierMinSeverity() map[string]string {
+	return viper.GetStringMapString("orion.NotifierMinSeverity")
+}
+
+// BuildDefaultNotifierDedupConfig reads the error dedup config from [orion.NotifierDedup],
+// Window is a go duration e.g. Window = "1m"
+func BuildDefaultNotifierDedupConfig() notifier.DedupConfig {
+	config := notifier.DedupConfig{}
+	if err := viper.UnmarshalKey("orion.NotifierDedup", &config); err != nil {
+		log.Error(context.Background(), "config", "could not parse orion.NotifierDedup", "error", err)
+	}
+	return config
+}
+
+// BuildDefaultHTTPErrorStatus reads the HTTP status overrides of gRPC codes configured as
+// [orion.HTTPErrorStatus] tables e.g. FailedPrecondition = 412
+func BuildDefaultHTTPErrorStatus() map[string]int {
+	statuses := make(map[string]int)
+	if err := viper.UnmarshalKey("orion.HTTPErrorStatus", &statuses); err != nil {
+		log.Error(context.Background(), "config", "could not parse orion.HTTPErrorStatus", "error", err)
+	}
+	return statuses
+}
+
+// BuildDefaultAccessLogConfig builds a default config for access logs
+func BuildDefaultAccessLogConfig() interceptors.AccessLogConfig {
+	return interceptors.AccessLogConfig{
+		Enabled:           viper.GetBool("orion.AccessLog.Enabled"),
+		Metadata:          viper.GetStringSlice("orion.AccessLog.Metadata"),
+		PayloadSampleRate: viper.GetFloat64("orion.AccessLog.PayloadSampleRate"),
+		RedactFields:      viper.GetStringSlice("orion.AccessLog.RedactFields"),
+	}
+}
+
+// BuildDefaultLogLevelOverrideConfig builds a default config for per request log level overrides
+func BuildDefaultLogLevelOverrideConfig() handlers.LogLevelOverrideConfig {
+	return handlers.LogLevelOverrideConfig{
+		Enabled:         viper.GetBool("orion.LogLevelOverride.Enabled"),
+		Secret:          viper.GetString("orion.LogLevelOverride.Secret"),
+		AllowedNetworks: viper.GetStringSlice("orion.LogLevelOverride.AllowedNetworks"),
+	}
+}
+
+// BuildDefaultLogSamplingConfig reads the log sampling config from [orion.LogSampling],
+// intervals are go durations e.g. Interval = "1s"
+func BuildDefaultLogSamplingConfig() sampler.Config {
+	config := sampler.Config{}
+	if err := viper.UnmarshalKey("orion.LogSampling", &config); err != nil {
+		log.Error(context.Background(), "config", "could not parse orion.LogSampling", "error", err)
+	}
+	return config
+}
+
+// BuildDefaultScrubConfig reads the scrubbing config from [orion.Scrub], Keys and Values are added to
+// loggers.DefaultScrubKeys and loggers.DefaultScrubValues
+func BuildDefaultScrubConfig() loggers.ScrubConfig {
+	config := loggers.ScrubConfig{}
+	if err := viper.UnmarshalKey("orion.Scrub", &config); err != nil {
+		log.Error(context.Background(), "config", "could not parse orion.Scrub", "error", err)
+	}
+	// defaults of nested keys are not applied when [orion.Scrub] is present
+	config.Enabled = viper.GetBool("orion.Scrub.Enabled")
+	return config
+}
+
+// BuildDefaultConcurrencyLimitConfig builds a default config for the concurrency limiter
+func BuildDefaultConcurrencyLimitConfig() interceptors.ConcurrencyLimitConfig {
+	return interceptors.ConcurrencyLimitConfig{
+		Enabled:          viper.GetBool("orion.ConcurrencyLimit.Enabled"),
+		InitialLimit:     viper.GetInt("orion.ConcurrencyLimit.InitialLimit"),
+		MinLimit:         viper.GetInt("orion.ConcurrencyLimit.MinLimit"),
+		MaxLimit:         viper.GetInt("orion.ConcurrencyLimit.MaxLimit"),
+		BackoffRatio:     viper.GetFloat64("orion.ConcurrencyLimit.BackoffRatio"),
+		LatencyThreshold: viper.GetInt("orion.ConcurrencyLimit.LatencyThreshold"),
+	}
+}
+
+// BuildDefaultTLSConfig builds a default config for TLS
+func BuildDefaultTLSConfig() TLSConfig {
+	return TLSConfig{
+		CertFile:          viper.GetString("orion.TLSCertFile"),
+		KeyFile:           viper.GetString("orion.TLSKeyFile"),
+		ClientCAFile:      viper.GetString("orion.TLSClientCAFile"),
+		RequireClientCert: viper.GetBool("orion.TLSRequireClientCert"),
 	}
 }
 
@@ -161,7 +405,7 @@ func BuildDefaultSessionTrackingConfig() SessionTrackingConfig {
 func BuildDefaultHystrixConfig() HystrixConfig {
 	return HystrixConfig{
 		Port:                         viper.GetString("orion.HystrixPort"),
-		CommandConfig:                make(map[string]hystrix.CommandConfig),
+		CommandConfig:                BuildDefaultHystrixCommandConfig(),
 		StatsdAddr:                   viper.GetString("orion.HystrixStatsd"),
 		DefaultTimeout:               viper.GetInt("orion.HystrixDefaultTimeout"),
 		DefaultMaxConcurrent:         viper.GetInt("orion.HystrixDefaultMaxConcurrent"),
@@ -171,6 +415,15 @@ func BuildDefaultHystrixConfig() HystrixConfig {
 	}
 }
 
+// BuildDefaultHystrixCommandConfig builds the per command circuit breaker config from orion.HystrixCommandConfig
+func BuildDefaultHystrixCommandConfig() map[string]breaker.CommandConfig {
+	cmds := make(map[string]breaker.CommandConfig)
+	if err := viper.UnmarshalKey("orion.HystrixCommandConfig", &cmds); err != nil {
+		log.Error(context.Background(), "config", "could not parse orion.HystrixCommandConfig", "error", err)
+	}
+	return cmds
+}
+
 // BuildDefaultZipkinConfig builds a default config for zipkin
 func BuildDefaultZipkinConfig() ZipkinConfig {
 	return ZipkinConfig{
@@ -178,6 +431,19 @@ func BuildDefaultZipkinConfig() ZipkinConfig {
 	}
 }
 
+// BuildDefaultOpenTelemetryConfig builds a default config for OpenTelemetry
+func BuildDefaultOpenTelemetryConfig() OpenTelemetryConfig {
+	return OpenTelemetryConfig{
+		Enabled:        viper.GetBool("orion.OpenTelemetry.Enabled"),
+		Protocol:       viper.GetString("orion.OpenTelemetry.Protocol"),
+		Endpoint:       viper.GetString("orion.OpenTelemetry.Endpoint"),
+		Insecure:       viper.GetBool("orion.OpenTelemetry.Insecure"),
+		ServiceName:    viper.GetString("orion.OpenTelemetry.ServiceName"),
+		SampleRatio:    viper.GetFloat64("orion.OpenTelemetry.SampleRatio"),
+		DisableMetrics: viper.GetBool("orion.OpenTelemetry.DisableMetrics"),
+	}
+}
+
 // BuildDefaultNewRelicConfig builds a default config for newrelic
 func BuildDefaultNewRelicConfig() NewRelicConfig {
 	return NewRelicConfig{
@@ -198,6 +464,9 @@ func setConfigDefaults() {
 	viper.SetDefault("orion.HTTPOnly", false)
 	viper.SetDefault("orion.EnableProtoURL", false)
 	viper.SetDefault("orion.ZipkinAddr", "")
+	viper.SetDefault("orion.OpenTelemetry.Enabled", false)
+	viper.SetDefault("orion.OpenTelemetry.Protocol", "grpc")
+	viper.SetDefault("orion.OpenTelemetry.SampleRatio", 1.0)
 	viper.SetDefault("orion.env", "dev")
 	viper.SetDefault("orion.rollbar-token", "")
 	viper.SetDefault("orion.HotReload", true)
@@ -205,6 +474,20 @@ func setConfigDefaults() {
 	viper.SetDefault("orion.EnablePrometheusHistogram", false)
 	viper.SetDefault("orion.Env", "development")
 	viper.SetDefault("orion.DefaultJSONPB", false)
+	viper.SetDefault("orion.DisableHealthCheck", false)
+	viper.SetDefault("orion.TLSRequireClientCert", false)
+	viper.SetDefault("orion.SinglePort", false)
+	viper.SetDefault("orion.ConcurrencyLimit.Enabled", false)
+	viper.SetDefault("orion.ConcurrencyLimit.InitialLimit", interceptors.DefaultConcurrencyInitialLimit)
+	viper.SetDefault("orion.ConcurrencyLimit.MaxLimit", interceptors.DefaultConcurrencyMaxLimit)
+	viper.SetDefault("orion.ConcurrencyLimit.BackoffRatio", interceptors.DefaultConcurrencyBackoffRatio)
+	viper.SetDefault("orion.AccessLog.Enabled", false)
+	viper.SetDefault("orion.AccessLog.PayloadSampleRate", 0.0)
+	viper.SetDefault("orion.LogLevelOverride.Enabled", false)
+	viper.SetDefault("orion.EnableAdminAPI", false)
+	viper.SetDefault("orion.LogSampling.Enabled", false)
+	viper.SetDefault("orion.Scrub.Enabled", true)
+	viper.SetDefault("orion.NotifierDedup.Enabled", false)
 
 	viper.SetDefault("orion.HystrixDefaultTimeout", 1000)
 	viper.SetDefault("orion.HystrixDefaultMaxConcurrent", 300)
@@ -234,7 +517,7 @@ func readConfig(name string) error {
 		log.Warn(ctx, "config", "config could not be read "+err.Error())
 		return fmt.Errorf("Config config could not be read %s", err.Error())
 	}
-	data, _ := json.MarshalIndent(viper.AllSettings(), "", "  ")
+	data, _ := json.MarshalIndent(MaskSecrets(viper.AllSettings()), "", "  ")
 	log.Info(ctx, "Config", string(data))
 	return nil
 }
@@ -0,0 +1,194 @@
+package orion
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carousell/Orion/interceptors"
+	"github.com/carousell/Orion/utils/breaker"
+	"github.com/carousell/Orion/utils/errors/notifier"
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/carousell/Orion/utils/log/loggers/sampler"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDefaultRateLimits(t *testing.T) {
+	defer viper.Reset()
+	viper.SetConfigType("toml")
+	assert.NoError(t, viper.ReadConfig(strings.NewReader(`
+[orion]
+HTTPPort = "9282"
+
+[[orion.RateLimits]]
+Method = "*"
+Rate = 100
+
+[[orion.RateLimits]]
+Method = "/pkg.Svc/Get"
+Rate = 1.5
+Burst = 3
+Key = "x-user-id"
+`)))
+	assert.Equal(t, []interceptors.RateLimitRule{
+		{Method: "*", Rate: 100},
+		{Method: "/pkg.Svc/Get", Rate: 1.5, Burst: 3, Key: "x-user-id"},
+	}, BuildDefaultRateLimits())
+
+	viper.Reset()
+	assert.Empty(t, BuildDefaultRateLimits())
+}
+
+func TestBuildDefaultTimeouts(t *testing.T) {
+	defer viper.Reset()
+	viper.SetConfigType("toml")
+	assert.NoError(t, viper.ReadConfig(strings.NewReader(`
+[[orion.Timeouts]]
+Method = "*"
+Timeout = "2s"
+
+[[orion.Timeouts]]
+Method = "/pkg.Svc/Get"
+Timeout = "150ms"
+`)))
+	assert.Equal(t, []interceptors.TimeoutRule{
+		{Method: "*", Timeout: 2 * time.Second},
+		{Method: "/pkg.Svc/Get", Timeout: 150 * time.Millisecond},
+	}, BuildDefaultTimeouts())
+}
+
+func TestBuildDefaultTimeoutsRequiresUnits(t *testing.T) {
+	defer viper.Reset()
+	viper.SetConfigType("toml")
+	assert.NoError(t, viper.ReadConfig(strings.NewReader(`
+[[orion.Timeouts]]
+Method = "*"
+Timeout = 500
+
+[[orion.Timeouts]]
+Method = "/pkg.Svc/Get"
+Timeout = "150ms"
+`)))
+	// only the rule without a unit is dropped
+	assert.Equal(t, []interceptors.TimeoutRule{
+		{Method: "/pkg.Svc/Get", Timeout: 150 * time.Millisecond},
+	}, BuildDefaultTimeouts())
+}
+
+func TestBuildDefaultAccessLogConfig(t *testing.T) {
+	defer viper.Reset()
+	viper.SetConfigType("toml")
+	assert.NoError(t, viper.ReadConfig(strings.NewReader(`
+[orion.AccessLog]
+Enabled = true
+Metadata = ["x-user-id", "user-agent"]
+PayloadSampleRate = 0.01
+RedactFields = ["password", "pkg.User.email"]
+`)))
+	assert.Equal(t, interceptors.AccessLogConfig{
+		Enabled:           true,
+		Metadata:          []string{"x-user-id", "user-agent"},
+		PayloadSampleRate: 0.01,
+		RedactFields:      []string{"password", "pkg.User.email"},
+	}, BuildDefaultAccessLogConfig())
+}
+
+func TestBuildDefaultNotifierMinSeverity(t *testing.T) {
+	defer viper.Reset()
+	viper.SetConfigType("toml")
+	assert.NoError(t, viper.ReadConfig(strings.NewReader(`
+[orion.NotifierMinSeverity]
+rollbar = "warning"
+sentry = "error"
+`)))
+	assert.Equal(t, map[string]string{"rollbar": "warning", "sentry": "error"}, BuildDefaultNotifierMinSeverity())
+}
+
+func TestBuildDefaultNotifierDedupConfig(t *testing.T) {
+	defer viper.Reset()
+	viper.SetConfigType("toml")
+	assert.NoError(t, viper.ReadConfig(strings.NewReader(`
+[orion.NotifierDedup]
+Enabled = true
+Window = "30s"
+Burst = 3
+`)))
+	assert.Equal(t, notifier.DedupConfig{
+		Enabled: true,
+		Window:  30 * time.Second,
+		Burst:   3,
+	}, BuildDefaultNotifierDedupConfig())
+}
+
+func TestBuildDefaultHTTPErrorStatus(t *testing.T) {
+	defer viper.Reset()
+	viper.SetConfigType("toml")
+	assert.NoError(t, viper.ReadConfig(strings.NewReader(`
+[orion.HTTPErrorStatus]
+FailedPrecondition = 412
+Canceled = 499
+`)))
+	assert.Equal(t, map[string]int{"failedprecondition": 412, "canceled": 499}, BuildDefaultHTTPErrorStatus())
+}
+
+func TestBuildDefaultHystrixCommandConfig(t *testing.T) {
+	defer viper.Reset()
+	viper.SetConfigType("toml")
+	assert.NoError(t, viper.ReadConfig(strings.NewReader(`
+[orion.HystrixCommandConfig.GetUser]
+Timeout = 300
+MaxConcurrentRequests = 50
+ErrorPercentThreshold = 25
+
+[orion.HystrixCommandConfig.ListUsers]
+requestvolumethreshold = 10
+SleepWindow = 2000
+`)))
+	assert.Equal(t, map[string]breaker.CommandConfig{
+		"getuser":   {Timeout: 300, MaxConcurrentRequests: 50, ErrorPercentThreshold: 25},
+		"listusers": {RequestVolumeThreshold: 10, SleepWindow: 2000},
+	}, BuildDefaultHystrixCommandConfig())
+}
+
+func TestBuildDefaultScrubConfig(t *testing.T) {
+	defer viper.Reset()
+	setConfigDefaults()
+	assert.Equal(t, loggers.ScrubConfig{Enabled: true}, BuildDefaultScrubConfig())
+
+	viper.SetConfigType("toml")
+	assert.NoError(t, viper.ReadConfig(strings.NewReader(`
+[orion.Scrub]
+Keys = ["ssn"]
+Values = ["S\\d{7}[A-Z]"]
+`)))
+	assert.Equal(t, loggers.ScrubConfig{
+		Enabled: true,
+		Keys:    []string{"ssn"},
+		Values:  []string{`S\d{7}[A-Z]`},
+	}, BuildDefaultScrubConfig())
+}
+
+func TestBuildDefaultLogSamplingConfig(t *testing.T) {
+	defer viper.Reset()
+	viper.SetConfigType("toml")
+	assert.NoError(t, viper.ReadConfig(strings.NewReader(`
+[orion.LogSampling]
+Enabled = true
+Interval = "2s"
+First = 10
+Thereafter = 100
+SummaryInterval = "5m"
+
+[orion.LogSampling.Levels.debug]
+First = 1
+`)))
+	assert.Equal(t, sampler.Config{
+		Enabled:         true,
+		Interval:        2 * time.Second,
+		First:           10,
+		Thereafter:      100,
+		SummaryInterval: 5 * time.Minute,
+		Levels:          map[string]sampler.Rule{"debug": {First: 1}},
+	}, BuildDefaultLogSamplingConfig())
+}
@@ -2,8 +2,10 @@ package orion
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"os/signal"
 	"reflect"
@@ -17,6 +19,7 @@ import (
 	"github.com/carousell/Orion/orion/handlers"
 	grpcHandler "github.com/carousell/Orion/orion/handlers/grpc"
 	"github.com/carousell/Orion/orion/handlers/http"
+	"github.com/carousell/Orion/orion/health"
 	"github.com/carousell/Orion/utils/errors/notifier"
 	"github.com/carousell/Orion/utils/listenerutils"
 	"github.com/carousell/Orion/utils/log"
@@ -27,19 +30,30 @@ var (
 	ErrNil = errors.New("nil argument passed")
 	//ErrNotServiceFactory when passed argument is not a service factory
 	ErrNotServiceFactory = errors.New("you need to pass either a ServiceFactory or ServiceFactoryV2")
+	//ErrHotReloadDisabled when a reload is requested but HotReload is not enabled
+	ErrHotReloadDisabled = errors.New("hot reload disabled")
 )
 
 type svcInfo struct {
-	sd *grpc.ServiceDesc
-	sf ServiceFactoryV2
-	ss interface{}
+	sd     *grpc.ServiceDesc
+	sf     ServiceFactoryV2
+	ss     interface{}
+	checks []string
 }
 
 type handlerInfo struct {
+	name     string
 	handler  handlers.Handler
 	listener listenerutils.CustomListener
 }
 
+type handlerRegistration struct {
+	name    string
+	network string
+	address string
+	handler handlers.Handler
+}
+
 type encoderInfo struct {
 	serviceName string
 	method      string
@@ -70,6 +84,7 @@ type middlewareInfo struct {
 // DefaultServerImpl provides a default implementation of orion.Server this can be embedded in custom orion.Server implementations
 type DefaultServerImpl struct {
 	config                    Config
+	configMu                  sync.RWMutex
 	mu                        sync.Mutex
 	wg                        sync.WaitGroup
 	grpcUnknownServiceHandler grpc.StreamHandler
@@ -79,12 +94,17 @@ type DefaultServerImpl struct {
 	encoders     map[string]*encoderInfo
 	decoders     map[string]*decoderInfo
 	defDecoders  map[string]handlers.Decoder
+	defErrEncs   map[string]handlers.ErrorEncoder
 	defEncoders  map[string]handlers.Encoder
 	options      map[string]*optionInfo
 	middlewares  map[string]*middlewareInfo
 	handlers     []*handlerInfo
+	extHandlers  []*handlerRegistration
 	initializers []Initializer
 	version      uint64
+	health       *health.Registry
+	certs        *listenerutils.CertReloader
+	reloadMu     sync.Mutex
 }
 
 // AddMiddleware adds middlewares for particular service/method
@@ -179,6 +199,14 @@ func (d *DefaultServerImpl) AddDefaultDecoder(serviceName string, decoder Decode
 	d.defDecoders[serviceName] = decoder
 }
 
+// AddDefaultErrorEncoder is the implementation of handlers.ErrorEncodable
+func (d *DefaultServerImpl) AddDefaultErrorEncoder(serviceName string, encoder ErrorEncoder) {
+	if d.defErrEncs == nil {
+		d.defErrEncs = make(map[string]handlers.ErrorEncoder)
+	}
+	d.defErrEncs[serviceName] = encoder
+}
+
 // AddOption adds a option for the particular service/method
 func (d *DefaultServerImpl) AddOption(serviceName, method, option string) {
 	if d.options == nil {
@@ -194,6 +222,8 @@ func (d *DefaultServerImpl) AddOption(serviceName, method, option string) {
 // GetOrionConfig returns current orion config
 // NOTE: this config can not be modifies
 func (d *DefaultServerImpl) GetOrionConfig() Config {
+	d.configMu.RLock()
+	defer d.configMu.RUnlock()
 	return d.config
 }
 
@@ -201,6 +231,8 @@ func (d *DefaultServerImpl) init(reload bool) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	if d.inited != true {
+		d.initHealth()
+		d.initTLS()
 		d.initHandlers()
 		d.initInitializers(reload)
 		d.inited = true
@@ -230,54 +262,184 @@ func (d *DefaultServerImpl) processInitializers(reload bool) {
 	}
 }
 
+type singlePortListeners struct {
+	http listenerutils.CustomListener
+	grpc listenerutils.CustomListener
+}
+
+// buildSinglePortListeners creates gRPC and HTTP listeners sharing HTTPPort when SinglePort is enabled
+func (d *DefaultServerImpl) buildSinglePortListeners() singlePortListeners {
+	config := d.GetOrionConfig()
+	if !config.SinglePort || config.GRPCOnly || config.HTTPOnly {
+		return singlePortListeners{}
+	}
+	lis, err := net.Listen("tcp", ":"+config.HTTPPort)
+	if err != nil {
+		log.Error(context.Background(), "singlePort", "could not create listener", "error", err)
+		return singlePortListeners{}
+	}
+	if d.certs != nil {
+		lis = tls.NewListener(lis, d.certs.TLSConfig("h2", "http/1.1"))
+	}
+	log.Info(context.Background(), "SinglePort", config.HTTPPort)
+	mux := listenerutils.NewMuxWithListener(lis)
+	return singlePortListeners{
+		http: mux.HTTP(),
+		grpc: mux.GRPC(),
+	}
+}
+
 func (d *DefaultServerImpl) buildHandlers() []*handlerInfo {
+	config := d.GetOrionConfig()
 	hlrs := []*handlerInfo{}
-	if !d.config.GRPCOnly {
-		httpPort := d.config.HTTPPort
-		httpListener, err := listenerutils.NewListener("tcp", ":"+httpPort)
-		if err != nil {
-			log.Error(context.Background(), "httpListener", "could not create listener", "error", err)
+	singlePort := d.buildSinglePortListeners()
+	if !config.GRPCOnly {
+		httpPort := config.HTTPPort
+		httpListener := singlePort.http
+		if httpListener == nil {
+			var err error
+			httpListener, err = listenerutils.NewListener("tcp", ":"+httpPort)
+			if err != nil {
+				log.Error(context.Background(), "httpListener", "could not create listener", "error", err)
+			}
+			if httpListener != nil && d.certs != nil {
+				httpListener = listenerutils.NewTLSListener(httpListener, d.certs.TLSConfig("h2", "http/1.1"))
+			}
 		}
 		log.Info(context.Background(), "HTTPListenerPort", httpPort)
+		errorStatus, err := http.ParseErrorStatus(config.HTTPErrorStatus)
+		if err != nil {
+			log.Error(context.Background(), "config", "ignoring invalid orion.HTTPErrorStatus entries", "error", err)
+		}
 		config := http.Config{
 			CommonConfig: handlers.CommonConfig{
-				DisableDefaultInterceptors: d.config.DisableDefaultInterceptors,
+				DisableDefaultInterceptors: config.DisableDefaultInterceptors,
 			},
-			EnableProtoURL:   d.config.EnableProtoURL,
-			DefaultJSONPB:    d.config.DefaultJSONPB,
-			NRHttpTxNameType: d.config.NewRelicConfig.HttpTxNameType,
-			ReadTimeout:      d.config.ReadTimeout,
-			WriteTimeout:     d.config.WriteTimeout,
+			EnableProtoURL:   config.EnableProtoURL,
+			DefaultJSONPB:    config.DefaultJSONPB,
+			ErrorStatus:      errorStatus,
+			NRHttpTxNameType: config.NewRelicConfig.HttpTxNameType,
+			ReadTimeout:      config.ReadTimeout,
+			WriteTimeout:     config.WriteTimeout,
+			// HTTP/2 clients that are not gRPC reach the HTTP handler unencrypted after the mux
+			EnableH2C: singlePort.http != nil,
 		}
 		handler := http.NewHTTPHandler(config)
 		hlrs = append(hlrs, &handlerInfo{
+			name:     "http",
 			handler:  handler,
 			listener: httpListener,
 		})
 	}
-	if !d.config.HTTPOnly {
-		grpcPort := d.config.GRPCPort
-		grpcListener, err := listenerutils.NewListener("tcp", ":"+grpcPort)
-		if err != nil {
-			log.Info(context.Background(), "grpcListener", "could not create listener", "error", err)
+	if !config.HTTPOnly {
+		grpcPort := config.GRPCPort
+		grpcListener := singlePort.grpc
+		if grpcListener == nil {
+			var err error
+			grpcListener, err = listenerutils.NewListener("tcp", ":"+grpcPort)
+			if err != nil {
+				log.Info(context.Background(), "grpcListener", "could not create listener", "error", err)
+			}
+		} else {
+			grpcPort = config.HTTPPort
 		}
 		log.Info(context.Background(), "gRPCListenerPort", grpcPort)
 		config := grpcHandler.Config{
 			CommonConfig: handlers.CommonConfig{
-				DisableDefaultInterceptors: d.config.DisableDefaultInterceptors,
+				DisableDefaultInterceptors: config.DisableDefaultInterceptors,
 			},
 			UnknownServiceHandler: d.grpcUnknownServiceHandler,
-			MaxRecvMsgSize:        d.config.MaxRecvMsgSize,
+			MaxRecvMsgSize:        config.MaxRecvMsgSize,
+		}
+		if d.certs != nil && singlePort.grpc == nil {
+			// in single port mode TLS is terminated before the mux
+			config.TLSConfig = d.certs.TLSConfig()
 		}
 		handler := grpcHandler.NewGRPCHandler(config)
 		hlrs = append(hlrs, &handlerInfo{
+			name:     "grpc",
 			handler:  handler,
 			listener: grpcListener,
 		})
 	}
+	for _, reg := range d.extHandlers {
+		listener, err := listenerutils.NewListener(reg.network, reg.address)
+		if err != nil {
+			log.Error(context.Background(), "handler", reg.name, "msg", "could not create listener", "error", err)
+			continue
+		}
+		log.Info(context.Background(), "handler", reg.name, "network", reg.network, "address", reg.address)
+		hlrs = append(hlrs, &handlerInfo{
+			name:     reg.name,
+			handler:  reg.handler,
+			listener: listener,
+		})
+	}
 	return hlrs
 }
 
+func (d *DefaultServerImpl) initHealth() {
+	if d.health == nil {
+		d.health = health.NewRegistry()
+	}
+}
+
+// AddHealthCheck adds a named check that is evaluated on every readiness probe
+func (d *DefaultServerImpl) AddHealthCheck(name string, check health.Check) {
+	d.initHealth()
+	d.health.AddCheck(name, check)
+}
+
+// GetHealth returns the health registry used by this server
+func (d *DefaultServerImpl) GetHealth() *health.Registry {
+	d.initHealth()
+	return d.health
+}
+
+//GetRoutes returns the routes currently served by all handlers
+func (d *DefaultServerImpl) GetRoutes() []handlers.RouteInfo {
+	d.mu.Lock()
+	hs := append([]*handlerInfo{}, d.handlers...)
+	d.mu.Unlock()
+	routes := make([]handlers.RouteInfo, 0)
+	for _, h := range hs {
+		if r, ok := h.handler.(handlers.RouteReporter); ok {
+			for _, route := range r.GetRoutes() {
+				route.Handler = h.name
+				routes = append(routes, route)
+			}
+		}
+	}
+	return routes
+}
+
+func (d *DefaultServerImpl) initTLS() {
+	config := d.GetOrionConfig()
+	if !config.TLSConfig.Enabled() {
+		return
+	}
+	tlsConfig := config.TLSConfig
+	certs, err := listenerutils.NewCertReloader(tlsConfig.CertFile, tlsConfig.KeyFile, tlsConfig.ClientCAFile, tlsConfig.RequireClientCert)
+	if err != nil {
+		// we should not fallback to serving plain text when TLS has been asked for
+		panic("Error: could not initialize TLS: " + err.Error())
+	}
+	d.certs = certs
+	log.Info(context.Background(), "TLS", "enabled", "cert", tlsConfig.CertFile, "clientCA", tlsConfig.ClientCAFile)
+}
+
+func (d *DefaultServerImpl) reloadTLS() {
+	if d.certs == nil {
+		return
+	}
+	if err := d.certs.Reload(); err != nil {
+		notifier.NotifyWithLevel(err, "critical", "Error reloading TLS certificates")
+		log.Error(context.Background(), "Error", err, "msg", "TLS certificates not reloaded")
+		return
+	}
+	log.Info(context.Background(), "TLS", "certificates reloaded")
+}
+
 func (d *DefaultServerImpl) initHandlers() {
 	d.handlers = d.buildHandlers()
 }
@@ -288,45 +450,14 @@ func (d *DefaultServerImpl) signalWatcher() {
 	signal.Notify(c, syscall.SIGHUP, syscall.SIGTERM, syscall.SIGINT)
 	for sig := range c {
 		if sig == syscall.SIGHUP { // only reload config for sighup
-			if !d.config.HotReload {
-				log.Warn(context.Background(), "signal", "config reload SKIPPED (Hot reload disabled) on "+sig.String())
+			log.Info(context.Background(), "signal", "reloading on "+sig.String())
+			if err := d.Reload(); err != nil {
 				continue
 			}
-			d.version++
-			log.Info(context.Background(), "signal", "config reloaded on "+sig.String())
-			// relaod config
-			err := readConfig(d.config.OrionServerName)
-			if err != nil {
-				notifier.NotifyWithLevel(err, "critical", "Error parsing config not reloading services")
-				log.Error(context.Background(), "Error", err, "msg", "not reloading services")
-				continue
-			}
-
-			// reload initializers
-			d.processInitializers(true)
-
-			// reload services
-			oldServices := []*svcInfo{}
-			for _, info := range d.services {
-				d.registerService(info.sd, info.sf, true)
-				oldServices = append(oldServices, info)
-			}
-
-			// reload handlers
-			for _, h := range d.handlers {
-				d.startHandler(h, true)
-			}
-
-			//dispose the older service object
-			for _, info := range oldServices {
-				params := FactoryParams{
-					ServiceName: info.sd.ServiceName,
-					Version:     d.version - 1,
-				}
-				info.sf.DisposeService(info.ss, params)
-			}
 		} else if sig == syscall.SIGTERM || sig == syscall.SIGINT {
 			log.Info(context.Background(), "signal", "starting shutdown on "+sig.String())
+			// stop reporting ready before we start draining connections
+			d.health.Shutdown()
 			d.Stop(30 * time.Second)
 			break
 		} else {
@@ -341,10 +472,65 @@ func (d *DefaultServerImpl) signalWatcher() {
 	}
 }
 
+// Reload reloads TLS certificates and, when HotReload is enabled, re-reads the config, rebuilds the server config
+// from it and reinitializes initializers, services and handlers, this is what happens on SIGHUP
+func (d *DefaultServerImpl) Reload() error {
+	d.reloadMu.Lock()
+	defer d.reloadMu.Unlock()
+
+	// certificates are always reloaded, cert rotation should not need a hot reload of services
+	d.reloadTLS()
+	current := d.GetOrionConfig()
+	if !current.HotReload {
+		log.Warn(context.Background(), "reload", "config reload SKIPPED (Hot reload disabled)")
+		return ErrHotReloadDisabled
+	}
+	d.version++
+	log.Info(context.Background(), "reload", "config reloaded")
+	// relaod config
+	err := readConfig(current.OrionServerName)
+	if err != nil {
+		notifier.NotifyWithLevel(err, "critical", "Error parsing config not reloading services")
+		log.Error(context.Background(), "Error", err, "msg", "not reloading services")
+		return err
+	}
+	// rebuild the server config so that initializers read the reloaded values in ReInit
+	config := buildConfig(current.OrionServerName)
+	d.configMu.Lock()
+	d.config = config
+	d.configMu.Unlock()
+
+	// reload initializers
+	d.processInitializers(true)
+
+	// reload services
+	oldServices := []*svcInfo{}
+	for _, info := range d.services {
+		d.registerService(info.sd, info.sf, true)
+		oldServices = append(oldServices, info)
+	}
+
+	// reload handlers
+	for _, h := range d.handlers {
+		d.startHandler(h, true)
+	}
+
+	//dispose the older service object
+	for _, info := range oldServices {
+		params := FactoryParams{
+			ServiceName: info.sd.ServiceName,
+			Version:     d.version - 1,
+		}
+		info.sf.DisposeService(info.ss, params)
+	}
+	return nil
+}
+
 // Start starts the orion server
 func (d *DefaultServerImpl) Start() {
+	config := d.GetOrionConfig()
 	fmt.Println(BANNER)
-	if d.config.HTTPOnly && d.config.GRPCOnly {
+	if config.HTTPOnly && config.GRPCOnly && len(d.extHandlers) == 0 {
 		panic("Error: at least one GRPC or HTTP server needs to be initialized")
 	}
 
@@ -361,6 +547,11 @@ func (d *DefaultServerImpl) startHandler(h *handlerInfo, reload bool) {
 		h.listener = h.listener.GetListener()
 	}
 
+	//Expose health, needs to be set before services are added
+	if e, ok := h.handler.(handlers.Healthable); ok && !d.GetOrionConfig().DisableHealthCheck {
+		e.SetHealth(d.health)
+	}
+
 	//Add all services first
 	for _, info := range d.services {
 		h.handler.Add(info.sd, info.ss)
@@ -406,6 +597,13 @@ func (d *DefaultServerImpl) startHandler(h *handlerInfo, reload bool) {
 		}
 	}
 
+	//Add all default error encoders
+	if e, ok := h.handler.(handlers.ErrorEncodable); ok {
+		for svc, enc := range d.defErrEncs {
+			e.AddDefaultErrorEncoder(svc, enc)
+		}
+	}
+
 	// Add all middlewares
 	if e, ok := h.handler.(handlers.Middlewareable); ok {
 		for _, mi := range d.middlewares {
@@ -462,11 +660,25 @@ func (d *DefaultServerImpl) registerService(sd *grpc.ServiceDesc, sf ServiceFact
 		return fmt.Errorf("Orion.Server.RegisterService found the handler of type %v that does not satisfy %v", st, ht)
 	}
 
-	d.services[sd.ServiceName] = &svcInfo{
+	info := &svcInfo{
 		sd: sd,
 		sf: sf,
 		ss: ss,
 	}
+	d.initHealth()
+	if old, ok := d.services[sd.ServiceName]; ok {
+		for _, name := range old.checks {
+			d.health.RemoveCheck(name)
+		}
+	}
+	d.health.AddService(sd.ServiceName)
+	if c, ok := ss.(health.Checker); ok {
+		for name, check := range c.GetHealthChecks() {
+			d.health.AddCheck(name, check)
+			info.checks = append(info.checks, name)
+		}
+	}
+	d.services[sd.ServiceName] = info
 	return nil
 
 }
@@ -487,8 +699,19 @@ func (d *DefaultServerImpl) GetConfig() map[string]interface{} {
 	return viper.AllSettings()
 }
 
-// Stop stops the server
+// Stop stops the server, handlers, initializers and error reporters all have to stop within timeout
 func (d *DefaultServerImpl) Stop(timeout time.Duration) error {
+	// flip readiness first so that load balancers stop sending traffic
+	d.initHealth()
+	d.health.Shutdown()
+	// all steps share timeout, each one gets the time left by the previous ones
+	deadline := time.Now().Add(timeout)
+	remaining := func() time.Duration {
+		if left := time.Until(deadline); left > 0 {
+			return left
+		}
+		return 0
+	}
 	var wg sync.WaitGroup
 	for _, h := range d.handlers {
 		h.listener.CanClose(true)
@@ -496,9 +719,19 @@ func (d *DefaultServerImpl) Stop(timeout time.Duration) error {
 		go func(h *handlerInfo, timeout time.Duration) {
 			defer wg.Done()
 			h.handler.Stop(timeout)
-		}(h, timeout)
+		}(h, remaining())
 	}
 	wg.Wait()
+	// flush telemetry and logs still buffered by initializers
+	for _, in := range d.initializers {
+		if s, ok := in.(StoppableInitializer); ok {
+			if err := s.Stop(remaining()); err != nil {
+				log.Warn(context.Background(), "stop", "initializer did not stop cleanly", "error", err)
+			}
+		}
+	}
+	// deliver errors still queued for error reporters
+	notifier.Flush(remaining())
 	return nil
 }
 
@@ -514,10 +747,14 @@ func GetDefaultServer(name string, opts ...DefaultServerOption) Server {
 }
 
 // GetDefaultServerWithConfig returns a default server object that uses provided configuration
-func GetDefaultServerWithConfig(config Config) Server {
-	return &DefaultServerImpl{
+func GetDefaultServerWithConfig(config Config, opts ...DefaultServerOption) Server {
+	server := &DefaultServerImpl{
 		config: config,
 	}
+	for _, opt := range opts {
+		opt.apply(server)
+	}
+	return server
 }
 
 type DefaultServerOption interface {
@@ -532,6 +769,23 @@ func WithGrpcUnknownHandler(grpcUnknownServiceHandler grpc.StreamHandler) Defaul
 	})
 }
 
+// WithHandler returns a DefaultServerOption which adds an additional handler to orion server,
+// the handler is served on its own listener created for network/address (for example "tcp", ":9290" or "unix", "/tmp/svc.sock")
+// and receives the same services, encoders, decoders, options and middlewares as the default handlers
+func WithHandler(name, network, address string, handler handlers.Handler) DefaultServerOption {
+	return newFuncDefaultServerOption(func(h *DefaultServerImpl) {
+		if handler == nil {
+			return
+		}
+		h.extHandlers = append(h.extHandlers, &handlerRegistration{
+			name:    name,
+			network: network,
+			address: address,
+			handler: handler,
+		})
+	})
+}
+
 type funcDefaultServerOption struct {
 	f func(options *DefaultServerImpl)
 }
@@ -0,0 +1,215 @@
+package orion
+
+import (
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/carousell/Orion/orion/health"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type testHandlerService interface {
+	Ping()
+}
+
+type testHandlerServiceImpl struct{}
+
+func (t *testHandlerServiceImpl) Ping() {}
+
+type testHandlerFactory struct{}
+
+func (f *testHandlerFactory) NewService(svr Server) interface{} {
+	return &testHandlerServiceImpl{}
+}
+
+func (f *testHandlerFactory) DisposeService(svc interface{}) {}
+
+var testHandlerServiceDesc = grpc.ServiceDesc{
+	ServiceName: "test.TestHandlerService",
+	HandlerType: (*testHandlerService)(nil),
+}
+
+// fakeHandler records everything startHandler replays to it
+type fakeHandler struct {
+	mu          sync.Mutex
+	services    []string
+	middlewares map[string][]string
+	health      *health.Registry
+	running     chan net.Listener
+	stopped     chan struct{}
+}
+
+func newFakeHandler() *fakeHandler {
+	return &fakeHandler{
+		middlewares: make(map[string][]string),
+		running:     make(chan net.Listener, 1),
+		stopped:     make(chan struct{}),
+	}
+}
+
+func (f *fakeHandler) Add(sd *grpc.ServiceDesc, ss interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.services = append(f.services, sd.ServiceName)
+	return nil
+}
+
+func (f *fakeHandler) AddMiddleware(serviceName, method string, middleware ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.middlewares[serviceName+"/"+method] = middleware
+}
+
+func (f *fakeHandler) SetHealth(registry *health.Registry) {
+	f.health = registry
+}
+
+func (f *fakeHandler) Run(lis net.Listener) error {
+	f.running <- lis
+	<-f.stopped
+	return nil
+}
+
+func (f *fakeHandler) Stop(timeout time.Duration) error {
+	close(f.stopped)
+	return nil
+}
+
+func TestWithHandler(t *testing.T) {
+	fake := newFakeHandler()
+	svr := GetDefaultServerWithConfig(Config{
+		GRPCOnly: true,
+		HTTPOnly: true,
+	}, WithHandler("fake", "tcp", "127.0.0.1:0", fake))
+	// skip default initializers
+	svr.AddInitializers()
+
+	assert.NoError(t, svr.RegisterService(&testHandlerServiceDesc, &testHandlerFactory{}))
+	RegisterMiddleware(svr, "TestHandlerService", "Ping", "Auth")
+	svr.Start()
+
+	select {
+	case lis := <-fake.running:
+		assert.NotNil(t, lis)
+		assert.Equal(t, "tcp", lis.Addr().Network())
+	case <-time.After(time.Second * 5):
+		t.Fatal("additional handler was not started")
+	}
+
+	fake.mu.Lock()
+	assert.Equal(t, []string{"test.TestHandlerService"}, fake.services)
+	assert.Equal(t, []string{"Auth"}, fake.middlewares["TestHandlerService/Ping"])
+	fake.mu.Unlock()
+	assert.NotNil(t, fake.health)
+
+	assert.NoError(t, svr.Stop(time.Second))
+	assert.True(t, fake.health.IsDraining())
+	assert.NoError(t, svr.Wait())
+}
+
+type configRecorder struct {
+	configs []Config
+}
+
+func (c *configRecorder) Init(svr Server) error {
+	c.configs = append(c.configs, svr.GetOrionConfig())
+	return nil
+}
+
+func (c *configRecorder) ReInit(svr Server) error {
+	return c.Init(svr)
+}
+
+func TestReloadRebuildsConfig(t *testing.T) {
+	defer viper.Reset()
+	file := filepath.Join(t.TempDir(), "ReloadTest.toml")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(`
+[orion]
+HotReload = true
+RateLimits = [{ Method = "/svc/Method", Rate = 5.0 }]
+`), 0644))
+	viper.SetConfigFile(file)
+
+	recorder := &configRecorder{}
+	svr := GetDefaultServerWithConfig(Config{OrionServerName: "ReloadTest", HotReload: true}).(*DefaultServerImpl)
+	svr.initializers = []Initializer{recorder}
+	assert.NoError(t, svr.Reload())
+	if assert.Len(t, recorder.configs, 1) {
+		assert.Equal(t, "ReloadTest", recorder.configs[0].OrionServerName)
+		assert.Len(t, recorder.configs[0].RateLimits, 1)
+	}
+	assert.Equal(t, recorder.configs[0].RateLimits, svr.GetOrionConfig().RateLimits)
+}
+
+func TestReloadDuringServe(t *testing.T) {
+	defer viper.Reset()
+	file := filepath.Join(t.TempDir(), "ReloadTest.toml")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(`
+[orion]
+HotReload = true
+GRPCOnly = true
+HTTPOnly = true
+`), 0644))
+	viper.SetConfigFile(file)
+
+	svr := GetDefaultServerWithConfig(Config{OrionServerName: "ReloadTest", HotReload: true, GRPCOnly: true, HTTPOnly: true}).(*DefaultServerImpl)
+	svr.initializers = []Initializer{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			assert.NoError(t, svr.Reload())
+		}
+	}()
+	// handlers read the config while it is reloaded
+	fakes := make([]*fakeHandler, 0)
+	for i := 0; i < 20; i++ {
+		assert.Empty(t, svr.buildHandlers())
+		fake := newFakeHandler()
+		fakes = append(fakes, fake)
+		svr.startHandler(&handlerInfo{handler: fake}, false)
+	}
+	<-done
+	for _, fake := range fakes {
+		fake.Stop(time.Second)
+	}
+	assert.NoError(t, svr.Wait())
+}
+
+type stopRecorder struct {
+	configRecorder
+	timeout time.Duration
+	// sleep is how much of its timeout Stop uses
+	sleep time.Duration
+}
+
+func (s *stopRecorder) Stop(timeout time.Duration) error {
+	s.timeout = timeout
+	time.Sleep(s.sleep)
+	return nil
+}
+
+func TestStopStopsInitializers(t *testing.T) {
+	recorder := &stopRecorder{}
+	svr := GetDefaultServerWithConfig(Config{}).(*DefaultServerImpl)
+	svr.initializers = []Initializer{recorder}
+	assert.NoError(t, svr.Stop(2*time.Second))
+	assert.True(t, recorder.timeout > time.Second && recorder.timeout <= 2*time.Second, recorder.timeout)
+}
+
+func TestStopSharesTimeout(t *testing.T) {
+	first := &stopRecorder{sleep: 100 * time.Millisecond}
+	second := &stopRecorder{}
+	svr := GetDefaultServerWithConfig(Config{}).(*DefaultServerImpl)
+	svr.initializers = []Initializer{first, second}
+	assert.NoError(t, svr.Stop(300*time.Millisecond))
+	// the second initializer only gets the time left by the first one
+	assert.True(t, second.timeout <= 200*time.Millisecond, second.timeout)
+	assert.True(t, second.timeout > 0, second.timeout)
+}
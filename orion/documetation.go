@@ -79,13 +79,28 @@ This contains:
 Whats Incuded
 
 Orion comes included with.
-	Hystrix (http://github.com/afex/hystrix-go)
+	Hystrix style circuit breakers (http://github.com/carousell/Orion/utils/breaker) for unary and streaming client calls, with a hystrix dashboard stream
 	Zipkin (http://github.com/opentracing/opentracing-go)
+	OpenTelemetry (https://opentelemetry.io) with OTLP exporters and W3C trace context propagation
 	NewRelic (http://github.com/newrelic/go-agent)
 	Prometheus (http://github.com/grpc-ecosystem/go-grpc-prometheus)
 	Pprof (https://golang.org/pkg/net/http/pprof/))
 	Configuration (http://github.com/spf13/viper)
 	Live Configuration Reload (http://github.com/carousell/Orion/utils/listenerutils)
+	Route introspection ('/orion/routes' and '/orion/openapi.json' on pprof port)
+	Per method and per service rate limits configured as [[orion.RateLimits]] (http://github.com/carousell/Orion/interceptors)
+	Adaptive load shedding configured under [orion.ConcurrencyLimit] (http://github.com/carousell/Orion/interceptors)
+	Method and stream timeouts from 'ORION:OPTION: TIMEOUT=500ms', [[orion.Timeouts]] (durations need a unit e.g. "500ms") and the X-Request-Timeout header (e.g. "1.5s")
+	Access logs with sampled, redacted payloads configured under [orion.AccessLog] (http://github.com/carousell/Orion/interceptors)
+	Per request log levels through the x-orion-log-level header/metadata, authorized under [orion.LogLevelOverride]
+	Admin API ('/orion/admin/loglevel', '/orion/admin/config' and '/orion/admin/reload' on pprof port) when orion.EnableAdminAPI is set
+	Sampling of repeated logs configured under [orion.LogSampling] (http://github.com/carousell/Orion/utils/log/loggers/sampler)
+	Pluggable error reporters with per reporter severity thresholds configured under [orion.NotifierMinSeverity] (http://github.com/carousell/Orion/utils/errors/notifier)
+	Deduplication of reported errors configured under [orion.NotifierDedup]
+	Typed errors with google.rpc error details (http://github.com/carousell/Orion/utils/errors) rendered as application/problem+json over HTTP
+	Configurable HTTP error encoders and gRPC to HTTP status mapping with a JSON error envelope when JSON is negotiated
+	Sentry error reporting with per request scopes and log breadcrumbs, release set by orion.SentryRelease
+	Scrubbing of sensitive fields (passwords, tokens, cookies, emails), card numbers and struct values from logs and error reports configured under [orion.Scrub]
 	And much more...
 
 Getting Started
@@ -99,3 +114,4 @@ package orion
 //go:generate godoc2ghmd -ex -file=handlers/README.md github.com/carousell/Orion/orion/handlers
 //go:generate godoc2ghmd -ex -file=modifiers/README.md github.com/carousell/Orion/orion/modifiers
 //go:generate godoc2ghmd -ex -file=helpers/README.md github.com/carousell/Orion/orion/helpers
+//go:generate godoc2ghmd -ex -file=health/README.md github.com/carousell/Orion/orion/health
@@ -36,6 +36,14 @@ func RegisterDefaultDecoder(svr Server, serviceName string, decoder Decoder) {
 	}
 }
 
+//RegisterDefaultErrorEncoder allows for registering an HTTP error encoder for the entire service, it controls the
+//status code, headers and body of error responses
+func RegisterDefaultErrorEncoder(svr Server, serviceName string, encoder ErrorEncoder) {
+	if e, ok := svr.(handlers.ErrorEncodable); ok {
+		e.AddDefaultErrorEncoder(serviceName, encoder)
+	}
+}
+
 //RegisterDecoder allows for registering an HTTP request decoder to a method
 //Note: this is normally called from protoc-gen-orion autogenerated files
 func RegisterDecoder(svr Server, serviceName, method string, decoder Decoder) {
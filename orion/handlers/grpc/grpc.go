@@ -2,14 +2,22 @@ package grpc
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/carousell/Orion/interceptors"
 	"github.com/carousell/Orion/orion/handlers"
+	"github.com/carousell/Orion/orion/health"
 	"github.com/carousell/Orion/utils/log"
+	"github.com/carousell/Orion/utils/options"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 // Config is the configuration for GRPC Handler
@@ -17,6 +25,8 @@ type Config struct {
 	handlers.CommonConfig
 	UnknownServiceHandler grpc.StreamHandler
 	MaxRecvMsgSize        int
+	// TLSConfig when set serves gRPC with TLS transport credentials
+	TLSConfig *tls.Config
 }
 
 //NewGRPCHandler creates a new GRPC handler
@@ -29,6 +39,9 @@ type grpcHandler struct {
 	mu          sync.Mutex
 	config      Config
 	middlewares *handlers.MiddlewareMapping
+	// method options are mapped to service/method the same way as middlewares
+	options *handlers.MiddlewareMapping
+	health  *health.Registry
 }
 
 func (g *grpcHandler) init() {
@@ -50,11 +63,20 @@ func (g *grpcHandler) init() {
 		if g.config.MaxRecvMsgSize > 0 {
 			opts = append(opts, grpc.MaxRecvMsgSize(g.config.MaxRecvMsgSize))
 		}
+		if g.config.TLSConfig != nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(g.config.TLSConfig)))
+		}
 		g.grpcServer = grpc.NewServer(opts...)
+		if g.health != nil {
+			healthpb.RegisterHealthServer(g.grpcServer, g.health)
+		}
 	}
 	if g.middlewares == nil {
 		g.middlewares = handlers.NewMiddlewareMapping()
 	}
+	if g.options == nil {
+		g.options = handlers.NewMiddlewareMapping()
+	}
 }
 
 func (g *grpcHandler) Add(sd *grpc.ServiceDesc, ss interface{}) error {
@@ -65,6 +87,17 @@ func (g *grpcHandler) Add(sd *grpc.ServiceDesc, ss interface{}) error {
 	return nil
 }
 
+func (g *grpcHandler) SetHealth(registry *health.Registry) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.health = registry
+	if g.grpcServer != nil && registry != nil {
+		if _, ok := g.grpcServer.GetServiceInfo()[healthpb.Health_ServiceDesc.ServiceName]; !ok {
+			healthpb.RegisterHealthServer(g.grpcServer, registry)
+		}
+	}
+}
+
 func (g *grpcHandler) AddMiddleware(serviceName string, method string, middlewares ...string) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
@@ -72,6 +105,60 @@ func (g *grpcHandler) AddMiddleware(serviceName string, method string, middlewar
 	g.middlewares.AddMiddleware(serviceName, method, middlewares...)
 }
 
+func (g *grpcHandler) AddOption(serviceName, method, option string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.init()
+	g.options.AddMiddleware(serviceName, method, option)
+}
+
+//GetRoutes returns all methods registered on the gRPC server
+func (g *grpcHandler) GetRoutes() []handlers.RouteInfo {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	routes := make([]handlers.RouteInfo, 0)
+	if g.grpcServer == nil {
+		return routes
+	}
+	services := g.grpcServer.GetServiceInfo()
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		methods := append([]grpc.MethodInfo{}, services[name].Methods...)
+		sort.Slice(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+		for _, m := range methods {
+			classifier := make([]string, 0)
+			if m.IsClientStream {
+				classifier = append(classifier, "CLIENT_STREAMING")
+			}
+			if m.IsServerStream {
+				classifier = append(classifier, "SERVER_STREAMING")
+			}
+			if len(classifier) == 0 {
+				classifier = append(classifier, "NON_STREAMING")
+			}
+			route := handlers.RouteInfo{
+				HTTPMethods: []string{"POST"},
+				Path:        "/" + name + "/" + m.Name,
+				Service:     name,
+				Method:      m.Name,
+				Classifier:  classifier,
+			}
+			if g.middlewares != nil {
+				route.Middlewares = g.middlewares.GetMiddlewares(name, m.Name)
+			}
+			if g.options != nil {
+				route.Options = g.options.GetMiddlewares(name, m.Name)
+			}
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
 func (g *grpcHandler) Run(grpcListener net.Listener) error {
 	log.Info(context.Background(), "GRPC", "server starting")
 	grpc_prometheus.Register(g.grpcServer)
@@ -99,6 +186,7 @@ func (g *grpcHandler) Stop(timeout time.Duration) error {
 	g.grpcServer.Stop()
 	g.grpcServer = nil
 	g.middlewares = nil
+	g.options = nil
 	log.Info(context.Background(), "GRPC", "stopped server")
 	return nil
 }
@@ -111,6 +199,13 @@ func (g *grpcHandler) grpcInterceptor() grpc.UnaryServerInterceptor {
 		if g.middlewares != nil {
 			middlewares = append(middlewares, g.middlewares.GetMiddlewaresFromURL(info.FullMethod)...)
 		}
+		if g.options != nil {
+			for _, option := range g.options.GetMiddlewaresFromURL(info.FullMethod) {
+				if timeout, ok := interceptors.ParseTimeoutOption(option); ok {
+					ctx = options.AddToOptions(ctx, interceptors.TimeoutOption, timeout)
+				}
+			}
+		}
 		// fetch interceptors from the service implementation and apply
 		interceptor := handlers.GetInterceptorsWithMethodMiddlewares(info.Server, g.config.CommonConfig, middlewares)
 		return interceptor(ctx, req, info, handler)
@@ -120,6 +215,15 @@ func (g *grpcHandler) grpcInterceptor() grpc.UnaryServerInterceptor {
 // grpcStreamInterceptor acts as default interceptor for gprc streams and applies service specific interceptors based on implementation
 func (g *grpcHandler) grpcStreamInterceptor() grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if g.options != nil {
+			for _, option := range g.options.GetMiddlewaresFromURL(info.FullMethod) {
+				if timeout, ok := interceptors.ParseTimeoutOption(option); ok {
+					wrapped := grpc_middleware.WrapServerStream(ss)
+					wrapped.WrappedContext = options.AddToOptions(ss.Context(), interceptors.TimeoutOption, timeout)
+					ss = wrapped
+				}
+			}
+		}
 		interceptor := handlers.GetStreamInterceptors(srv, g.config.CommonConfig)
 		return interceptor(srv, ss, info, handler)
 	}
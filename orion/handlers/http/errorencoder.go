@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/carousell/Orion/orion/handlers"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+//ErrorStatusMapping overrides the HTTP status code of gRPC codes, codes that are not present are mapped by GrpcErrorToHTTP
+type ErrorStatusMapping map[codes.Code]int
+
+//Status returns the HTTP status code and message of err
+func (m ErrorStatusMapping) Status(err error) (int, string) {
+	code, msg := GrpcErrorToHTTP(err, http.StatusInternalServerError, "Internal Server Error!")
+	if s, ok := status.FromError(err); ok && s != nil {
+		if override, found := m[s.Code()]; found {
+			code = override
+		}
+	}
+	return code, msg
+}
+
+//ParseErrorStatus builds an ErrorStatusMapping from HTTP status codes keyed by gRPC code names, names are case
+//insensitive and may use underscores e.g. "FailedPrecondition" or "failed_precondition". Unknown names and status
+//codes outside 100-599 are left out of the mapping and reported in the returned error
+func ParseErrorStatus(statuses map[string]int) (ErrorStatusMapping, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+	names := make(map[string]codes.Code)
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		names[strings.ToLower(c.String())] = c
+	}
+	mapping := make(ErrorStatusMapping, len(statuses))
+	invalid := make([]string, 0)
+	for name, httpStatus := range statuses {
+		c, ok := names[strings.ToLower(strings.Replace(name, "_", "", -1))]
+		switch {
+		case !ok:
+			invalid = append(invalid, fmt.Sprintf("unknown gRPC code %q", name))
+		case httpStatus < 100 || httpStatus > 599:
+			invalid = append(invalid, fmt.Sprintf("invalid HTTP status %d for %s", httpStatus, name))
+		default:
+			mapping[c] = httpStatus
+		}
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return mapping, errors.New("error status: " + strings.Join(invalid, ", "))
+	}
+	return mapping, nil
+}
+
+//ErrorEnvelope is the JSON error body written when JSON is negotiated, it follows the google api error model
+type ErrorEnvelope struct {
+	Error ErrorBody `json:"error"`
+}
+
+//ErrorBody is the error of an ErrorEnvelope
+type ErrorBody struct {
+	// Code is the HTTP status code of the response
+	Code int `json:"code"`
+	// Status is the gRPC code of the error e.g. "NOT_FOUND"
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message"`
+	// Details are the google.rpc error details of the error as JSON with their "@type"
+	Details []json.RawMessage `json:"details,omitempty"`
+}
+
+//NewErrorEnvelope builds an ErrorEnvelope for err with the given HTTP status and message
+func NewErrorEnvelope(err error, httpStatus int, message string) ErrorEnvelope {
+	env := ErrorEnvelope{
+		Error: ErrorBody{
+			Code:    httpStatus,
+			Message: message,
+		},
+	}
+	s, ok := status.FromError(err)
+	if !ok || s == nil {
+		return env
+	}
+	if s.Code() != codes.Unknown {
+		env.Error.Status = codeName(s.Code())
+	}
+	for _, detail := range s.Proto().GetDetails() {
+		if data, e := protojson.Marshal(detail); e == nil {
+			env.Error.Details = append(env.Error.Details, json.RawMessage(data))
+		}
+	}
+	return env
+}
+
+// codeName returns the google api name of c e.g. "NOT_FOUND"
+func codeName(c codes.Code) string {
+	return code.Code(c).String()
+}
+
+//NewErrorEncoder creates the default ErrorEncoder, status codes are mapped using mapping. Errors are serialized
+//the same way as responses: as a google.rpc.Status proto when protobuf is negotiated and as an ErrorEnvelope when
+//JSON is negotiated or defaultJSONPB is set. Errors with google.rpc details and requests accepting
+//application/problem+json get a Problem, other errors are written as plain text
+func NewErrorEncoder(mapping ErrorStatusMapping, defaultJSONPB bool) handlers.ErrorEncoder {
+	return func(ctx context.Context, w http.ResponseWriter, req *http.Request, err error, headers map[string][]string) {
+		code, msg := mapping.Status(err)
+		setRetryAfter(w, err)
+		writeError(ctx, w, req, code, msg, err, headers, defaultJSONPB)
+	}
+}
+
+//DefaultErrorEncoder is the ErrorEncoder used when none is configured
+var DefaultErrorEncoder = NewErrorEncoder(nil, false)
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/carousell/Orion/orion/modifiers"
+	"github.com/carousell/Orion/utils/errors"
+	"github.com/carousell/Orion/utils/options"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+)
+
+func TestParseErrorStatus(t *testing.T) {
+	mapping, err := ParseErrorStatus(map[string]int{
+		"failedprecondition": http.StatusPreconditionFailed,
+		"CANCELED":           499,
+		"not_found":          http.StatusGone,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ErrorStatusMapping{
+		codes.FailedPrecondition: http.StatusPreconditionFailed,
+		codes.Canceled:           499,
+		codes.NotFound:           http.StatusGone,
+	}, mapping)
+
+	mapping, err = ParseErrorStatus(map[string]int{
+		"NotFound": http.StatusGone,
+		"bogus":    http.StatusTeapot,
+		"Internal": 5000,
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `unknown gRPC code "bogus"`)
+		assert.Contains(t, err.Error(), "invalid HTTP status 5000 for Internal")
+	}
+	assert.Equal(t, ErrorStatusMapping{codes.NotFound: http.StatusGone}, mapping)
+
+	mapping, err = ParseErrorStatus(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, mapping)
+}
+
+func TestErrorStatusMapping(t *testing.T) {
+	mapping := ErrorStatusMapping{codes.FailedPrecondition: http.StatusPreconditionFailed}
+	code, msg := mapping.Status(errors.PreconditionFailed("terms not accepted"))
+	assert.Equal(t, http.StatusPreconditionFailed, code)
+	assert.Equal(t, "terms not accepted", msg)
+	code, _ = mapping.Status(errors.NotFound("missing"))
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestDefaultErrorEncoderEnvelope(t *testing.T) {
+	err := errors.Unavailable("try later", 2*time.Second)
+	req := httptest.NewRequest(http.MethodPost, "/listing/create", nil)
+	req.Header.Set("Accept", ContentTypeJSON)
+	rec := httptest.NewRecorder()
+
+	NewErrorEncoder(ErrorStatusMapping{codes.Unavailable: http.StatusBadGateway}, false)(context.Background(), rec, req, err,
+		map[string][]string{"X-Request-Id": {"1"}})
+
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Equal(t, ContentTypeJSON, rec.Header().Get("Content-Type"))
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	assert.Equal(t, "1", rec.Header().Get("X-Request-Id"))
+	env := struct {
+		Error struct {
+			Code    int
+			Status  string
+			Message string
+			Details []map[string]interface{}
+		}
+	}{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &env))
+	assert.Equal(t, http.StatusBadGateway, env.Error.Code)
+	assert.Equal(t, "UNAVAILABLE", env.Error.Status)
+	assert.Equal(t, "try later", env.Error.Message)
+	if assert.Len(t, env.Error.Details, 1) {
+		assert.Equal(t, "type.googleapis.com/google.rpc.RetryInfo", env.Error.Details[0]["@type"])
+		assert.Equal(t, "2s", env.Error.Details[0]["retryDelay"])
+	}
+}
+
+func TestErrorEncoderNegotiation(t *testing.T) {
+	err := errors.NotFound("missing")
+	encode := func(ctx context.Context, hdrs map[string]string, defaultJSONPB bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/listing/get", nil)
+		for k, v := range hdrs {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		NewErrorEncoder(nil, defaultJSONPB)(ctx, rec, req, err, map[string][]string{"Content-Type": {"text/plain"}})
+		return rec
+	}
+
+	// proto clients sending only a content type get proto errors, like their responses
+	rec := encode(context.Background(), map[string]string{"Content-Type": "application/x-protobuf"}, false)
+	assert.Equal(t, []string{ContentTypeProto}, rec.Header().Values("Content-Type"))
+	st := &spb.Status{}
+	assert.NoError(t, proto.Unmarshal(rec.Body.Bytes(), st))
+	assert.Equal(t, int32(codes.NotFound), st.GetCode())
+
+	rec = encode(context.Background(), map[string]string{"Content-Type": ContentTypeJSON}, false)
+	assert.Equal(t, []string{ContentTypeJSON}, rec.Header().Values("Content-Type"))
+	assert.JSONEq(t, `{"error":{"code":404,"status":"NOT_FOUND","message":"missing"}}`, rec.Body.String())
+
+	// serialization set by the service wins over headers
+	ctx := options.AddToOptions(context.Background(), "", "")
+	modifiers.SerializeOutProtoBuf(ctx)
+	rec = encode(ctx, map[string]string{"Accept": ContentTypeJSON + ", " + ContentTypeProblemJSON}, false)
+	assert.Equal(t, []string{ContentTypeProto}, rec.Header().Values("Content-Type"))
+
+	// DefaultJSONPB negotiates JSON for clients that ask for nothing
+	rec = encode(context.Background(), nil, true)
+	assert.Equal(t, []string{ContentTypeJSON}, rec.Header().Values("Content-Type"))
+
+	// clients that negotiate nothing get the status message as before
+	rec = encode(context.Background(), nil, false)
+	assert.Equal(t, []string{"text/plain"}, rec.Header().Values("Content-Type"))
+	assert.Equal(t, "missing", rec.Body.String())
+}
+
+func TestErrorEncoderPerService(t *testing.T) {
+	custom := func(ctx context.Context, w http.ResponseWriter, req *http.Request, err error, headers map[string][]string) {
+		w.WriteHeader(http.StatusTeapot)
+	}
+	h := &httpHandler{config: Config{ErrorStatus: ErrorStatusMapping{codes.NotFound: http.StatusGone}}}
+	h.AddDefaultErrorEncoder("listing.Listing", custom)
+
+	rec := httptest.NewRecorder()
+	h.errorEncoder("listing.Listing")(context.Background(), rec, httptest.NewRequest(http.MethodGet, "/", nil), errors.NotFound("missing"), nil)
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.errorEncoder("user.User")(context.Background(), rec, httptest.NewRequest(http.MethodGet, "/", nil), errors.NotFound("missing"), nil)
+	assert.Equal(t, http.StatusGone, rec.Code)
+
+	h.config.ErrorEncoder = custom
+	rec = httptest.NewRecorder()
+	h.errorEncoder("user.User")(context.Background(), rec, httptest.NewRequest(http.MethodGet, "/", nil), errors.NotFound("missing"), nil)
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+}
@@ -0,0 +1,631 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/carousell/Orion/orion/handlers"
+	"github.com/carousell/Orion/orion/modifiers"
+	"github.com/carousell/Orion/utils"
+	"github.com/carousell/Orion/utils/errors/notifier"
+	"github.com/carousell/Orion/utils/headers"
+	"github.com/carousell/Orion/utils/log"
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	//ContentTypeGRPCWeb is the content type for gRPC-Web requests
+	ContentTypeGRPCWeb = "application/grpc-web"
+	//ContentTypeGRPCWebText is the content type for base64 encoded gRPC-Web requests
+	ContentTypeGRPCWebText = "application/grpc-web-text"
+	//ContentTypeConnect is the content type prefix for Connect streaming requests
+	ContentTypeConnect = "application/connect"
+	//ConnectProtocolVersionHeader is sent by Connect clients on unary requests
+	ConnectProtocolVersionHeader = "Connect-Protocol-Version"
+)
+
+const (
+	webFlagCompressed = 0x01
+	webFlagEndStream  = 0x02
+	webFlagTrailer    = 0x80
+	webMaxMessageSize = 4 << 20
+)
+
+var (
+	// headers that should never be forwarded to services as metadata
+	webSkipHeaders = map[string]bool{
+		"connection":        true,
+		"content-length":    true,
+		"content-type":      true,
+		"keep-alive":        true,
+		"te":                true,
+		"transfer-encoding": true,
+		"upgrade":           true,
+	}
+)
+
+type webProtocol int
+
+const (
+	protocolGRPCWeb webProtocol = iota
+	protocolConnect
+	protocolConnectUnary
+)
+
+// webCall describes the wire format of a gRPC-Web or Connect request
+type webCall struct {
+	protocol    webProtocol
+	contentType string
+	text        bool
+	json        bool
+}
+
+// parseWebCall identifies gRPC-Web and Connect requests
+func parseWebCall(req *http.Request) (*webCall, bool) {
+	if req.Method != http.MethodPost {
+		return nil, false
+	}
+	contentType := strings.ToLower(strings.TrimSpace(strings.Split(req.Header.Get("Content-Type"), ";")[0]))
+	call := &webCall{contentType: contentType}
+	switch {
+	case strings.HasPrefix(contentType, ContentTypeGRPCWebText):
+		call.protocol = protocolGRPCWeb
+		call.text = true
+		call.json = contentType == ContentTypeGRPCWebText+"+json"
+	case strings.HasPrefix(contentType, ContentTypeGRPCWeb):
+		call.protocol = protocolGRPCWeb
+		call.json = contentType == ContentTypeGRPCWeb+"+json"
+	case strings.HasPrefix(contentType, ContentTypeConnect+"+"):
+		call.protocol = protocolConnect
+		call.json = contentType == ContentTypeConnect+"+json"
+	case req.Header.Get(ConnectProtocolVersionHeader) != "" && (contentType == "application/proto" || contentType == ContentTypeJSON):
+		call.protocol = protocolConnectUnary
+		call.json = contentType == ContentTypeJSON
+	default:
+		return nil, false
+	}
+	return call, true
+}
+
+func isWebRequest(req *http.Request, rm *mux.RouteMatch) bool {
+	_, ok := parseWebCall(req)
+	return ok
+}
+
+// splitWebPath splits /package.Service/Method
+func splitWebPath(path string) (string, string, bool) {
+	path = strings.Trim(path, "/")
+	idx := strings.LastIndex(path, "/")
+	if idx <= 0 || idx == len(path)-1 {
+		return "", "", false
+	}
+	return path[:idx], path[idx+1:], true
+}
+
+func (h *httpHandler) webHandler(resp http.ResponseWriter, req *http.Request) {
+	call, _ := parseWebCall(req)
+	service, method, _ := splitWebPath(req.URL.Path)
+	ctx := utils.StartNRTransaction(service+"/"+method, req.Context(), req, resp)
+	ctx = loggers.AddToLogContext(ctx, "transport", "grpc-web")
+	var err error
+	defer func(t time.Time) {
+		log.Info(ctx, "path", req.URL.Path, "method", req.Method, "error", err, "took", time.Since(t))
+	}(time.Now())
+	req = req.WithContext(ctx)
+	ctx, err = h.serveWeb(resp, req, call, service, method)
+	if modifiers.HasDontLogError(ctx) {
+		utils.FinishNRTransaction(req.Context(), nil)
+	} else {
+		notifier.Notify(err, req.URL.String(), ctx, notifier.Tags{
+			"grpc_code": status.Code(err).String(),
+		})
+		utils.FinishNRTransaction(req.Context(), err)
+	}
+}
+
+func (h *httpHandler) serveWeb(resp http.ResponseWriter, req *http.Request, call *webCall, service, method string) (context.Context, error) {
+	w := &webResponse{
+		resp:   resp,
+		call:   call,
+		method: "/" + service + "/" + method,
+	}
+	info, ok := h.mapping.Get(service, method)
+	if !ok || info.serviceName != service {
+		err := status.Errorf(codes.Unimplemented, "unknown method %s", w.method)
+		w.finishUnary(req.Context(), nil, err)
+		return req.Context(), err
+	}
+	isStream := info.clientStreams || info.serverStreams
+	if isStream && call.protocol == protocolConnectUnary {
+		err := status.Errorf(codes.Unimplemented, "%s is a streaming method", w.method)
+		w.finishUnary(req.Context(), nil, err)
+		return req.Context(), err
+	}
+
+	ctx := prepareContext(req, info)
+	ctx = processOptions(ctx, req, info)
+	ctx = metadata.NewIncomingContext(ctx, metadata.Join(incomingMetadata(ctx), webMetadata(req.Header)))
+	w.allowed = append(info.svc.responseHeaders, DefaultHTTPResponseHeaders...)
+	ctx = grpc.NewContextWithServerTransportStream(ctx, w)
+	if timeout, ok := webTimeout(req.Header); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var body io.Reader = req.Body
+	if call.text {
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+
+	if call.protocol == protocolConnectUnary && req.Header.Get("Content-Encoding") != "" && req.Header.Get("Content-Encoding") != "identity" {
+		err := status.Errorf(codes.Unimplemented, "unsupported content encoding %s", req.Header.Get("Content-Encoding"))
+		w.finishUnary(ctx, nil, err)
+		return ctx, err
+	}
+
+	if isStream {
+		stream := &webServerStream{
+			ctx:  ctx,
+			w:    w,
+			body: body,
+		}
+		streamInfo := &grpc.StreamServerInfo{
+			FullMethod:     w.method,
+			IsClientStream: info.clientStreams,
+			IsServerStream: info.serverStreams,
+		}
+		interceptor := handlers.GetStreamInterceptors(info.svc.svc, h.config.CommonConfig)
+		err := interceptor(info.svc.svc, stream, streamInfo, info.stream)
+		w.finishStream(ctx, err)
+		return ctx, err
+	}
+
+	// decoder func
+	dec := func(r interface{}) error {
+		var data []byte
+		var err error
+		if call.protocol == protocolConnectUnary {
+			data, err = ioutil.ReadAll(io.LimitReader(body, webMaxMessageSize+1))
+			if err == nil && len(data) > webMaxMessageSize {
+				err = status.Errorf(codes.ResourceExhausted, "message larger than %d bytes", webMaxMessageSize)
+			}
+		} else {
+			data, err = readWebFrame(body)
+			if err == io.EOF {
+				// empty request message
+				data, err = nil, nil
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return call.unmarshal(data, r)
+	}
+
+	// fetch all method middlewares
+	middlewares := make([]string, 0)
+	if h.middlewares != nil {
+		middlewares = append(middlewares, h.middlewares.GetMiddlewares(info.serviceName, info.methodName)...)
+	}
+	// fetch all interceptors
+	interceptors := handlers.GetInterceptorsWithMethodMiddlewares(info.svc.svc, h.config.CommonConfig, middlewares)
+
+	// make service call
+	protoResponse, err := info.method(info.svc.svc, ctx, dec, interceptors)
+	var data []byte
+	if err == nil {
+		data, err = call.marshal(protoResponse)
+	}
+	w.finishUnary(ctx, data, err)
+	return ctx, err
+}
+
+func incomingMetadata(ctx context.Context) metadata.MD {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return md
+}
+
+// webMetadata converts request headers to gRPC metadata
+func webMetadata(hdr http.Header) metadata.MD {
+	md := metadata.MD{}
+	for key, values := range hdr {
+		key = strings.ToLower(key)
+		if webSkipHeaders[key] || strings.HasPrefix(key, "grpc-") || strings.HasPrefix(key, "connect-") {
+			continue
+		}
+		if strings.HasSuffix(key, "-bin") {
+			for _, v := range values {
+				if data, err := decodeBinHeader(v); err == nil {
+					md.Append(key, string(data))
+				}
+			}
+			continue
+		}
+		md.Append(key, values...)
+	}
+	return md
+}
+
+func decodeBinHeader(v string) ([]byte, error) {
+	if len(v)%4 == 0 {
+		return base64.StdEncoding.DecodeString(v)
+	}
+	return base64.RawStdEncoding.DecodeString(v)
+}
+
+// webTimeout parses grpc-timeout and Connect-Timeout-Ms headers
+func webTimeout(hdr http.Header) (time.Duration, bool) {
+	if v := hdr.Get("Connect-Timeout-Ms"); v != "" {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err == nil && ms > 0 {
+			return time.Duration(ms) * time.Millisecond, true
+		}
+	}
+	v := hdr.Get("Grpc-Timeout")
+	if len(v) < 2 {
+		return 0, false
+	}
+	value, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+	if err != nil || value <= 0 {
+		return 0, false
+	}
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+	unit, ok := units[v[len(v)-1]]
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(value) * unit, true
+}
+
+func (c *webCall) marshal(m interface{}) ([]byte, error) {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "response is not a proto message")
+	}
+	if c.json {
+		mar := jsonpb.Marshaler{}
+		buf := new(bytes.Buffer)
+		if err := mar.Marshal(buf, msg); err != nil {
+			return nil, status.Errorf(codes.Internal, "could not marshal response: %s", err.Error())
+		}
+		return buf.Bytes(), nil
+	}
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not marshal response: %s", err.Error())
+	}
+	return data, nil
+}
+
+func (c *webCall) unmarshal(data []byte, m interface{}) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "request is not a proto message")
+	}
+	var err error
+	if c.json {
+		if len(data) > 0 {
+			unmar := jsonpb.Unmarshaler{AllowUnknownFields: true}
+			err = unmar.Unmarshal(bytes.NewReader(data), msg)
+		}
+	} else {
+		err = proto.Unmarshal(data, msg)
+	}
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "could not unmarshal request: %s", err.Error())
+	}
+	return nil
+}
+
+// readWebFrame reads a single length prefixed message
+func readWebFrame(r io.Reader) ([]byte, error) {
+	hdr := make([]byte, 5)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, status.Errorf(codes.InvalidArgument, "could not read message: %s", err.Error())
+	}
+	if hdr[0]&webFlagCompressed != 0 {
+		return nil, status.Errorf(codes.Unimplemented, "compressed messages are not supported")
+	}
+	length := binary.BigEndian.Uint32(hdr[1:])
+	if length > webMaxMessageSize {
+		return nil, status.Errorf(codes.ResourceExhausted, "message larger than %d bytes", webMaxMessageSize)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "could not read message: %s", err.Error())
+	}
+	return data, nil
+}
+
+// webResponse writes gRPC-Web and Connect responses, it also acts as the grpc.ServerTransportStream
+// so that grpc.SetHeader/grpc.SetTrailer work as they would on the gRPC handler
+type webResponse struct {
+	mu          sync.Mutex
+	resp        http.ResponseWriter
+	call        *webCall
+	method      string
+	allowed     []string
+	header      metadata.MD
+	trailer     metadata.MD
+	wroteHeader bool
+	text        io.WriteCloser
+}
+
+func (w *webResponse) Method() string {
+	return w.method
+}
+
+func (w *webResponse) SetHeader(md metadata.MD) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.wroteHeader {
+		return status.Errorf(codes.Internal, "headers already sent")
+	}
+	w.header = metadata.Join(w.header, md)
+	return nil
+}
+
+func (w *webResponse) SendHeader(md metadata.MD) error {
+	if err := w.SetHeader(md); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writeHeader(context.Background(), http.StatusOK)
+	return nil
+}
+
+func (w *webResponse) SetTrailer(md metadata.MD) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.trailer = metadata.Join(w.trailer, md)
+	return nil
+}
+
+// writeHeader should be called with mu held
+func (w *webResponse) writeHeader(ctx context.Context, code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	hdr := w.resp.Header()
+	if ctx != nil {
+		for key, values := range processWhitelist(ctx, headers.ResponseHeadersFromContext(ctx), w.allowed) {
+			for _, value := range values {
+				hdr.Add(key, value)
+			}
+		}
+	}
+	for key, values := range w.header {
+		for _, value := range values {
+			hdr.Add(key, encodeWebHeader(key, value))
+		}
+	}
+	hdr.Set("Content-Type", w.call.contentType)
+	w.resp.WriteHeader(code)
+}
+
+func (w *webResponse) write(flags byte, data []byte) error {
+	frame := make([]byte, 5+len(data))
+	frame[0] = flags
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	copy(frame[5:], data)
+	out := io.Writer(w.resp)
+	if w.call.text {
+		// the whole body is a single base64 stream, padding is only written by closeText
+		if w.text == nil {
+			w.text = base64.NewEncoder(base64.StdEncoding, w.resp)
+		}
+		out = w.text
+	}
+	_, err := out.Write(frame)
+	if f, ok := w.resp.(http.Flusher); ok {
+		f.Flush()
+	}
+	return err
+}
+
+// closeText ends the base64 stream of grpc-web-text responses, should be called with mu held
+func (w *webResponse) closeText() {
+	if w.text == nil {
+		return
+	}
+	w.text.Close()
+	w.text = nil
+	if f, ok := w.resp.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *webResponse) sendMessage(ctx context.Context, data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writeHeader(ctx, http.StatusOK)
+	return w.write(0, data)
+}
+
+// finishUnary writes the response for a unary call
+func (w *webResponse) finishUnary(ctx context.Context, data []byte, err error) {
+	if w.call.protocol != protocolConnectUnary {
+		if err == nil {
+			w.sendMessage(ctx, data)
+		}
+		w.finishStream(ctx, err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key, values := range w.trailer {
+		for _, value := range values {
+			w.resp.Header().Add("Trailer-"+key, encodeWebHeader(key, value))
+		}
+	}
+	if err != nil {
+		code, _ := GrpcErrorToHTTP(err, http.StatusInternalServerError, "Internal Server Error!")
+		w.call.contentType = ContentTypeJSON
+		setRetryAfter(w.resp, err)
+		w.writeHeader(ctx, code)
+		data, _ := json.Marshal(connectError(err))
+		w.resp.Write(data)
+		return
+	}
+	w.writeHeader(ctx, http.StatusOK)
+	w.resp.Write(data)
+}
+
+// finishStream writes the trailers for gRPC-Web or the end of stream message for Connect
+func (w *webResponse) finishStream(ctx context.Context, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	defer w.closeText()
+	w.writeHeader(ctx, http.StatusOK)
+	st := status.Convert(err)
+	if w.call.protocol == protocolConnect {
+		end := struct {
+			Error    *webError           `json:"error,omitempty"`
+			Metadata map[string][]string `json:"metadata,omitempty"`
+		}{
+			Metadata: w.trailer,
+		}
+		if err != nil {
+			end.Error = connectError(err)
+		}
+		data, _ := json.Marshal(end)
+		w.write(webFlagEndStream, data)
+		return
+	}
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "grpc-status: %d\r\n", st.Code())
+	if st.Message() != "" {
+		fmt.Fprintf(buf, "grpc-message: %s\r\n", encodeGrpcMessage(st.Message()))
+	}
+	for key, values := range w.trailer {
+		for _, value := range values {
+			fmt.Fprintf(buf, "%s: %s\r\n", strings.ToLower(key), encodeWebHeader(key, value))
+		}
+	}
+	w.write(webFlagTrailer, buf.Bytes())
+}
+
+type webError struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func connectError(err error) *webError {
+	st := status.Convert(err)
+	return &webError{
+		Code:    connectCode(st.Code()),
+		Message: st.Message(),
+	}
+}
+
+// connectCode converts a gRPC code to the Connect representation, e.g. NotFound -> not_found
+func connectCode(code codes.Code) string {
+	name := code.String()
+	out := make([]rune, 0, len(name)+4)
+	prev := ' '
+	for _, r := range name {
+		if unicode.IsUpper(r) && unicode.IsLower(prev) {
+			out = append(out, '_')
+		}
+		prev = r
+		out = append(out, unicode.ToLower(r))
+	}
+	return string(out)
+}
+
+func encodeWebHeader(key, value string) string {
+	if strings.HasSuffix(strings.ToLower(key), "-bin") {
+		return base64.RawStdEncoding.EncodeToString([]byte(value))
+	}
+	return value
+}
+
+// encodeGrpcMessage percent encodes the grpc-message as defined by the gRPC HTTP/2 spec
+func encodeGrpcMessage(msg string) string {
+	buf := new(bytes.Buffer)
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			buf.WriteByte(c)
+		} else {
+			fmt.Fprintf(buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
+
+// webServerStream implements grpc.ServerStream over gRPC-Web and Connect
+type webServerStream struct {
+	ctx  context.Context
+	w    *webResponse
+	body io.Reader
+}
+
+func (s *webServerStream) SetHeader(md metadata.MD) error {
+	return s.w.SetHeader(md)
+}
+
+func (s *webServerStream) SendHeader(md metadata.MD) error {
+	return s.w.SendHeader(md)
+}
+
+func (s *webServerStream) SetTrailer(md metadata.MD) {
+	s.w.SetTrailer(md)
+}
+
+func (s *webServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *webServerStream) SendMsg(m interface{}) error {
+	if err := s.ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	data, err := s.w.call.marshal(m)
+	if err != nil {
+		return err
+	}
+	return s.w.sendMessage(s.ctx, data)
+}
+
+func (s *webServerStream) RecvMsg(m interface{}) error {
+	data, err := readWebFrame(s.body)
+	if err != nil {
+		return err
+	}
+	return s.w.call.unmarshal(data, m)
+}
+
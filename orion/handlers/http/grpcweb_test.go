@@ -0,0 +1,189 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func startWebServer(t *testing.T) (string, func()) {
+	h := NewHTTPHandler(Config{})
+	srv := health.NewServer()
+	srv.SetServingStatus("test", healthpb.HealthCheckResponse_NOT_SERVING)
+	assert.NoError(t, h.Add(&healthpb.Health_ServiceDesc, srv))
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go h.Run(lis)
+	return "http://" + lis.Addr().String(), func() {
+		h.Stop(time.Second)
+	}
+}
+
+func webFrame(flags byte, data []byte) []byte {
+	frame := make([]byte, 5+len(data))
+	frame[0] = flags
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	copy(frame[5:], data)
+	return frame
+}
+
+func readFrames(t *testing.T, data []byte) (messages [][]byte, trailer string) {
+	for len(data) >= 5 {
+		length := binary.BigEndian.Uint32(data[1:5])
+		if !assert.True(t, len(data) >= int(5+length)) {
+			return
+		}
+		payload := data[5 : 5+length]
+		if data[0]&webFlagTrailer != 0 {
+			trailer = string(payload)
+		} else {
+			messages = append(messages, payload)
+		}
+		data = data[5+length:]
+	}
+	return
+}
+
+func post(t *testing.T, url, contentType string, body []byte, hdr map[string]string) (*http.Response, []byte) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range hdr {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return resp, data
+}
+
+func TestGRPCWebUnary(t *testing.T) {
+	addr, stop := startWebServer(t)
+	defer stop()
+
+	req, _ := proto.Marshal(&healthpb.HealthCheckRequest{})
+	resp, data := post(t, addr+"/grpc.health.v1.Health/Check", ContentTypeGRPCWeb+"+proto", webFrame(0, req), nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, ContentTypeGRPCWeb+"+proto", resp.Header.Get("Content-Type"))
+	messages, trailer := readFrames(t, data)
+	if assert.Len(t, messages, 1) {
+		out := &healthpb.HealthCheckResponse{}
+		assert.NoError(t, proto.Unmarshal(messages[0], out))
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, out.GetStatus())
+	}
+	assert.Contains(t, trailer, "grpc-status: 0\r\n")
+
+	// errors are returned in trailers
+	req, _ = proto.Marshal(&healthpb.HealthCheckRequest{Service: "unknown"})
+	_, data = post(t, addr+"/grpc.health.v1.Health/Check", ContentTypeGRPCWeb, webFrame(0, req), nil)
+	messages, trailer = readFrames(t, data)
+	assert.Len(t, messages, 0)
+	assert.Contains(t, trailer, "grpc-status: 5\r\n")
+	assert.Contains(t, trailer, "grpc-message: unknown service\r\n")
+
+	// unknown methods
+	_, data = post(t, addr+"/grpc.health.v1.Health/Missing", ContentTypeGRPCWeb, webFrame(0, req), nil)
+	_, trailer = readFrames(t, data)
+	assert.Contains(t, trailer, "grpc-status: 12\r\n")
+}
+
+func TestGRPCWebText(t *testing.T) {
+	addr, stop := startWebServer(t)
+	defer stop()
+
+	req, _ := proto.Marshal(&healthpb.HealthCheckRequest{Service: "test"})
+	body := base64.StdEncoding.EncodeToString(webFrame(0, req))
+	resp, data := post(t, addr+"/grpc.health.v1.Health/Check", ContentTypeGRPCWebText, []byte(body), nil)
+	assert.Equal(t, ContentTypeGRPCWebText, resp.Header.Get("Content-Type"))
+
+	// the whole body is one base64 stream
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	assert.NoError(t, err)
+	messages, trailer := readFrames(t, decoded)
+	if assert.Len(t, messages, 1) {
+		out := &healthpb.HealthCheckResponse{}
+		assert.NoError(t, proto.Unmarshal(messages[0], out))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, out.GetStatus())
+	}
+	assert.Contains(t, trailer, "grpc-status: 0\r\n")
+}
+
+func TestGRPCWebServerStream(t *testing.T) {
+	addr, stop := startWebServer(t)
+	defer stop()
+
+	req, _ := proto.Marshal(&healthpb.HealthCheckRequest{Service: "test"})
+	_, data := post(t, addr+"/grpc.health.v1.Health/Watch", ContentTypeGRPCWeb, webFrame(0, req), map[string]string{
+		"grpc-timeout": "200m",
+	})
+	messages, trailer := readFrames(t, data)
+	if assert.True(t, len(messages) > 0) {
+		out := &healthpb.HealthCheckResponse{}
+		assert.NoError(t, proto.Unmarshal(messages[0], out))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, out.GetStatus())
+	}
+	assert.Contains(t, trailer, "grpc-status:")
+}
+
+func TestGRPCWebTextServerStream(t *testing.T) {
+	addr, stop := startWebServer(t)
+	defer stop()
+
+	req, _ := proto.Marshal(&healthpb.HealthCheckRequest{Service: "test"})
+	body := base64.StdEncoding.EncodeToString(webFrame(0, req))
+	resp, data := post(t, addr+"/grpc.health.v1.Health/Watch", ContentTypeGRPCWebText, []byte(body), map[string]string{
+		"grpc-timeout": "200m",
+	})
+	assert.Equal(t, ContentTypeGRPCWebText, resp.Header.Get("Content-Type"))
+
+	// padding may only appear at the end of the body
+	assert.NotContains(t, strings.TrimRight(string(data), "="), "=")
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	assert.NoError(t, err)
+	messages, trailer := readFrames(t, decoded)
+	if assert.True(t, len(messages) > 0) {
+		out := &healthpb.HealthCheckResponse{}
+		assert.NoError(t, proto.Unmarshal(messages[0], out))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, out.GetStatus())
+	}
+	assert.Contains(t, trailer, "grpc-status:")
+}
+
+func TestConnectUnary(t *testing.T) {
+	addr, stop := startWebServer(t)
+	defer stop()
+
+	hdr := map[string]string{ConnectProtocolVersionHeader: "1"}
+	resp, data := post(t, addr+"/grpc.health.v1.Health/Check", ContentTypeJSON, []byte(`{"service":"test"}`), hdr)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"status":"NOT_SERVING"}`, string(data))
+
+	resp, data = post(t, addr+"/grpc.health.v1.Health/Check", ContentTypeJSON, []byte(`{"service":"unknown"}`), hdr)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	e := webError{}
+	assert.NoError(t, json.Unmarshal(data, &e))
+	assert.Equal(t, "not_found", e.Code)
+	assert.Equal(t, "unknown service", e.Message)
+}
+
+func TestConnectCode(t *testing.T) {
+	assert.Equal(t, "deadline_exceeded", connectCode(4))
+	assert.Equal(t, "canceled", connectCode(1))
+	assert.Equal(t, "ok", connectCode(0))
+}
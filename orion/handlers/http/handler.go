@@ -10,8 +10,11 @@ import (
 	"time"
 
 	"github.com/carousell/Orion/orion/handlers"
+	"github.com/carousell/Orion/orion/health"
 	"github.com/carousell/Orion/utils/log"
 	"github.com/gorilla/mux"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"google.golang.org/grpc"
 )
 
@@ -136,6 +139,29 @@ func (h *httpHandler) AddDefaultDecoder(serviceName string, decoder handlers.Dec
 	}
 }
 
+func (h *httpHandler) AddDefaultErrorEncoder(serviceName string, encoder handlers.ErrorEncoder) {
+	if h.defErrEncs == nil {
+		h.defErrEncs = make(map[string]handlers.ErrorEncoder)
+	}
+	if encoder != nil {
+		h.defErrEncs[cleanSvcName(serviceName)] = encoder
+	}
+}
+
+// errorEncoder returns the error encoder for serviceName
+func (h *httpHandler) errorEncoder(serviceName string) handlers.ErrorEncoder {
+	if enc, ok := h.defErrEncs[cleanSvcName(serviceName)]; ok {
+		return enc
+	}
+	if h.config.ErrorEncoder != nil {
+		return h.config.ErrorEncoder
+	}
+	if h.config.ErrorStatus != nil || h.config.DefaultJSONPB {
+		return NewErrorEncoder(h.config.ErrorStatus, h.config.DefaultJSONPB)
+	}
+	return DefaultErrorEncoder
+}
+
 func (h *httpHandler) AddOption(serviceName, method, option string) {
 	if info, ok := h.mapping.Get(serviceName, method); ok {
 		if info.options == nil {
@@ -153,9 +179,25 @@ func (h *httpHandler) AddMiddleware(serviceName string, method string, middlewar
 	h.middlewares.AddMiddleware(serviceName, method, middlewares...)
 }
 
+func (h *httpHandler) SetHealth(registry *health.Registry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.health = registry
+}
+
 func (h *httpHandler) Run(httpListener net.Listener) error {
 	r := mux.NewRouter()
+	h.mu.Lock()
+	registry := h.health
+	h.mu.Unlock()
+	if registry != nil {
+		r.Methods(http.MethodGet, http.MethodHead).Path(LivenessPath).HandlerFunc(registry.LivenessHandler())
+		r.Methods(http.MethodGet, http.MethodHead).Path(ReadinessPath).HandlerFunc(registry.ReadinessHandler())
+	}
+	// gRPC-Web and Connect requests are identified by content type and take precedence over mapped URLs
+	r.MatcherFunc(isWebRequest).HandlerFunc(h.webHandler)
 	fmt.Println("Mapped URLs: ")
+	routes := make([]handlers.RouteInfo, 0)
 	allPaths := h.mapping.GetAllMethodInfoByOrder()
 	for i := range allPaths {
 		info := allPaths[i]
@@ -187,23 +229,73 @@ func (h *httpHandler) Run(httpListener net.Listener) error {
 				r.Methods(info.httpMethod...).Path(url + "/").Handler(handler)
 			}
 			fmt.Println("\t", info.httpMethod, routeURL, "mapped to", info.serviceName, info.methodName, methodClassifier)
+			routes = append(routes, h.routeInfo(info, url, methodClassifier))
 		}
 	}
 	r.NotFoundHandler = &notFoundHandler{}
+	h.mu.Lock()
+	h.routes = routes
+	h.mu.Unlock()
 
 	readTimeout := math.Min(300, math.Max(5, float64(h.config.ReadTimeout)))
 	writeTimeout := math.Min(300, math.Max(10, float64(h.config.WriteTimeout)))
-	h.svr = &http.Server{
+	var handler http.Handler = r
+	if h.config.EnableH2C {
+		handler = h2c.NewHandler(r, &http2.Server{})
+	}
+	svr := &http.Server{
 		ReadTimeout:  time.Duration(readTimeout) * time.Second,
 		WriteTimeout: time.Duration(writeTimeout) * time.Second,
-		Handler:      r,
+		Handler:      handler,
+	}
+	h.mu.Lock()
+	h.svr = svr
+	h.mu.Unlock()
+	return svr.Serve(httpListener)
+}
+
+func (h *httpHandler) routeInfo(info *methodInfo, url string, classifier []string) handlers.RouteInfo {
+	route := handlers.RouteInfo{
+		HTTPMethods:   append([]string{}, info.httpMethod...),
+		Path:          url,
+		Service:       info.serviceName,
+		Method:        info.methodName,
+		Classifier:    classifier,
+		Options:       append([]string{}, info.options...),
+		CustomEncoder: info.encoder != nil,
+		CustomDecoder: info.decoder != nil,
+		CustomHandler: info.httpHandler != nil,
+	}
+	if h.middlewares != nil {
+		route.Middlewares = h.middlewares.GetMiddlewares(info.serviceName, info.methodName)
+	}
+	if _, ok := h.defEncoders[cleanSvcName(info.serviceName)]; ok {
+		route.CustomEncoder = true
 	}
-	return h.svr.Serve(httpListener)
+	if _, ok := h.defDecoders[cleanSvcName(info.serviceName)]; ok {
+		route.CustomDecoder = true
+	}
+	if handlerFetcher, ok := info.svc.svc.(handlers.CustomHTTPHandler); ok {
+		if handlerFetcher.GetHTTPHandler(strings.ToLower(info.methodName)) != nil {
+			route.CustomHandler = true
+		}
+	}
+	return route
+}
+
+//GetRoutes returns the routes mapped in the last call to Run
+func (h *httpHandler) GetRoutes() []handlers.RouteInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]handlers.RouteInfo{}, h.routes...)
 }
 
 func (h *httpHandler) Stop(timeout time.Duration) error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	if h.svr == nil {
+		return nil
+	}
 	ctx, can := context.WithTimeout(context.Background(), timeout)
 	defer can()
 	h.svr.Shutdown(ctx)
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/carousell/Orion/orion/handlers"
+	"github.com/carousell/Orion/orion/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func probe(t *testing.T, url string) int {
+	resp, err := http.Get(url)
+	if !assert.NoError(t, err) {
+		return 0
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestHealthProbes(t *testing.T) {
+	registry := health.NewRegistry()
+	h := NewHTTPHandler(Config{})
+	h.(handlers.Healthable).SetHealth(registry)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go h.Run(lis)
+	defer h.Stop(time.Second)
+	base := "http://" + lis.Addr().String()
+
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get(base + LivenessPath)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, http.StatusOK, probe(t, base+LivenessPath))
+	assert.Equal(t, http.StatusOK, probe(t, base+ReadinessPath))
+
+	// server Stop flips readiness before draining connections
+	registry.Shutdown()
+	assert.Equal(t, http.StatusOK, probe(t, base+LivenessPath))
+	assert.Equal(t, http.StatusServiceUnavailable, probe(t, base+ReadinessPath))
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/textproto"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/carousell/Orion/interceptors"
 	"github.com/carousell/Orion/orion/handlers"
 	"github.com/carousell/Orion/orion/modifiers"
 	"github.com/carousell/Orion/utils"
@@ -22,7 +24,10 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/grpc-ecosystem/go-grpc-middleware/util/metautils"
 	opentracing "github.com/opentracing/opentracing-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 )
 
 // grpcMetadataCarrier satisfies both opentracing.TextMapWriter and opentracing.TextMapReader.
@@ -63,15 +68,19 @@ func (h *httpHandler) httpHandler(resp http.ResponseWriter, req *http.Request, s
 		log.Info(ctx, "path", req.URL.Path, "method", req.Method, "error", err, "took", time.Since(t))
 	}(ctx, time.Now())
 	req = req.WithContext(ctx)
-	ctx, err = h.serveHTTP(resp, req, service, method)
+	sw := &statusWriter{ResponseWriter: resp}
+	ctx, err = h.serveHTTP(sw, req, service, method)
 	if modifiers.HasDontLogError(ctx) {
 		utils.FinishNRTransaction(req.Context(), nil)
 	} else {
 
-		// Add HTTP response code as tag
+		// Add HTTP response code as tag, per service error encoders pick their own status
 		var tags notifier.Tags
 		if err != nil {
-			httpCode, _ := GrpcErrorToHTTP(err, http.StatusInternalServerError, "Internal Server Error!")
+			httpCode := sw.status
+			if httpCode == 0 {
+				httpCode, _ = h.config.ErrorStatus.Status(err)
+			}
 			tags = notifier.Tags{
 				"http_code": strconv.Itoa(httpCode),
 			}
@@ -137,6 +146,15 @@ func prepareContext(req *http.Request, info *methodInfo) context.Context {
 		opentracing.GlobalTracer().Inject(wireContext, opentracing.HTTPHeaders, grpcMetadataCarrier(md))
 		ctx = md.ToIncoming(ctx)
 	}
+	// W3C trace context for OpenTelemetry
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(req.Header))
+	// expose the caller address the same way gRPC does
+	if addr, err := net.ResolveTCPAddr("tcp", req.RemoteAddr); err == nil {
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+	}
+	ctx = handlers.WithLogLevelOverride(ctx, req.Header.Get(handlers.LogLevelKey), req.Header.Get(handlers.LogLevelSecretKey))
+	// errors and logs of this request are reported with the request details
+	ctx = notifier.SetHTTPRequestScope(ctx, req)
 	return ctx
 }
 
@@ -146,6 +164,10 @@ func processOptions(ctx context.Context, req *http.Request, info *methodInfo) co
 			switch strings.ToUpper(opt) {
 			case IgnoreNR:
 				utils.IgnoreNRTransaction(ctx)
+			default:
+				if timeout, ok := interceptors.ParseTimeoutOption(opt); ok {
+					ctx = options.AddToOptions(ctx, interceptors.TimeoutOption, timeout)
+				}
 			}
 		}
 	}
@@ -157,6 +179,12 @@ func (h *httpHandler) serveHTTP(resp http.ResponseWriter, req *http.Request, ser
 	if ok {
 		ctx := prepareContext(req, info)
 		ctx = processOptions(ctx, req, info)
+		// client supplied timeout, similar to grpc-timeout for gRPC
+		if timeout, ok := interceptors.ParseTimeout(req.Header.Get(RequestTimeoutHeader)); ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
 		req = req.WithContext(ctx)
 		// httpHandler allows handling entire http request
 		if info.httpHandler != nil {
@@ -216,38 +244,46 @@ func (h *httpHandler) serveHTTP(resp http.ResponseWriter, req *http.Request, ser
 		hdr := headers.ResponseHeadersFromContext(ctx)
 		responseHeaders := processWhitelist(ctx, hdr, append(info.svc.responseHeaders, DefaultHTTPResponseHeaders...))
 		if err != nil {
+			encodeError := h.errorEncoder(info.svc.desc.ServiceName)
 			if encErr != nil {
-				writeRespWithHeaders(resp, http.StatusBadRequest, []byte("Bad Request!"), responseHeaders)
+				encodeError(ctx, resp, req, errors.InvalidArgument("Bad Request!"), responseHeaders)
 				return ctx, errors.Wrap(encErr, "Bad Request")
 			}
-			code, msg := GrpcErrorToHTTP(err, http.StatusInternalServerError, "Internal Server Error!")
-			writeRespWithHeaders(resp, code, []byte(msg), responseHeaders)
+			encodeError(ctx, resp, req, err, responseHeaders)
+			_, msg := GrpcErrorToHTTP(err, http.StatusInternalServerError, "Internal Server Error!")
 			return ctx, errors.Wrap(err, msg)
 		}
 		return ctx, h.serializeOut(ctx, resp, protoResponse.(proto.Message), responseHeaders)
 	}
-	writeResp(resp, http.StatusNotFound, []byte("Not Found: "+req.URL.String()))
-	return req.Context(), errors.New("Not Found: " + req.URL.String())
+	err := errors.NotFound("Not Found: " + req.URL.String())
+	h.errorEncoder("")(req.Context(), resp, req, err, nil)
+	return req.Context(), err
 }
 
-func (h *httpHandler) serialize(ctx context.Context, msg proto.Message) ([]byte, string, error) {
+// negotiateSerialization returns the serialization of responses, it is the one set through modifiers, then the one
+// matching the accept header and then the one matching the original content type. An empty string is returned when
+// none is found and defaultJSONPB is not set
+func negotiateSerialization(ctx context.Context, accept, contentType []string, defaultJSONPB bool) string {
 	// first check if any serialization is
-	serType, _ := modifiers.GetSerialization(ctx)
+	if serType, _ := modifiers.GetSerialization(ctx); serType != "" {
+		return serType
+	}
+	// try and match an accept header
+	serType := serializationType(accept)
 	if serType == "" {
-		// try and match an accept header
-		serType = AcceptTypeFromHeaders(ctx)
-		if serType == "" {
-			// try and match the original content type
-			serType = ContentTypeFromHeaders(ctx)
-			if serType == "" {
-				serType = modifiers.JSON
-			}
-		}
-		// if server preference is JSONPB, JSONPB should be used instead of JSON for marshalling
-		if serType == modifiers.JSON && h.config.DefaultJSONPB {
-			serType = modifiers.JSONPB
-		}
+		// try and match the original content type
+		serType = serializationType(contentType)
+	}
+	// if server preference is JSONPB, JSONPB should be used instead of JSON for marshalling
+	if (serType == "" || serType == modifiers.JSON) && defaultJSONPB {
+		serType = modifiers.JSONPB
 	}
+	return serType
+}
+
+func (h *httpHandler) serialize(ctx context.Context, msg proto.Message) ([]byte, string, error) {
+	hdrs := headers.RequestHeadersFromContext(ctx)
+	serType := negotiateSerialization(ctx, hdrs["Accept"], hdrs["Content-Type"], h.config.DefaultJSONPB)
 	switch serType {
 	case modifiers.JSONPB:
 		sData, err := h.mar.MarshalToString(msg)
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/carousell/Orion/orion/modifiers"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	//ContentTypeProblemJSON is the content type of RFC 7807 error responses
+	ContentTypeProblemJSON = "application/problem+json"
+)
+
+//Problem is an RFC 7807 problem detail, google.rpc error details of the error are rendered as extension members
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Code is the gRPC code of the error e.g. "NotFound"
+	Code string `json:"code,omitempty"`
+	// InvalidParams are the field violations of google.rpc.BadRequest
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+	// Preconditions are the violations of google.rpc.PreconditionFailure
+	Preconditions []Precondition `json:"preconditions,omitempty"`
+	// Reason, Domain and Metadata are taken from google.rpc.ErrorInfo
+	Reason   string            `json:"reason,omitempty"`
+	Domain   string            `json:"domain,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// RetryAfter is the retry delay in seconds of google.rpc.RetryInfo
+	RetryAfter int64 `json:"retry-after,omitempty"`
+	// LocalizedMessage is the message of google.rpc.LocalizedMessage, to be shown to users
+	LocalizedMessage *LocalizedMessage `json:"localized-message,omitempty"`
+}
+
+//InvalidParam is a single invalid request field
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+//Precondition is a single failed precondition
+type Precondition struct {
+	Type        string `json:"type"`
+	Subject     string `json:"subject"`
+	Description string `json:"description"`
+}
+
+//LocalizedMessage is an error message to be shown to users in Locale
+type LocalizedMessage struct {
+	Locale  string `json:"locale"`
+	Message string `json:"message"`
+}
+
+//NewProblem builds a Problem for err with the given HTTP status and detail
+func NewProblem(err error, httpStatus int, detail string, instance string) Problem {
+	p := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(httpStatus),
+		Status:   httpStatus,
+		Detail:   detail,
+		Instance: instance,
+	}
+	s, ok := status.FromError(err)
+	if !ok || s == nil {
+		return p
+	}
+	if s.Code() != codes.Unknown {
+		p.Code = s.Code().String()
+	}
+	for _, d := range s.Details() {
+		switch detail := d.(type) {
+		case *errdetails.BadRequest:
+			for _, v := range detail.GetFieldViolations() {
+				p.InvalidParams = append(p.InvalidParams, InvalidParam{Name: v.GetField(), Reason: v.GetDescription()})
+			}
+		case *errdetails.PreconditionFailure:
+			for _, v := range detail.GetViolations() {
+				p.Preconditions = append(p.Preconditions, Precondition{Type: v.GetType(), Subject: v.GetSubject(), Description: v.GetDescription()})
+			}
+		case *errdetails.ErrorInfo:
+			p.Reason = detail.GetReason()
+			p.Domain = detail.GetDomain()
+			p.Metadata = detail.GetMetadata()
+		case *errdetails.RetryInfo:
+			if detail.GetRetryDelay() != nil {
+				p.RetryAfter = int64(math.Ceil(detail.GetRetryDelay().AsDuration().Seconds()))
+			}
+		case *errdetails.LocalizedMessage:
+			p.LocalizedMessage = &LocalizedMessage{Locale: detail.GetLocale(), Message: detail.GetMessage()}
+		}
+	}
+	return p
+}
+
+// acceptsProblem returns true when the client asks for application/problem+json responses
+func acceptsProblem(req *http.Request) bool {
+	for _, accept := range req.Header.Values("Accept") {
+		for _, value := range strings.Split(accept, ",") {
+			if strings.TrimSpace(strings.SplitN(value, ";", 2)[0]) == ContentTypeProblemJSON {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasDetails returns true when err carries google.rpc error details
+func hasDetails(err error) bool {
+	s, ok := status.FromError(err)
+	return ok && s != nil && len(s.Proto().GetDetails()) > 0
+}
+
+// writeError renders err in the serialization negotiated for responses, see NewErrorEncoder. A serialization set
+// through modifiers overrides application/problem+json, errors with details are rendered as application/problem+json
+// unless protobuf is negotiated or the accept header maps to a serialization in ContentTypeMap
+func writeError(ctx context.Context, resp http.ResponseWriter, req *http.Request, httpStatus int, detail string, err error, headers map[string][]string, defaultJSONPB bool) {
+	accept := req.Header.Values("Accept")
+	serType := negotiateSerialization(ctx, accept, req.Header.Values("Content-Type"), defaultJSONPB)
+	_, forced := modifiers.GetSerialization(ctx)
+	problem := !forced && (acceptsProblem(req) ||
+		(hasDetails(err) && serType != modifiers.ProtoBuf && serializationType(accept) == ""))
+	if !problem && serType == "" {
+		// nothing negotiated, write the status message as is
+		writeRespWithHeaders(resp, httpStatus, []byte(detail), headers)
+		return
+	}
+
+	// the content type is decided by the error encoding
+	filtered := make(map[string][]string, len(headers))
+	for k, v := range headers {
+		if !strings.EqualFold(k, "Content-Type") {
+			filtered[k] = v
+		}
+	}
+	headers = filtered
+	if !problem && serType == modifiers.ProtoBuf {
+		s, ok := status.FromError(err)
+		if !ok || s == nil {
+			s = status.New(codes.Unknown, detail)
+		}
+		st := s.Proto()
+		st.Message = detail
+		if data, e := proto.Marshal(st); e == nil {
+			resp.Header().Set("Content-Type", ContentTypeProto)
+			writeRespWithHeaders(resp, httpStatus, data, headers)
+			return
+		}
+	}
+	contentType := ContentTypeJSON
+	var data []byte
+	var e error
+	if problem {
+		contentType = ContentTypeProblemJSON
+		data, e = json.Marshal(NewProblem(err, httpStatus, detail, req.URL.Path))
+	} else {
+		data, e = json.Marshal(NewErrorEnvelope(err, httpStatus, detail))
+	}
+	if e != nil {
+		writeRespWithHeaders(resp, httpStatus, []byte(detail), headers)
+		return
+	}
+	resp.Header().Set("Content-Type", contentType)
+	writeRespWithHeaders(resp, httpStatus, data, headers)
+}
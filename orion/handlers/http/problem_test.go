@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/carousell/Orion/orion/modifiers"
+	"github.com/carousell/Orion/utils/errors"
+	"github.com/carousell/Orion/utils/options"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWriteErrorProblemJSON(t *testing.T) {
+	err := errors.WithErrorInfo(
+		errors.InvalidArgument("invalid listing", errors.FieldViolation{Field: "price", Description: "must be positive"}),
+		"INVALID_PRICE", "listing.carousell.com", nil)
+	err = errors.WithRetryInfo(err, 1500*time.Millisecond)
+	req := httptest.NewRequest(http.MethodPost, "/listing/create", nil)
+	req.Header.Set("Accept", ContentTypeProblemJSON)
+	rec := httptest.NewRecorder()
+
+	code, msg := GrpcErrorToHTTP(err, http.StatusInternalServerError, "Internal Server Error!")
+	writeError(context.Background(), rec, req, code, msg, err, map[string][]string{"Content-Type": {"text/plain"}, "X-Request-Id": {"1"}}, false)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, []string{ContentTypeProblemJSON}, rec.Header().Values("Content-Type"))
+	assert.Equal(t, "1", rec.Header().Get("X-Request-Id"))
+	p := Problem{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	assert.Equal(t, Problem{
+		Type:          "about:blank",
+		Title:         "Bad Request",
+		Status:        http.StatusBadRequest,
+		Detail:        "invalid listing",
+		Instance:      "/listing/create",
+		Code:          "InvalidArgument",
+		InvalidParams: []InvalidParam{{Name: "price", Reason: "must be positive"}},
+		Reason:        "INVALID_PRICE",
+		Domain:        "listing.carousell.com",
+		RetryAfter:    2,
+	}, p)
+}
+
+func TestWriteErrorDetailsDefaultToProblemJSON(t *testing.T) {
+	err := errors.InvalidArgument("invalid listing", errors.FieldViolation{Field: "price", Description: "must be positive"})
+	write := func(ctx context.Context, hdrs map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/listing/create", nil)
+		for k, v := range hdrs {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		writeError(ctx, rec, req, http.StatusBadRequest, "invalid listing", err, nil, false)
+		return rec
+	}
+
+	// no accept header
+	for _, hdrs := range []map[string]string{nil, {"Content-Type": ContentTypeJSON}} {
+		rec := write(context.Background(), hdrs)
+		assert.Equal(t, ContentTypeProblemJSON, rec.Header().Get("Content-Type"))
+		p := Problem{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+		assert.Equal(t, []InvalidParam{{Name: "price", Reason: "must be positive"}}, p.InvalidParams)
+	}
+
+	// negotiated serializations override it
+	assert.Equal(t, ContentTypeProto, write(context.Background(), map[string]string{"Content-Type": ContentTypeProto}).Header().Get("Content-Type"))
+	assert.Equal(t, ContentTypeJSON, write(context.Background(), map[string]string{"Accept": ContentTypeJSON}).Header().Get("Content-Type"))
+	ctx := options.AddToOptions(context.Background(), "", "")
+	modifiers.SerializeOutJSON(ctx)
+	assert.Equal(t, ContentTypeJSON, write(ctx, nil).Header().Get("Content-Type"))
+
+	// errors without details keep the status message
+	rec := httptest.NewRecorder()
+	writeError(context.Background(), rec, httptest.NewRequest(http.MethodGet, "/missing", nil), http.StatusNotFound, "missing", errors.NotFound("missing"), nil, false)
+	assert.Equal(t, "missing", rec.Body.String())
+}
+
+func TestWriteErrorProto(t *testing.T) {
+	err := errors.PreconditionFailed("terms not accepted", errors.PreconditionViolation{Type: "TOS", Subject: "user:1"})
+	req := httptest.NewRequest(http.MethodPost, "/listing/create", nil)
+	req.Header.Set("Accept", "application/protobuf;q=0.9, application/json")
+	rec := httptest.NewRecorder()
+
+	writeError(context.Background(), rec, req, http.StatusBadRequest, "terms not accepted", err, nil, false)
+
+	assert.Equal(t, ContentTypeProto, rec.Header().Get("Content-Type"))
+	st := &spb.Status{}
+	assert.NoError(t, proto.Unmarshal(rec.Body.Bytes(), st))
+	s := status.FromProto(st)
+	assert.Equal(t, codes.FailedPrecondition, s.Code())
+	if assert.Len(t, s.Details(), 1) {
+		assert.Equal(t, "TOS", s.Details()[0].(*errdetails.PreconditionFailure).GetViolations()[0].GetType())
+	}
+}
+
+func TestWriteErrorPlainError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	req.Header.Set("Accept", "application/json, application/problem+json")
+	rec := httptest.NewRecorder()
+	writeError(context.Background(), rec, req, http.StatusBadRequest, "Bad Request!", json.Unmarshal([]byte("{"), &struct{}{}), nil, false)
+	p := Problem{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	assert.Equal(t, "Bad Request!", p.Detail)
+	assert.Empty(t, p.Code)
+}
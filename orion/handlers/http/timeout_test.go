@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carousell/Orion/orion/handlers"
+	"github.com/carousell/Orion/utils/errors/notifier"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type slowServer interface{}
+
+var slowServiceDesc = grpc.ServiceDesc{
+	ServiceName: "test.Slow",
+	HandlerType: (*slowServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Sleep",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(healthpb.HealthCheckRequest)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					select {
+					case <-ctx.Done():
+						return nil, ctx.Err()
+					case <-time.After(time.Second):
+						return &healthpb.HealthCheckResponse{}, nil
+					}
+				}
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Slow/Sleep"}, handler)
+			},
+		},
+	},
+}
+
+func startSlowServer(t *testing.T, options ...string) (string, func()) {
+	h := NewHTTPHandler(Config{})
+	assert.NoError(t, h.Add(&slowServiceDesc, struct{}{}))
+	for _, option := range options {
+		h.(handlers.Optionable).AddOption("test.Slow", "Sleep", option)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go h.Run(lis)
+	return "http://" + lis.Addr().String() + "/slow/sleep", func() {
+		h.Stop(time.Second)
+	}
+}
+
+func TestRequestTimeoutHeader(t *testing.T) {
+	url, stop := startSlowServer(t)
+	defer stop()
+	start := time.Now()
+	resp, _ := post(t, url, ContentTypeJSON, []byte("{}"), map[string]string{RequestTimeoutHeader: "50ms"})
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestTimeoutOption(t *testing.T) {
+	url, stop := startSlowServer(t, "TIMEOUT=50MS")
+	defer stop()
+	resp, _ := post(t, url, ContentTypeJSON, []byte("{}"), nil)
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+}
+
+type tagRecorder struct {
+	tags []map[string]string
+}
+
+func (r *tagRecorder) Name() string {
+	return "tags"
+}
+
+func (r *tagRecorder) Report(event notifier.Event) error {
+	r.tags = append(r.tags, event.Tags)
+	return nil
+}
+
+func TestHTTPCodeTagUsesServiceErrorEncoder(t *testing.T) {
+	recorder := &tagRecorder{}
+	notifier.RegisterReporter(recorder)
+	defer notifier.UnregisterReporter(recorder.Name())
+
+	h := NewHTTPHandler(Config{}).(*httpHandler)
+	assert.NoError(t, h.Add(&slowServiceDesc, struct{}{}))
+	h.AddDefaultErrorEncoder("test.Slow", func(ctx context.Context, w http.ResponseWriter, req *http.Request, err error, headers map[string][]string) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/slow/sleep", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", ContentTypeJSON)
+	req.Header.Set(RequestTimeoutHeader, "10ms")
+	rec := httptest.NewRecorder()
+	h.httpHandler(rec, req, "test.Slow", "Sleep", "/slow/sleep")
+
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	if assert.Len(t, recorder.tags, 1) {
+		assert.Equal(t, "418", recorder.tags[0]["http_code"])
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/carousell/Orion/orion/handlers"
+	"github.com/carousell/Orion/orion/health"
 	"github.com/carousell/Orion/orion/modifiers"
 	"github.com/golang/protobuf/jsonpb"
 	"google.golang.org/grpc"
@@ -19,6 +20,7 @@ var (
 		"application/protobuf":            modifiers.ProtoBuf,
 		"application/proto":               modifiers.ProtoBuf,
 		"application/x-proto":             modifiers.ProtoBuf,
+		"application/x-protobuf":          modifiers.ProtoBuf,
 		"application/vnd.google.protobuf": modifiers.ProtoBuf,
 		ContentTypeProto:                  modifiers.ProtoBuf,
 	}
@@ -32,6 +34,13 @@ var (
 const (
 	//IgnoreNR is the option flag to ignore newrelic for this method
 	IgnoreNR = "IGNORE_NR"
+	//RequestTimeoutHeader is the request header clients use to set a timeout for HTTP requests,
+	//as a go duration with a unit ("1.5s", "500ms")
+	RequestTimeoutHeader = "X-Request-Timeout"
+	//LivenessPath is the URL liveness probes are served on
+	LivenessPath = "/healthz"
+	//ReadinessPath is the URL readiness probes are served on
+	ReadinessPath = "/readyz"
 )
 
 const (
@@ -53,6 +62,12 @@ type Config struct {
 	NRHttpTxNameType string
 	ReadTimeout      int
 	WriteTimeout     int
+	// EnableH2C serves HTTP/2 without TLS (prior knowledge and upgrade)
+	EnableH2C bool
+	// ErrorEncoder writes error responses of services that have no default error encoder, DefaultErrorEncoder when nil
+	ErrorEncoder handlers.ErrorEncoder
+	// ErrorStatus overrides the HTTP status code of gRPC codes for the default error encoder
+	ErrorStatus ErrorStatusMapping
 }
 
 type serviceInfo struct {
@@ -85,7 +100,10 @@ type httpHandler struct {
 	middlewares *handlers.MiddlewareMapping
 	defEncoders map[string]handlers.Encoder
 	defDecoders map[string]handlers.Decoder
+	defErrEncs  map[string]handlers.ErrorEncoder
 	mar         jsonpb.Marshaler
 	svr         *http.Server
 	config      Config
+	health      *health.Registry
+	routes      []handlers.RouteInfo
 }
@@ -1,10 +1,16 @@
 package http
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/carousell/Orion/interceptors"
 	"github.com/carousell/Orion/utils/headers"
 	"github.com/carousell/Orion/utils/log"
 	"google.golang.org/grpc/codes"
@@ -13,23 +19,21 @@ import (
 
 //ContentTypeFromHeaders searches for a matching content type
 func ContentTypeFromHeaders(ctx context.Context) string {
-	hdrs := headers.RequestHeadersFromContext(ctx)
-	if values, found := hdrs["Content-Type"]; found {
-		for _, v := range values {
-			if t, ok := ContentTypeMap[v]; ok {
-				return t
-			}
-		}
-	}
-	return ""
+	return serializationType(headers.RequestHeadersFromContext(ctx)["Content-Type"])
 }
 
 //AcceptTypeFromHeaders searches for a mathing accept type
 func AcceptTypeFromHeaders(ctx context.Context) string {
-	hdrs := headers.RequestHeadersFromContext(ctx)
-	if values, found := hdrs["Accept"]; found {
-		for _, v := range values {
-			if t, ok := ContentTypeMap[v]; ok {
+	return serializationType(headers.RequestHeadersFromContext(ctx)["Accept"])
+}
+
+// serializationType returns the serialization of the first media type in values found in ContentTypeMap,
+// values can be lists of media types with parameters e.g. "application/protobuf;q=0.9, application/json"
+func serializationType(values []string) string {
+	for _, v := range values {
+		for _, mediaType := range strings.Split(v, ",") {
+			mediaType = strings.TrimSpace(strings.SplitN(mediaType, ";", 2)[0])
+			if t, ok := ContentTypeMap[mediaType]; ok {
 				return t
 			}
 		}
@@ -42,7 +46,11 @@ func AcceptTypeFromHeaders(ctx context.Context) string {
 func GrpcErrorToHTTP(err error, defaultStatus int, defaultMessage string) (int, string) {
 	code := defaultStatus
 	msg := defaultMessage
-	if s, ok := status.FromError(err); ok && s != nil {
+	s, ok := status.FromError(err)
+	if !ok && (errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)) {
+		s, ok = status.FromContextError(err), true
+	}
+	if ok && s != nil {
 		msg = s.Message()
 		switch s.Code() {
 		case codes.NotFound:
@@ -84,6 +92,17 @@ func GrpcErrorToHTTP(err error, defaultStatus int, defaultMessage string) (int,
 	return code, msg
 }
 
+// setRetryAfter sets the Retry-After header for errors that carry a retry delay, e.g. rate limited calls
+func setRetryAfter(resp http.ResponseWriter, err error) {
+	if wait, ok := interceptors.RetryAfterFromError(err); ok {
+		seconds := int64(math.Ceil(wait.Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		resp.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	}
+}
+
 func processWhitelist(ctx context.Context, data map[string][]string, allowedKeys []string) map[string][]string {
 	whitelistedMap := make(map[string][]string)
 	whitelistedKeys := make(map[string]bool)
@@ -137,3 +156,41 @@ func writeRespWithHeaders(resp http.ResponseWriter, status int, data []byte, hea
 	resp.WriteHeader(status)
 	resp.Write(data)
 }
+
+// statusWriter records the status code written by encoders, decoders and custom handlers
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("http: response does not support hijacking")
+}
+
+// Unwrap allows http.ResponseController to reach the wrapped ResponseWriter
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/carousell/Orion/interceptors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSetRetryAfter(t *testing.T) {
+	limiter := interceptors.NewRateLimiter(interceptors.RateLimitRule{Method: "*", Rate: 0.4, Burst: 1})
+	interceptor := interceptors.RateLimitInterceptor(limiter)
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	_, err := interceptor(context.Background(), nil, info, handler)
+	assert.NoError(t, err)
+	_, err = interceptor(context.Background(), nil, info, handler)
+	assert.Error(t, err)
+
+	rec := httptest.NewRecorder()
+	setRetryAfter(rec, err)
+	assert.Equal(t, "3", rec.Header().Get("Retry-After"))
+	code, _ := GrpcErrorToHTTP(err, 500, "")
+	assert.Equal(t, 429, code)
+
+	rec = httptest.NewRecorder()
+	setRetryAfter(rec, status.Error(codes.Internal, "boom"))
+	assert.Empty(t, rec.Header().Get("Retry-After"))
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/carousell/Orion/utils/log"
+	"github.com/carousell/Orion/utils/log/loggers"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	//LogLevelKey is the metadata key (and HTTP header) used to request a log level for a single request
+	LogLevelKey = "x-orion-log-level"
+	//LogLevelSecretKey is the metadata key (and HTTP header) carrying the secret that authorizes a LogLevelKey override
+	LogLevelSecretKey = "x-orion-log-secret"
+)
+
+var (
+	logLevelOverrideMu   sync.RWMutex
+	logLevelOverride     LogLevelOverrideConfig
+	logLevelOverrideNets []*net.IPNet
+)
+
+//LogLevelOverrideConfig controls which callers can change the log level of their requests through LogLevelKey
+type LogLevelOverrideConfig struct {
+	// Enabled allows callers to override the log level, at least one of Secret or AllowedNetworks must also be set
+	Enabled bool
+	// Secret when set must be sent as LogLevelSecretKey along with LogLevelKey
+	Secret string
+	// AllowedNetworks when set restricts overrides to callers from these CIDRs (e.g. "10.0.0.0/8") or IPs
+	AllowedNetworks []string
+}
+
+//SetLogLevelOverride updates the configuration used to authorize per request log level overrides
+func SetLogLevelOverride(config LogLevelOverrideConfig) {
+	nets := make([]*net.IPNet, 0, len(config.AllowedNetworks))
+	for _, network := range config.AllowedNetworks {
+		network = strings.TrimSpace(network)
+		if !strings.Contains(network, "/") {
+			if ip := net.ParseIP(network); ip != nil && ip.To4() != nil {
+				network += "/32"
+			} else {
+				network += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(network)
+		if err != nil {
+			log.Error(context.Background(), "msg", "invalid network in log level override", "network", network, "error", err)
+			continue
+		}
+		nets = append(nets, n)
+	}
+	logLevelOverrideMu.Lock()
+	defer logLevelOverrideMu.Unlock()
+	logLevelOverride = config
+	logLevelOverrideNets = nets
+}
+
+func allowLogLevelOverride(ctx context.Context, secret string) bool {
+	logLevelOverrideMu.RLock()
+	defer logLevelOverrideMu.RUnlock()
+	config := logLevelOverride
+	if !config.Enabled || (config.Secret == "" && len(logLevelOverrideNets) == 0) {
+		return false
+	}
+	if config.Secret != "" && subtle.ConstantTimeCompare([]byte(config.Secret), []byte(secret)) != 1 {
+		return false
+	}
+	if len(logLevelOverrideNets) > 0 {
+		p, ok := peer.FromContext(ctx)
+		if !ok || p.Addr == nil {
+			return false
+		}
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		ip := net.ParseIP(host)
+		for _, n := range logLevelOverrideNets {
+			if ip != nil && n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+//WithLogLevelOverride applies the log level requested by the caller to ctx, when the caller is authorized
+//by the configuration set through SetLogLevelOverride
+func WithLogLevelOverride(ctx context.Context, level, secret string) context.Context {
+	if level == "" {
+		return ctx
+	}
+	lvl, err := loggers.ParseLevel(strings.TrimSpace(level))
+	if err != nil || !allowLogLevelOverride(ctx, secret) {
+		return ctx
+	}
+	ctx = loggers.AddLevelToContext(ctx, lvl)
+	// mark escalated logs so they can be found
+	return loggers.AddToLogContext(ctx, "logLevelOverride", lvl.String())
+}
+
+func logLevelOverrideFromMetadata(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	value := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	return WithLogLevelOverride(ctx, value(LogLevelKey), value(LogLevelSecretKey))
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/carousell/Orion/utils/log"
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+type countingLogger struct {
+	level loggers.Level
+	count int
+}
+
+func (c *countingLogger) Log(ctx context.Context, level loggers.Level, skip int, args ...interface{}) {
+	c.count++
+}
+
+func (c *countingLogger) SetLevel(level loggers.Level) {
+	c.level = level
+}
+
+func (c *countingLogger) GetLevel() loggers.Level {
+	return c.level
+}
+
+func peerContext(ip string) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 5000}})
+}
+
+func TestWithLogLevelOverride(t *testing.T) {
+	defer SetLogLevelOverride(LogLevelOverrideConfig{})
+
+	// disabled by default
+	_, ok := loggers.LevelFromContext(WithLogLevelOverride(context.Background(), "debug", ""))
+	assert.False(t, ok)
+
+	// enabled without a secret or networks is not allowed
+	SetLogLevelOverride(LogLevelOverrideConfig{Enabled: true})
+	_, ok = loggers.LevelFromContext(WithLogLevelOverride(context.Background(), "debug", ""))
+	assert.False(t, ok)
+
+	SetLogLevelOverride(LogLevelOverrideConfig{Enabled: true, Secret: "s3cret"})
+	_, ok = loggers.LevelFromContext(WithLogLevelOverride(context.Background(), "debug", "wrong"))
+	assert.False(t, ok)
+	level, ok := loggers.LevelFromContext(WithLogLevelOverride(context.Background(), "DEBUG", "s3cret"))
+	assert.True(t, ok)
+	assert.Equal(t, loggers.Level(loggers.DebugLevel), level)
+	_, ok = loggers.LevelFromContext(WithLogLevelOverride(context.Background(), "verbose", "s3cret"))
+	assert.False(t, ok)
+
+	SetLogLevelOverride(LogLevelOverrideConfig{Enabled: true, AllowedNetworks: []string{"10.0.0.0/8", "192.168.1.10"}})
+	_, ok = loggers.LevelFromContext(WithLogLevelOverride(peerContext("10.1.2.3"), "debug", ""))
+	assert.True(t, ok)
+	_, ok = loggers.LevelFromContext(WithLogLevelOverride(peerContext("192.168.1.10"), "debug", ""))
+	assert.True(t, ok)
+	_, ok = loggers.LevelFromContext(WithLogLevelOverride(peerContext("172.16.0.1"), "debug", ""))
+	assert.False(t, ok)
+	_, ok = loggers.LevelFromContext(WithLogLevelOverride(context.Background(), "debug", ""))
+	assert.False(t, ok)
+}
+
+func TestOptionsInterceptorLogLevel(t *testing.T) {
+	SetLogLevelOverride(LogLevelOverrideConfig{Enabled: true, Secret: "s3cret"})
+	defer SetLogLevelOverride(LogLevelOverrideConfig{})
+
+	base := &countingLogger{level: loggers.InfoLevel}
+	logger := log.NewLogger(base)
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(LogLevelKey, "debug", LogLevelSecretKey, "s3cret"))
+
+	optionsInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			logger.Debug(ctx, "msg", "visible")
+			logger.Debug(context.Background(), "msg", "filtered")
+			assert.Equal(t, "debug", loggers.FromContext(ctx)["logLevelOverride"])
+			return nil, nil
+		})
+	assert.Equal(t, 1, base.count)
+}
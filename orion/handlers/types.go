@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/carousell/Orion/orion/health"
 	"google.golang.org/grpc"
 )
 
@@ -43,6 +44,10 @@ type Encoder func(req *http.Request, reqObject interface{}) error
 //Decoder is the function type needed for response decoders
 type Decoder func(ctx context.Context, w http.ResponseWriter, encodeError, endpointError error, respObject interface{})
 
+//ErrorEncoder is the function type needed for HTTP error encoders, it writes the status code, headers and body
+//for err, headers are the whitelisted response headers of the request
+type ErrorEncoder func(ctx context.Context, w http.ResponseWriter, req *http.Request, err error, headers map[string][]string)
+
 //Encodeable interface that is implemented by a handler that supports custom HTTP encoder
 type Encodeable interface {
 	AddEncoder(serviceName, method string, httpMethod []string, path string, encoder Encoder)
@@ -55,6 +60,11 @@ type Decodable interface {
 	AddDefaultDecoder(serviceName string, decoder Decoder)
 }
 
+//ErrorEncodable interface that is implemented by a handler that supports custom HTTP error encoders
+type ErrorEncodable interface {
+	AddDefaultErrorEncoder(serviceName string, encoder ErrorEncoder)
+}
+
 //Optionable interface that is implemented by a handler that support custom Orion options
 type Optionable interface {
 	AddOption(ServiceName, method, option string)
@@ -80,6 +90,35 @@ type Middlewareable interface {
 	AddMiddleware(serviceName, method string, middleware ...string)
 }
 
+//Healthable interface that is implemented by a handler that can expose server health
+type Healthable interface {
+	SetHealth(registry *health.Registry)
+}
+
+//RouteInfo describes a single route served by a handler
+type RouteInfo struct {
+	// Handler is the name of the handler serving the route
+	Handler string `json:"handler,omitempty"`
+	// HTTPMethods are the HTTP methods accepted on Path
+	HTTPMethods []string `json:"httpMethods"`
+	Path        string   `json:"path"`
+	// Service is the fully qualified proto service name
+	Service string `json:"service"`
+	Method  string `json:"method"`
+	// Classifier is one or more of NON_STREAMING, CLIENT_STREAMING, SERVER_STREAMING
+	Classifier    []string `json:"classifier"`
+	Options       []string `json:"options,omitempty"`
+	Middlewares   []string `json:"middlewares,omitempty"`
+	CustomEncoder bool     `json:"customEncoder"`
+	CustomDecoder bool     `json:"customDecoder"`
+	CustomHandler bool     `json:"customHandler"`
+}
+
+//RouteReporter interface that is implemented by a handler that can report the routes it is serving
+type RouteReporter interface {
+	GetRoutes() []RouteInfo
+}
+
 //CommonConfig is the config that is common across both http and grpc handlers
 type CommonConfig struct {
 	DisableDefaultInterceptors bool
@@ -195,6 +195,7 @@ func optionsStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.
 	ctx := ss.Context()
 	ctx = options.AddToOptions(ctx, "", "")
 	ctx = loggers.AddToLogContext(ctx, "grpcMethod", info.FullMethod)
+	ctx = logLevelOverrideFromMetadata(ctx)
 	newServer := &streamServer{
 		ServerStream: ss,
 		ctx:          ctx,
@@ -205,6 +206,10 @@ func optionsStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.
 func optionsInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 	ctx = options.AddToOptions(ctx, "", "")
 	ctx = loggers.AddToLogContext(ctx, "grpcMethod", info.FullMethod)
+	if _, ok := loggers.LevelFromContext(ctx); !ok {
+		// HTTP requests are handled by the HTTP handler
+		ctx = logLevelOverrideFromMetadata(ctx)
+	}
 	if !modifiers.IsHTTPRequest(ctx) {
 		loggers.AddToLogContext(ctx, "transport", "gRPC")
 		options.AddToOptions(ctx, modifiers.RequestGRPC, true)
@@ -0,0 +1,268 @@
+/*
+Package health provides the health registry used by orion server, it implements the standard gRPC health
+protocol (grpc.health.v1.Health) and HTTP liveness/readiness probes.
+
+Services can contribute named checks by implementing the Checker interface, these checks are evaluated
+on every readiness probe. Liveness only reflects whether the server process is serving.
+*/
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// LivenessPath is the HTTP path that serves liveness probes
+	LivenessPath = "/healthz"
+	// ReadinessPath is the HTTP path that serves readiness probes
+	ReadinessPath = "/readyz"
+)
+
+var (
+	// DefaultCheckTimeout is the maximum time a single check is allowed to take
+	DefaultCheckTimeout = time.Second * 2
+	// DefaultWatchInterval is the interval at which health is reevaluated for Watch streams
+	DefaultWatchInterval = time.Second * 5
+)
+
+//Check is the function that reports health of a dependency, a non nil error marks the check as failing
+type Check func(ctx context.Context) error
+
+//Checker interface when implemented by a service allows that service to provide named health checks
+type Checker interface {
+	// GetHealthChecks returns a map of check name to Check
+	GetHealthChecks() map[string]Check
+}
+
+//Report is the result of evaluating health
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+//Registry stores health checks and serving status for an orion server
+type Registry struct {
+	healthpb.UnimplementedHealthServer
+	mu       sync.RWMutex
+	checks   map[string]Check
+	services map[string]bool
+	draining bool
+	changed  chan struct{}
+}
+
+//NewRegistry creates a new health Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		checks:   make(map[string]Check),
+		services: make(map[string]bool),
+		changed:  make(chan struct{}),
+	}
+}
+
+//AddCheck adds (or replaces) a named health check
+func (r *Registry) AddCheck(name string, check Check) {
+	if name == "" || check == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check
+}
+
+//RemoveCheck removes a named health check
+func (r *Registry) RemoveCheck(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.checks, name)
+}
+
+//AddService marks a grpc service as known to the health registry
+func (r *Registry) AddService(serviceName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.services[serviceName] = true
+}
+
+//Shutdown marks the registry as draining, all readiness probes report NOT_SERVING after this call
+func (r *Registry) Shutdown() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.draining {
+		r.draining = true
+		close(r.changed)
+	}
+}
+
+//IsDraining returns true if Shutdown has been called
+func (r *Registry) IsDraining() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.draining
+}
+
+// Live returns the liveness report, liveness does not evaluate any checks
+func (r *Registry) Live(ctx context.Context) Report {
+	return Report{
+		Status: healthpb.HealthCheckResponse_SERVING.String(),
+	}
+}
+
+// Ready evaluates all registered checks and returns the readiness report
+func (r *Registry) Ready(ctx context.Context) Report {
+	r.mu.RLock()
+	draining := r.draining
+	checks := make(map[string]Check, len(r.checks))
+	for name, c := range r.checks {
+		checks[name] = c
+	}
+	r.mu.RUnlock()
+
+	report := Report{
+		Status: healthpb.HealthCheckResponse_SERVING.String(),
+		Checks: make(map[string]string),
+	}
+	if draining {
+		report.Status = healthpb.HealthCheckResponse_NOT_SERVING.String()
+	}
+
+	for name, c := range checks {
+		if err := runCheck(ctx, c); err != nil {
+			report.Checks[name] = err.Error()
+			report.Status = healthpb.HealthCheckResponse_NOT_SERVING.String()
+		} else {
+			report.Checks[name] = "ok"
+		}
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, c Check) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultCheckTimeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = status.Errorf(codes.Internal, "panic in health check: %v", r)
+		}
+	}()
+	return c(ctx)
+}
+
+func (r *Registry) servingStatus(ctx context.Context, service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	if service != "" {
+		r.mu.RLock()
+		_, isService := r.services[service]
+		check, isCheck := r.checks[service]
+		draining := r.draining
+		r.mu.RUnlock()
+		switch {
+		case isCheck:
+			if draining || runCheck(ctx, check) != nil {
+				return healthpb.HealthCheckResponse_NOT_SERVING, nil
+			}
+			return healthpb.HealthCheckResponse_SERVING, nil
+		case !isService:
+			return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, status.Error(codes.NotFound, "unknown service")
+		}
+	}
+	if r.Ready(ctx).Status != healthpb.HealthCheckResponse_SERVING.String() {
+		return healthpb.HealthCheckResponse_NOT_SERVING, nil
+	}
+	return healthpb.HealthCheckResponse_SERVING, nil
+}
+
+// Check implements grpc.health.v1.Health/Check
+func (r *Registry) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	st, err := r.servingStatus(ctx, req.GetService())
+	if err != nil {
+		return nil, err
+	}
+	return &healthpb.HealthCheckResponse{Status: st}, nil
+}
+
+// List implements grpc.health.v1.Health/List
+func (r *Registry) List(ctx context.Context, req *healthpb.HealthListRequest) (*healthpb.HealthListResponse, error) {
+	r.mu.RLock()
+	names := []string{""}
+	for name := range r.services {
+		names = append(names, name)
+	}
+	for name := range r.checks {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+	sort.Strings(names)
+
+	resp := &healthpb.HealthListResponse{
+		Statuses: make(map[string]*healthpb.HealthCheckResponse),
+	}
+	for _, name := range names {
+		st, _ := r.servingStatus(ctx, name)
+		resp.Statuses[name] = &healthpb.HealthCheckResponse{Status: st}
+	}
+	return resp, nil
+}
+
+// Watch implements grpc.health.v1.Health/Watch, health is reevaluated every DefaultWatchInterval
+// and immediately when the registry starts draining
+func (r *Registry) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ctx := stream.Context()
+	r.mu.RLock()
+	changed := r.changed
+	r.mu.RUnlock()
+
+	ticker := time.NewTicker(DefaultWatchInterval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_ServingStatus(-1)
+	for {
+		st, _ := r.servingStatus(ctx, req.GetService())
+		if st != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last = st
+		}
+		select {
+		case <-ctx.Done():
+			return status.Error(codes.Canceled, "stream has ended")
+		case <-changed:
+			// draining started, evaluate immediately and stop waiting on this channel
+			changed = nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// LivenessHandler returns a http.HandlerFunc that serves liveness probes
+func (r *Registry) LivenessHandler() http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		writeReport(resp, r.Live(req.Context()))
+	}
+}
+
+// ReadinessHandler returns a http.HandlerFunc that serves readiness probes
+func (r *Registry) ReadinessHandler() http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		writeReport(resp, r.Ready(req.Context()))
+	}
+}
+
+func writeReport(resp http.ResponseWriter, report Report) {
+	code := http.StatusOK
+	if report.Status != healthpb.HealthCheckResponse_SERVING.String() {
+		code = http.StatusServiceUnavailable
+	}
+	data, _ := json.Marshal(report)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(code)
+	resp.Write(data)
+}
@@ -0,0 +1,76 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestReadiness(t *testing.T) {
+	r := NewRegistry()
+	r.AddService("test.Service")
+	failing := errors.New("db down")
+	var dbErr error
+	r.AddCheck("db", func(ctx context.Context) error {
+		return dbErr
+	})
+
+	ctx := context.Background()
+	report := r.Ready(ctx)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING.String(), report.Status)
+	assert.Equal(t, "ok", report.Checks["db"])
+
+	dbErr = failing
+	report = r.Ready(ctx)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING.String(), report.Status)
+	assert.Equal(t, failing.Error(), report.Checks["db"])
+
+	resp, err := r.Check(ctx, &healthpb.HealthCheckRequest{Service: "db"})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+
+	_, err = r.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestShutdown(t *testing.T) {
+	r := NewRegistry()
+	r.AddService("test.Service")
+
+	ctx := context.Background()
+	resp, err := r.Check(ctx, &healthpb.HealthCheckRequest{Service: "test.Service"})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	r.Shutdown()
+	r.Shutdown() // should be safe to call multiple times
+
+	resp, err = r.Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+
+	// liveness is not affected by draining
+	rec := httptest.NewRecorder()
+	r.LivenessHandler()(rec, httptest.NewRequest("GET", LivenessPath, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	r.ReadinessHandler()(rec, httptest.NewRequest("GET", ReadinessPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestPanickingCheck(t *testing.T) {
+	r := NewRegistry()
+	r.AddCheck("panic", func(ctx context.Context) error {
+		panic("boom")
+	})
+	report := r.Ready(context.Background())
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING.String(), report.Status)
+}
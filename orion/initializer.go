@@ -8,17 +8,20 @@ import (
 	_ "net/http/pprof" // import pprof
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/carousell/Orion/utils/hystrixprometheus"
 	"github.com/prometheus/client_golang/prometheus"
 
-	"github.com/afex/hystrix-go/hystrix"
-	metricCollector "github.com/afex/hystrix-go/hystrix/metric_collector"
-	"github.com/afex/hystrix-go/plugins"
+	"github.com/carousell/Orion/interceptors"
+	"github.com/carousell/Orion/orion/handlers"
 	"github.com/carousell/Orion/utils"
+	"github.com/carousell/Orion/utils/breaker"
 	"github.com/carousell/Orion/utils/errors/notifier"
 	"github.com/carousell/Orion/utils/log"
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/carousell/Orion/utils/log/loggers/sampler"
 	logg "github.com/go-kit/kit/log"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	newrelic "github.com/newrelic/go-agent"
@@ -30,12 +33,22 @@ import (
 var (
 	//DefaultInitializers are the initializers applied by orion as default
 	DefaultInitializers = []Initializer{
+		ScrubInitializer(),
 		HystrixInitializer(),
 		ZipkinInitializer(),
+		OpenTelemetryInitializer(),
 		NewRelicInitializer(),
 		PrometheusInitializer(),
 		PprofInitializer(),
 		ErrorLoggingInitializer(),
+		IntrospectionInitializer(),
+		RateLimitInitializer(),
+		ConcurrencyLimitInitializer(),
+		TimeoutInitializer(),
+		AccessLogInitializer(),
+		LogLevelOverrideInitializer(),
+		AdminInitializer(),
+		LogSamplingInitializer(),
 	}
 )
 
@@ -69,28 +82,58 @@ func PprofInitializer() Initializer {
 	return &pprofInitializer{}
 }
 
+//RateLimitInitializer returns a Initializer implementation for server side rate limits
+func RateLimitInitializer() Initializer {
+	return &rateLimitInitializer{}
+}
+
+//ConcurrencyLimitInitializer returns a Initializer implementation for adaptive server side load shedding
+func ConcurrencyLimitInitializer() Initializer {
+	return &concurrencyLimitInitializer{}
+}
+
+//TimeoutInitializer returns a Initializer implementation for configured method timeouts
+func TimeoutInitializer() Initializer {
+	return &timeoutInitializer{}
+}
+
+//AccessLogInitializer returns a Initializer implementation for access logs
+func AccessLogInitializer() Initializer {
+	return &accessLogInitializer{}
+}
+
+//LogLevelOverrideInitializer returns a Initializer implementation for per request log level overrides
+func LogLevelOverrideInitializer() Initializer {
+	return &logLevelOverrideInitializer{}
+}
+
+//LogSamplingInitializer returns a Initializer implementation that samples repeated logs of the global logger
+func LogSamplingInitializer() Initializer {
+	return &logSamplingInitializer{}
+}
+
+//ScrubInitializer returns a Initializer implementation that masks sensitive data in logs and error reports
+func ScrubInitializer() Initializer {
+	return &scrubInitializer{}
+}
+
 type hystrixInitializer struct {
 }
 
 func (h *hystrixInitializer) Init(svr Server) error {
 	config := svr.GetOrionConfig()
-
-	hystrix.DefaultTimeout = config.HystrixConfig.DefaultTimeout
-	hystrix.DefaultMaxConcurrent = config.HystrixConfig.DefaultMaxConcurrent
-	hystrix.DefaultErrorPercentThreshold = config.HystrixConfig.DefaultErrorPercentThreshold
-	hystrix.DefaultSleepWindow = config.HystrixConfig.DefaultSleepWindow
-	hystrix.DefaultVolumeThreshold = config.HystrixConfig.DefaultVolumeThreshold
+	configureBreakers(config.HystrixConfig)
 
 	if strings.TrimSpace(config.HystrixConfig.StatsdAddr) != "" {
 		name := config.OrionServerName + ".hystrix"
 		name = strings.Replace(name, "-", "_", 10)
 
-		c, err := plugins.InitializeStatsdCollector(&plugins.StatsdCollectorConfig{
-			StatsdAddr: config.HystrixConfig.StatsdAddr,
-			Prefix:     name,
+		c, err := breaker.NewStatsdCollector(breaker.StatsdConfig{
+			Addr:   config.HystrixConfig.StatsdAddr,
+			Prefix: name,
 		})
 		if err == nil {
-			metricCollector.Registry.Register(c.NewStatsdCollector)
+			breaker.RegisterCollector(c.Collector)
 			log.Info(context.Background(), "HystrixStatsd", config.HystrixConfig.StatsdAddr)
 		} else {
 			log.Info(context.Background(), "Hystrix", err.Error())
@@ -99,9 +142,9 @@ func (h *hystrixInitializer) Init(svr Server) error {
 	}
 
 	promC := hystrixprometheus.NewPrometheusCollector("hystrix", nil, prometheus.DefBuckets)
-	metricCollector.Registry.Register(promC.Collector)
+	breaker.RegisterCollector(promC.Collector)
 
-	hystrixStreamHandler := hystrix.NewStreamHandler()
+	hystrixStreamHandler := breaker.NewStreamHandler()
 	hystrixStreamHandler.Start()
 	port := config.HystrixConfig.Port
 	log.Info(context.Background(), "HystrixPort", port)
@@ -110,10 +153,25 @@ func (h *hystrixInitializer) Init(svr Server) error {
 }
 
 func (h *hystrixInitializer) ReInit(svr Server) error {
-	// do nothing, cant be reinited
+	// collectors and the stream handler cant be reinited
+	configureBreakers(svr.GetOrionConfig().HystrixConfig)
 	return nil
 }
 
+func configureBreakers(config HystrixConfig) {
+	breaker.SetDefaults(breaker.CommandConfig{
+		Timeout:                config.DefaultTimeout,
+		MaxConcurrentRequests:  config.DefaultMaxConcurrent,
+		RequestVolumeThreshold: config.DefaultVolumeThreshold,
+		SleepWindow:            config.DefaultSleepWindow,
+		ErrorPercentThreshold:  config.DefaultErrorPercentThreshold,
+	})
+	breaker.Configure(config.CommandConfig)
+	if len(config.CommandConfig) > 0 {
+		log.Info(context.Background(), "HystrixCommandConfig", config.CommandConfig)
+	}
+}
+
 type newRelicInitializer struct {
 }
 
@@ -161,6 +219,12 @@ func (z *zipkinInitializer) Init(svr Server) error {
 
 	zipkinAddr := svr.GetOrionConfig().ZipkinConfig.Addr
 	serviceName := svr.GetOrionConfig().OrionServerName
+	if svr.GetOrionConfig().OpenTelemetryConfig.Enabled {
+		// OpenTelemetry replaces zipkin
+		log.Info(context.Background(), "zipkin", "disabled, OpenTelemetry is enabled")
+		stdopentracing.SetGlobalTracer(stdopentracing.NoopTracer{})
+		return nil
+	}
 	if zipkinAddr != "" {
 		logger := logg.NewJSONLogger(os.Stdout)
 		logger = logg.With(logger, "time", logg.DefaultTimestampUTC)
@@ -252,18 +316,33 @@ func (e *errorLoggingInitializer) Init(svr Server) error {
 	// environment for error notification
 	notifier.SetEnvironemnt(env)
 
+	// dedup and per reporter severity thresholds
+	dedup := svr.GetOrionConfig().NotifierDedup
+	notifier.SetDedup(dedup)
+	if dedup.Enabled {
+		log.Info(context.Background(), "NotifierDedup", dedup)
+	}
+	minSeverity := svr.GetOrionConfig().NotifierMinSeverity
+	reporterOptions := func(name string) []notifier.ReporterOption {
+		if level, ok := minSeverity[name]; ok {
+			return []notifier.ReporterOption{notifier.WithMinSeverity(level)}
+		}
+		return nil
+	}
+
 	// rollbar
 	rToken := svr.GetOrionConfig().RollbarToken
 	if strings.TrimSpace(rToken) != "" {
-		notifier.InitRollbar(rToken, env)
-		log.Debug(context.Background(), "rollbarToken", rToken, "env", env)
+		notifier.InitRollbar(rToken, env, reporterOptions(notifier.RollbarReporter)...)
+		log.Debug(context.Background(), "reporter", notifier.RollbarReporter, "env", env)
 	}
 
 	//sentry
 	sToken := svr.GetOrionConfig().SentryDSN
 	if strings.TrimSpace(sToken) != "" {
-		notifier.InitSentry(sToken)
-		log.Debug(context.Background(), "sentryDSN", rToken, "env", env)
+		notifier.SetRelease(svr.GetOrionConfig().SentryRelease)
+		notifier.InitSentry(sToken, reporterOptions(notifier.SentryReporter)...)
+		log.Debug(context.Background(), "reporter", notifier.SentryReporter, "env", env)
 	}
 	return nil
 }
@@ -271,3 +350,164 @@ func (e *errorLoggingInitializer) Init(svr Server) error {
 func (e *errorLoggingInitializer) ReInit(svr Server) error {
 	return e.Init(svr)
 }
+
+type rateLimitInitializer struct{}
+
+func (r *rateLimitInitializer) Init(svr Server) error {
+	rules := svr.GetOrionConfig().RateLimits
+	interceptors.SetRateLimits(rules...)
+	if len(rules) > 0 {
+		log.Info(context.Background(), "RateLimits", rules)
+	}
+	return nil
+}
+
+func (r *rateLimitInitializer) ReInit(svr Server) error {
+	return r.Init(svr)
+}
+
+type concurrencyLimitInitializer struct{}
+
+func (c *concurrencyLimitInitializer) Init(svr Server) error {
+	config := svr.GetOrionConfig().ConcurrencyLimit
+	interceptors.SetConcurrencyLimit(config)
+	if config.Enabled {
+		log.Info(context.Background(), "ConcurrencyLimit", config)
+	}
+	return nil
+}
+
+func (c *concurrencyLimitInitializer) ReInit(svr Server) error {
+	return c.Init(svr)
+}
+
+type timeoutInitializer struct{}
+
+func (t *timeoutInitializer) Init(svr Server) error {
+	rules := svr.GetOrionConfig().Timeouts
+	interceptors.SetTimeouts(rules...)
+	if len(rules) > 0 {
+		log.Info(context.Background(), "Timeouts", rules)
+	}
+	return nil
+}
+
+func (t *timeoutInitializer) ReInit(svr Server) error {
+	return t.Init(svr)
+}
+
+type accessLogInitializer struct{}
+
+func (a *accessLogInitializer) Init(svr Server) error {
+	config := svr.GetOrionConfig().AccessLog
+	interceptors.SetAccessLog(config)
+	if config.Enabled {
+		log.Info(context.Background(), "AccessLog", config)
+	}
+	return nil
+}
+
+func (a *accessLogInitializer) ReInit(svr Server) error {
+	return a.Init(svr)
+}
+
+type logLevelOverrideInitializer struct{}
+
+func (l *logLevelOverrideInitializer) Init(svr Server) error {
+	config := svr.GetOrionConfig().LogLevelOverride
+	handlers.SetLogLevelOverride(config)
+	if config.Enabled {
+		log.Info(context.Background(), "LogLevelOverride", config.Enabled, "AllowedNetworks", config.AllowedNetworks)
+	}
+	return nil
+}
+
+func (l *logLevelOverrideInitializer) ReInit(svr Server) error {
+	return l.Init(svr)
+}
+
+var (
+	samplingMu     sync.Mutex
+	samplingLogger *sampler.Logger
+)
+
+type logSamplingInitializer struct{}
+
+// globalLogger writes to the logger set globally when the log is made
+type globalLogger struct{}
+
+func (globalLogger) Log(ctx context.Context, level loggers.Level, skip int, args ...interface{}) {
+	log.GetLogger().Log(ctx, level, skip+1, args...)
+}
+
+func (globalLogger) SetLevel(level loggers.Level) {
+	log.SetLevel(level)
+}
+
+func (globalLogger) GetLevel() loggers.Level {
+	return log.GetLevel()
+}
+
+func setLogSampling(config sampler.Config) {
+	samplingMu.Lock()
+	defer samplingMu.Unlock()
+	if samplingLogger != nil {
+		samplingLogger.Update(config)
+		return
+	}
+	if !config.Enabled {
+		return
+	}
+	// summaries are written to the global logger
+	samplingLogger = sampler.NewLogger(globalLogger{}, config)
+	log.SetHook("sampling", samplingLogger.Sample)
+}
+
+func (l *logSamplingInitializer) Init(svr Server) error {
+	config := svr.GetOrionConfig().LogSampling
+	setLogSampling(config)
+	if config.Enabled {
+		log.Info(context.Background(), "LogSampling", config)
+	}
+	return nil
+}
+
+func (l *logSamplingInitializer) ReInit(svr Server) error {
+	return l.Init(svr)
+}
+
+//Stop writes a summary of logs suppressed since the last one
+func (l *logSamplingInitializer) Stop(timeout time.Duration) error {
+	samplingMu.Lock()
+	defer samplingMu.Unlock()
+	if samplingLogger != nil {
+		samplingLogger.Stop()
+	}
+	return nil
+}
+
+type scrubInitializer struct{}
+
+func setScrub(config loggers.ScrubConfig) error {
+	s, err := loggers.NewScrubber(config)
+	if err != nil {
+		return err
+	}
+	loggers.SetScrubber(s)
+	return nil
+}
+
+func (s *scrubInitializer) Init(svr Server) error {
+	config := svr.GetOrionConfig().Scrub
+	if err := setScrub(config); err != nil {
+		return err
+	}
+	if !config.Enabled {
+		log.Warn(context.Background(), "Scrub", "scrubbing of logs and error reports is disabled")
+	}
+	return nil
+}
+
+func (s *scrubInitializer) ReInit(svr Server) error {
+	return setScrub(svr.GetOrionConfig().Scrub)
+}
@@ -0,0 +1,52 @@
+package orion
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/carousell/Orion/utils/log"
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/carousell/Orion/utils/log/loggers/sampler"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingLogger struct {
+	mu   sync.Mutex
+	msgs []interface{}
+}
+
+func (c *countingLogger) Log(ctx context.Context, level loggers.Level, skip int, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.msgs = append(c.msgs, args[1])
+}
+
+func (c *countingLogger) SetLevel(level loggers.Level) {}
+
+func (c *countingLogger) GetLevel() loggers.Level {
+	return loggers.DebugLevel
+}
+
+func TestLogSamplingSurvivesSetLogger(t *testing.T) {
+	previous := log.GetLogger()
+	defer func() {
+		log.SetHook("sampling", nil)
+		samplingMu.Lock()
+		samplingLogger = nil
+		samplingMu.Unlock()
+		log.SetLogger(previous)
+	}()
+
+	setLogSampling(sampler.Config{Enabled: true, First: 1})
+	base := &countingLogger{}
+	log.SetLogger(log.NewLogger(base))
+	for i := 0; i < 3; i++ {
+		log.Info(context.Background(), "msg", "repeated")
+	}
+	assert.Equal(t, []interface{}{"repeated"}, base.msgs)
+
+	assert.NoError(t, (&logSamplingInitializer{}).Stop(time.Second))
+	assert.Equal(t, []interface{}{"repeated", "logs suppressed by sampling"}, base.msgs)
+}
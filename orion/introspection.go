@@ -0,0 +1,115 @@
+package orion
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/carousell/Orion/orion/handlers"
+	"github.com/carousell/Orion/utils/openapi"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	//RoutesPath is the path on the pprof port that serves the live route table
+	RoutesPath = "/orion/routes"
+	//OpenAPIPath is the path on the pprof port that serves the OpenAPI document of the live routes
+	OpenAPIPath = "/orion/openapi.json"
+)
+
+var (
+	introspectionOnce   sync.Once
+	introspectionMu     sync.RWMutex
+	introspectionServer Server
+)
+
+//RouteLister is implemented by servers that can list the routes currently being served
+type RouteLister interface {
+	GetRoutes() []handlers.RouteInfo
+}
+
+//IntrospectionInitializer returns a Initializer implementation that serves the live route table
+//and an OpenAPI document on the pprof port
+func IntrospectionInitializer() Initializer {
+	return &introspectionInitializer{}
+}
+
+type introspectionInitializer struct{}
+
+func (i *introspectionInitializer) Init(svr Server) error {
+	if _, ok := svr.(RouteLister); !ok {
+		return nil
+	}
+	introspectionMu.Lock()
+	introspectionServer = svr
+	introspectionMu.Unlock()
+	// http.DefaultServeMux panics when a path is registered twice
+	introspectionOnce.Do(func() {
+		http.HandleFunc(RoutesPath, routesHandler)
+		http.HandleFunc(OpenAPIPath, openAPIHandler)
+	})
+	return nil
+}
+
+func (i *introspectionInitializer) ReInit(svr Server) error {
+	// routes and config are always fetched live, nothing to do here
+	return nil
+}
+
+func currentServer() Server {
+	introspectionMu.RLock()
+	defer introspectionMu.RUnlock()
+	return introspectionServer
+}
+
+func currentRoutes(svr Server) []handlers.RouteInfo {
+	if svr == nil {
+		return []handlers.RouteInfo{}
+	}
+	return svr.(RouteLister).GetRoutes()
+}
+
+func writeJSON(resp http.ResponseWriter, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Write(data)
+}
+
+func routesHandler(resp http.ResponseWriter, req *http.Request) {
+	writeJSON(resp, currentRoutes(currentServer()))
+}
+
+func openAPIHandler(resp http.ResponseWriter, req *http.Request) {
+	svr := currentServer()
+	config := Config{}
+	if svr != nil {
+		config = svr.GetOrionConfig()
+	}
+	writeJSON(resp, BuildOpenAPI(config.OrionServerName, currentRoutes(svr), config.DefaultJSONPB))
+}
+
+//BuildOpenAPI builds an OpenAPI 3 document for all non streaming routes served by the HTTP handler,
+//request and response schemas are resolved from the registered proto descriptors
+func BuildOpenAPI(title string, routes []handlers.RouteInfo, jsonpb bool) *openapi.Document {
+	doc := openapi.NewDocument(title, "")
+	for _, route := range routes {
+		if route.Handler != "" && route.Handler != "http" {
+			continue
+		}
+		if len(route.Classifier) != 1 || route.Classifier[0] != "NON_STREAMING" {
+			// streams are served over websockets, they can not be described by OpenAPI
+			continue
+		}
+		doc.Add(openapi.Route{
+			Service:     route.Service,
+			Method:      route.Method,
+			HTTPMethods: route.HTTPMethods,
+			Path:        route.Path,
+		}, protoregistry.GlobalFiles, openapi.Options{JSONPB: jsonpb})
+	}
+	return doc
+}
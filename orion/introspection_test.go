@@ -0,0 +1,126 @@
+package orion
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/carousell/Orion/orion/handlers"
+	httpHandler "github.com/carousell/Orion/orion/handlers/http"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHTTPRoutes(t *testing.T) {
+	h := httpHandler.NewHTTPHandler(httpHandler.Config{})
+	assert.NoError(t, h.Add(&healthpb.Health_ServiceDesc, health.NewServer()))
+	h.(handlers.Encodeable).AddEncoder("grpc.health.v1.Health", "Check", []string{"GET"}, "/check/{service}", nil)
+	h.(handlers.Middlewareable).AddMiddleware("grpc.health.v1.Health", "Check", "Auth")
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go h.Run(lis)
+	defer h.Stop(time.Second)
+
+	reporter := h.(handlers.RouteReporter)
+	var routes []handlers.RouteInfo
+	for i := 0; i < 100 && len(routes) == 0; i++ {
+		time.Sleep(time.Millisecond * 10)
+		routes = reporter.GetRoutes()
+	}
+	if assert.Len(t, routes, 3) {
+		check := routes[0]
+		assert.Equal(t, "/check/{service}", check.Path)
+		assert.Equal(t, []string{"GET"}, check.HTTPMethods)
+		assert.Equal(t, []string{"Auth"}, check.Middlewares)
+		assert.Equal(t, []string{"NON_STREAMING"}, check.Classifier)
+		assert.Equal(t, []string{"SERVER_STREAMING"}, routes[2].Classifier)
+	}
+}
+
+func TestBuildOpenAPI(t *testing.T) {
+	routes := []handlers.RouteInfo{
+		{
+			Handler:     "http",
+			HTTPMethods: []string{"GET"},
+			Path:        "/check/{service:[a-z]+}",
+			Service:     "grpc.health.v1.Health",
+			Method:      "Check",
+			Classifier:  []string{"NON_STREAMING"},
+		},
+		{
+			Handler:     "http",
+			HTTPMethods: []string{"POST"},
+			Path:        "/health/list",
+			Service:     "grpc.health.v1.Health",
+			Method:      "List",
+			Classifier:  []string{"NON_STREAMING"},
+		},
+		{
+			Handler:     "http",
+			HTTPMethods: []string{"GET"},
+			Path:        "/health/watch",
+			Service:     "grpc.health.v1.Health",
+			Method:      "Watch",
+			Classifier:  []string{"SERVER_STREAMING"},
+		},
+		{
+			Handler:     "grpc",
+			HTTPMethods: []string{"POST"},
+			Path:        "/grpc.health.v1.Health/Check",
+			Service:     "grpc.health.v1.Health",
+			Method:      "Check",
+			Classifier:  []string{"NON_STREAMING"},
+		},
+	}
+	data, err := json.Marshal(BuildOpenAPI("test", routes, false))
+	assert.NoError(t, err)
+	doc := struct {
+		Paths      map[string]map[string]map[string]interface{}
+		Components struct {
+			Schemas map[string]map[string]interface{}
+		}
+	}{}
+	assert.NoError(t, json.Unmarshal(data, &doc))
+
+	assert.Len(t, doc.Paths, 2)
+	get := doc.Paths["/check/{service}"]["get"]
+	if assert.NotNil(t, get) {
+		assert.Nil(t, get["requestBody"])
+		params := get["parameters"].([]interface{})
+		assert.Equal(t, "service", params[0].(map[string]interface{})["name"])
+	}
+	assert.NotNil(t, doc.Paths["/health/list"]["post"]["requestBody"])
+
+	resp := doc.Components.Schemas["grpc.health.v1.HealthCheckResponse"]
+	if assert.NotNil(t, resp) {
+		status := resp["properties"].(map[string]interface{})["status"].(map[string]interface{})
+		assert.Equal(t, "integer", status["type"])
+	}
+	// map values reference nested messages
+	assert.NotNil(t, doc.Components.Schemas["grpc.health.v1.HealthListResponse"])
+}
+
+func TestRoutesHandler(t *testing.T) {
+	svr := GetDefaultServerWithConfig(Config{GRPCOnly: true, HTTPOnly: true})
+	assert.NoError(t, IntrospectionInitializer().Init(svr))
+
+	rec := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(rec, httptest.NewRequest("GET", RoutesPath, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "[]", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(rec, httptest.NewRequest("GET", OpenAPIPath, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"openapi": "3.0.3"`)
+
+	// the document follows config reloads
+	svr.(*DefaultServerImpl).config.OrionServerName = "reloaded"
+	rec = httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(rec, httptest.NewRequest("GET", OpenAPIPath, nil))
+	assert.Contains(t, rec.Body.String(), `"title": "reloaded"`)
+}
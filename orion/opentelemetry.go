@@ -0,0 +1,198 @@
+package orion
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carousell/Orion/utils/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	//OTLPProtocolGRPC exports telemetry using OTLP over gRPC
+	OTLPProtocolGRPC = "grpc"
+	//OTLPProtocolHTTP exports telemetry using OTLP over HTTP
+	OTLPProtocolHTTP = "http"
+	// otelReloadShutdownTimeout is how long providers replaced on reload get to export what they buffered
+	otelReloadShutdownTimeout = 30 * time.Second
+)
+
+//OpenTelemetryInitializer returns a Initializer implementation for OpenTelemetry tracing and metrics
+func OpenTelemetryInitializer() Initializer {
+	return &openTelemetryInitializer{}
+}
+
+type openTelemetryInitializer struct {
+	mu             sync.Mutex
+	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
+}
+
+func (o *openTelemetryInitializer) Init(svr Server) error {
+	config := svr.GetOrionConfig().OpenTelemetryConfig
+	if !config.Enabled {
+		o.mu.Lock()
+		running := o.tracerProvider != nil
+		o.mu.Unlock()
+		if running {
+			// disabled on reload
+			otel.SetTracerProvider(tracenoop.NewTracerProvider())
+			otel.SetMeterProvider(metricnoop.NewMeterProvider())
+			o.replace(nil, nil)
+		}
+		return nil
+	}
+	ctx := context.Background()
+	serviceName := config.ServiceName
+	if strings.TrimSpace(serviceName) == "" {
+		serviceName = svr.GetOrionConfig().OrionServerName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", serviceName),
+		attribute.String("deployment.environment", svr.GetOrionConfig().Env),
+	))
+	if err != nil {
+		return err
+	}
+
+	traceExporter, err := newOTLPTraceExporter(ctx, config)
+	if err != nil {
+		log.Error(ctx, "OpenTelemetry", "could not create trace exporter", "error", err)
+		return err
+	}
+	ratio := config.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	var meterProvider *sdkmetric.MeterProvider
+	if !config.DisableMetrics {
+		metricExporter, err := newOTLPMetricExporter(ctx, config)
+		if err != nil {
+			log.Error(ctx, "OpenTelemetry", "could not create metric exporter", "error", err)
+			traceExporter.Shutdown(ctx)
+			return err
+		}
+		meterProvider = sdkmetric.NewMeterProvider(
+			sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)),
+			sdkmetric.WithResource(res),
+		)
+		otel.SetMeterProvider(meterProvider)
+	} else {
+		otel.SetMeterProvider(metricnoop.NewMeterProvider())
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	o.replace(tracerProvider, meterProvider)
+	log.Info(ctx, "OpenTelemetry", "initialized", "protocol", config.Protocol, "endpoint", config.Endpoint, "service", serviceName)
+	return nil
+}
+
+func (o *openTelemetryInitializer) ReInit(svr Server) error {
+	return o.Init(svr)
+}
+
+//Stop flushes and shuts down the providers, spans and metrics that are not exported within timeout are dropped
+func (o *openTelemetryInitializer) Stop(timeout time.Duration) error {
+	o.mu.Lock()
+	tracerProvider, meterProvider := o.tracerProvider, o.meterProvider
+	o.tracerProvider, o.meterProvider = nil, nil
+	o.mu.Unlock()
+	return shutdownProviders(tracerProvider, meterProvider, timeout)
+}
+
+// replace sets the current providers, the previous ones are shut down in the background
+// since the global providers no longer point to them
+func (o *openTelemetryInitializer) replace(tracerProvider *sdktrace.TracerProvider, meterProvider *sdkmetric.MeterProvider) {
+	o.mu.Lock()
+	oldTracer, oldMeter := o.tracerProvider, o.meterProvider
+	o.tracerProvider, o.meterProvider = tracerProvider, meterProvider
+	o.mu.Unlock()
+	if oldTracer != nil || oldMeter != nil {
+		go shutdownProviders(oldTracer, oldMeter, otelReloadShutdownTimeout)
+	}
+}
+
+func shutdownProviders(tracerProvider *sdktrace.TracerProvider, meterProvider *sdkmetric.MeterProvider, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var err error
+	if tracerProvider != nil {
+		err = tracerProvider.Shutdown(ctx)
+	}
+	if meterProvider != nil {
+		if merr := meterProvider.Shutdown(ctx); merr != nil && err == nil {
+			err = merr
+		}
+	}
+	if err != nil {
+		log.Warn(ctx, "OpenTelemetry", "could not flush telemetry", "error", err)
+	}
+	return err
+}
+
+func newOTLPTraceExporter(ctx context.Context, config OpenTelemetryConfig) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(config.Protocol) {
+	case "", OTLPProtocolGRPC:
+		opts := []otlptracegrpc.Option{}
+		if config.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case OTLPProtocolHTTP:
+		opts := []otlptracehttp.Option{}
+		if config.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	}
+	return nil, errors.New("unknown OTLP protocol " + config.Protocol)
+}
+
+func newOTLPMetricExporter(ctx context.Context, config OpenTelemetryConfig) (sdkmetric.Exporter, error) {
+	switch strings.ToLower(config.Protocol) {
+	case "", OTLPProtocolGRPC:
+		opts := []otlpmetricgrpc.Option{}
+		if config.Endpoint != "" {
+			opts = append(opts, otlpmetricgrpc.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	case OTLPProtocolHTTP:
+		opts := []otlpmetrichttp.Option{}
+		if config.Endpoint != "" {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	}
+	return nil, errors.New("unknown OTLP protocol " + config.Protocol)
+}
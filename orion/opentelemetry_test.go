@@ -0,0 +1,65 @@
+package orion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestOpenTelemetryInitializer(t *testing.T) {
+	defer func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	}()
+
+	// disabled by default
+	in := &openTelemetryInitializer{}
+	assert.NoError(t, in.Init(GetDefaultServerWithConfig(Config{})))
+	assert.Nil(t, in.tracerProvider)
+
+	in = &openTelemetryInitializer{}
+	svr := GetDefaultServerWithConfig(Config{
+		OrionServerName: "test",
+		OpenTelemetryConfig: OpenTelemetryConfig{
+			Enabled:  true,
+			Protocol: OTLPProtocolHTTP,
+			Endpoint: "127.0.0.1:4318",
+			Insecure: true,
+		},
+	})
+	assert.NoError(t, in.Init(svr))
+	if assert.NotNil(t, in.tracerProvider) && assert.NotNil(t, in.meterProvider) {
+		assert.Equal(t, in.tracerProvider, otel.GetTracerProvider())
+		assert.Contains(t, otel.GetTextMapPropagator().Fields(), "traceparent")
+	}
+
+	// providers replaced on reload are shut down
+	old := in.tracerProvider
+	assert.NoError(t, in.ReInit(svr))
+	// compare pointers, deep equality races with the shutdown of old
+	assert.True(t, old != in.tracerProvider)
+	assert.True(t, otel.GetTracerProvider() == in.tracerProvider)
+	assert.Eventually(t, func() bool { return !isRecording(old) }, 5*time.Second, 10*time.Millisecond)
+
+	current := in.tracerProvider
+	assert.True(t, isRecording(current))
+	in.Stop(time.Second)
+	assert.False(t, isRecording(current))
+	assert.Nil(t, in.tracerProvider)
+	assert.Nil(t, in.meterProvider)
+
+	svr = GetDefaultServerWithConfig(Config{OpenTelemetryConfig: OpenTelemetryConfig{Enabled: true, Protocol: "thrift"}})
+	assert.Error(t, (&openTelemetryInitializer{}).Init(svr))
+}
+
+func isRecording(tp *sdktrace.TracerProvider) bool {
+	_, span := tp.Tracer("test").Start(context.Background(), "test")
+	defer span.End()
+	return span.IsRecording()
+}
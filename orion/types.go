@@ -45,6 +45,11 @@ type Initializer interface {
 	ReInit(svr Server) error
 }
 
+//StoppableInitializer is implemented by initializers that have to flush or release resources when the server stops
+type StoppableInitializer interface {
+	Stop(timeout time.Duration) error
+}
+
 // ServiceFactory is the interface that need to be implemented by client that provides with a new service object
 type ServiceFactory interface {
 	// NewService function receives the server object for which service has to be initialized
@@ -77,5 +82,8 @@ type Encoder = handlers.Encoder
 //Decoder is the function type needed for request decoders
 type Decoder = handlers.Decoder
 
+//ErrorEncoder is the function type needed for HTTP error encoders
+type ErrorEncoder = handlers.ErrorEncoder
+
 //HTTPHandler is the http interceptor
 type HTTPHandler = handlers.HTTPHandler
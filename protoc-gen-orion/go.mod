@@ -1,7 +1,12 @@
 module github.com/carousell/Orion/protoc-gen-orion
 
-go 1.17
+go 1.25.3
 
-require github.com/golang/protobuf v1.5.2
+require (
+	github.com/carousell/Orion v0.0.0-00010101000000-000000000000
+	github.com/golang/protobuf v1.5.4
+	google.golang.org/protobuf v1.36.8
+)
 
-require google.golang.org/protobuf v1.26.0 // indirect
+// the openapi package is shared with orion introspection
+replace github.com/carousell/Orion => ../
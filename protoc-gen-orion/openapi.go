@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/carousell/Orion/utils/openapi"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// cleanComment drops ORION annotations from a comment
+func cleanComment(comment string) string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(comment, "\n") {
+		if parseComments(line) != nil || strings.HasPrefix(strings.ToUpper(strings.TrimSpace(line)), ORION+DELIM) {
+			continue
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
+
+// descriptorComment returns the cleaned leading comment of a message or field
+func descriptorComment(d protoreflect.Descriptor) string {
+	return cleanComment(d.ParentFile().SourceLocations().ByDescriptor(d).LeadingComments)
+}
+
+// defaultURL mirrors the URL generated by orion http handler for a method
+func defaultURL(fullServiceName, method string) string {
+	serviceName := strings.ToLower(fullServiceName)
+	parts := strings.Split(serviceName, ".")
+	if len(parts) > 1 {
+		serviceName = parts[1]
+	}
+	return "/" + serviceName + "/" + strings.ToLower(method)
+}
+
+func generateOpenAPI(file *descriptor.FileDescriptorProto, allFiles []*descriptor.FileDescriptorProto) *plugin.CodeGeneratorResponse_File {
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: allFiles})
+	if err != nil {
+		logError(err, "failed to resolve descriptors for openapi spec")
+	}
+	doc := buildOpenAPI(file, files)
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		logError(err, "failed to marshal openapi spec")
+	}
+	f := new(plugin.CodeGeneratorResponse_File)
+	f.Content = proto.String(string(data) + "\n")
+	f.Name = proto.String(file.GetName() + ".openapi.json")
+	return f
+}
+
+func buildOpenAPI(file *descriptor.FileDescriptorProto, files *protoregistry.Files) *openapi.Document {
+	doc := openapi.NewDocument(file.GetName(), "Generated by protoc-gen-orion from "+file.GetName())
+	// encoding/json uses the json tag generated by protoc-gen-go, which is the proto field name
+	opts := openapi.Options{Comment: descriptorComment}
+
+	comments := extractComments(file)
+	for index, svc := range file.GetService() {
+		fullServiceName := svc.GetName()
+		if file.GetPackage() != "" {
+			fullServiceName = file.GetPackage() + "." + svc.GetName()
+		}
+		for i, method := range svc.GetMethod() {
+			if method.GetClientStreaming() || method.GetServerStreaming() {
+				// streams are served over websockets, they can not be described by OpenAPI
+				continue
+			}
+			route := openapi.Route{
+				Service:     fullServiceName,
+				Method:      method.GetName(),
+				HTTPMethods: []string{"POST"},
+				Path:        defaultURL(fullServiceName, method.GetName()),
+			}
+			loc := comments[fmt.Sprintf("6,%d,2,%d", index, i)] // 6 means service, 2 means method in a service.
+			if loc != nil {
+				route.Summary = cleanComment(loc.GetLeadingComments())
+				for _, line := range strings.Split(strings.TrimSuffix(loc.GetLeadingComments(), "\n"), "\n") {
+					if option := parseComments(line); option != nil && option.Encoder {
+						if option.Method != "" {
+							route.HTTPMethods = strings.Split(option.Method, "/")
+						}
+						if strings.TrimSpace(option.Path) != "" {
+							route.Path = strings.TrimSpace(option.Path)
+						}
+					}
+				}
+			}
+			doc.Add(route, files, opts)
+		}
+	}
+	return doc
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/carousell/Orion/utils/openapi"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+func testFile() *descriptor.FileDescriptorProto {
+	field := func(name string, number int32, typ descriptor.FieldDescriptorProto_Type, typeName string) *descriptor.FieldDescriptorProto {
+		f := &descriptor.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Type:   typ.Enum(),
+			Label:  descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	tags := field("tags", 3, descriptor.FieldDescriptorProto_TYPE_STRING, "")
+	tags.Label = descriptor.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return &descriptor.FileDescriptorProto{
+		Name:    proto.String("example/example.proto"),
+		Package: proto.String("example"),
+		MessageType: []*descriptor.DescriptorProto{
+			{
+				Name: proto.String("GetRequest"),
+				Field: []*descriptor.FieldDescriptorProto{
+					field("user_id", 1, descriptor.FieldDescriptorProto_TYPE_INT64, ""),
+					field("item", 2, descriptor.FieldDescriptorProto_TYPE_MESSAGE, ".example.Item"),
+					tags,
+				},
+			},
+			{
+				Name: proto.String("Item"),
+				Field: []*descriptor.FieldDescriptorProto{
+					field("name", 1, descriptor.FieldDescriptorProto_TYPE_STRING, ""),
+				},
+			},
+		},
+		Service: []*descriptor.ServiceDescriptorProto{
+			{
+				Name: proto.String("ExampleService"),
+				Method: []*descriptor.MethodDescriptorProto{
+					{Name: proto.String("NormalGet"), InputType: proto.String(".example.GetRequest"), OutputType: proto.String(".example.Item")},
+					{Name: proto.String("HttpGet"), InputType: proto.String(".example.GetRequest"), OutputType: proto.String(".example.Item")},
+					{Name: proto.String("StreamGet"), InputType: proto.String(".example.GetRequest"), OutputType: proto.String(".example.Item"), ServerStreaming: proto.Bool(true)},
+				},
+			},
+		},
+		SourceCodeInfo: &descriptor.SourceCodeInfo{
+			Location: []*descriptor.SourceCodeInfo_Location{
+				{Path: []int32{6, 0, 2, 1}, Span: []int32{20, 2, 60}, LeadingComments: proto.String(" HTTP endpoint\n ORION:URL: GET/POST /users/{userid:[0-9]+}\n")},
+			},
+		},
+	}
+}
+
+func TestGenerateOpenAPI(t *testing.T) {
+	file := testFile()
+	out := generateOpenAPI(file, []*descriptor.FileDescriptorProto{file})
+	if out.GetName() != "example/example.proto.openapi.json" {
+		t.Fatalf("unexpected file name %s", out.GetName())
+	}
+	doc := openapi.Document{}
+	if err := json.Unmarshal([]byte(out.GetContent()), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := doc.Paths["/exampleservice/normalget"]["post"]; !ok {
+		t.Errorf("default orion url not found in %v", doc.Paths)
+	}
+	get, ok := doc.Paths["/users/{userid}"]["get"]
+	if !ok {
+		t.Fatalf("annotated url not found in %v", doc.Paths)
+	}
+	if get.RequestBody != nil {
+		t.Errorf("GET should not have a request body")
+	}
+	if get.Summary != "HTTP endpoint" {
+		t.Errorf("unexpected summary %q", get.Summary)
+	}
+	if len(get.Parameters) != 1 || get.Parameters[0].Name != "userid" || get.Parameters[0].Schema.Format != "int64" {
+		t.Errorf("unexpected path parameters %+v", get.Parameters)
+	}
+	if post := doc.Paths["/users/{userid}"]["post"]; post == nil || post.RequestBody == nil {
+		t.Errorf("POST should have a request body")
+	}
+	for path := range doc.Paths {
+		if path == "/exampleservice/streamget" {
+			t.Errorf("streams should not be listed")
+		}
+	}
+
+	req := doc.Components.Schemas["example.GetRequest"]
+	if req == nil {
+		t.Fatalf("request schema missing")
+	}
+	if req.Properties["item"].Ref != "#/components/schemas/example.Item" {
+		t.Errorf("unexpected ref %q", req.Properties["item"].Ref)
+	}
+	if req.Properties["tags"].Type != "array" || req.Properties["tags"].Items.Type != "string" {
+		t.Errorf("unexpected repeated schema %+v", req.Properties["tags"])
+	}
+	if _, ok := doc.Components.Schemas["example.Item"]; !ok {
+		t.Errorf("referenced schema missing")
+	}
+}
+
+func TestParseOpenAPIParam(t *testing.T) {
+	pp, err := parseProtocParams("exported-service-desc=true,openapi=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pp.OpenAPI || !pp.ExportedServiceDesc {
+		t.Errorf("unexpected params %+v", pp)
+	}
+	if _, err := parseProtocParams("openapi=yes"); err == nil {
+		t.Errorf("expected error for bad value")
+	}
+}
@@ -100,6 +100,7 @@ type orionMiddleware struct {
 type ProtocParams struct {
 	StandaloneMode      bool
 	ExportedServiceDesc bool
+	OpenAPI             bool
 }
 
 var tmpl = `// Code generated by protoc-gen-orion. DO NOT EDIT.
@@ -222,6 +223,9 @@ func main() {
 			if len(file.Service) > 0 {
 				f := generateFile(populate(file, reqParams))
 				response.File = append(response.File, f)
+				if reqParams.OpenAPI {
+					response.File = append(response.File, generateOpenAPI(file, request.GetProtoFile()))
+				}
 			}
 		}
 	}
@@ -468,6 +472,12 @@ func parseProtocParams(reqParam string) (ProtocParams, error) {
 				return ProtocParams{}, fmt.Errorf(`bad value for parameter %q: %w`, param, err)
 			}
 			pp.ExportedServiceDesc = boolValue
+		case "openapi":
+			boolValue, err := parseBoolValue(value)
+			if err != nil {
+				return ProtocParams{}, fmt.Errorf(`bad value for parameter %q: %w`, param, err)
+			}
+			pp.OpenAPI = boolValue
 		}
 	}
 	return pp, nil
@@ -0,0 +1,252 @@
+/*
+Package breaker provides circuit breakers for commands, it follows the concepts of hystrix, commands have a timeout,
+a max concurrency and a circuit that opens once the error percent of the last 10 seconds crosses a threshold after a
+minimum volume of requests. An open circuit rejects requests for a sleep window, after which a single probe request is
+allowed (half open), the circuit is closed when the probe succeeds and opened again when it fails.
+*/
+package breaker
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	EventSuccess                 = "success"
+	EventFailure                 = "failure"
+	EventTimeout                 = "timeout"
+	EventRejected                = "rejected"
+	EventShortCircuit            = "short-circuit"
+	EventFallbackSuccess         = "fallback-success"
+	EventFallbackFailure         = "fallback-failure"
+	EventContextCanceled         = "context_canceled"
+	EventContextDeadlineExceeded = "context_deadline_exceeded"
+)
+
+// CircuitError is returned instead of running a command
+type CircuitError struct {
+	Message string
+}
+
+func (e CircuitError) Error() string {
+	return "circuit breaker: " + e.Message
+}
+
+var (
+	// ErrCircuitOpen is returned when the circuit of a command is open
+	ErrCircuitOpen = CircuitError{Message: "circuit open"}
+	// ErrMaxConcurrency is returned when a command is already running MaxConcurrentRequests times
+	ErrMaxConcurrency = CircuitError{Message: "max concurrency"}
+	// ErrTimeout is returned when a command does not complete within its Timeout
+	ErrTimeout = CircuitError{Message: "timeout"}
+)
+
+// State is the state of a circuit
+type State int
+
+const (
+	// Closed circuits allow all requests
+	Closed State = iota
+	// Open circuits reject all requests until the sleep window ends
+	Open
+	// HalfOpen circuits allow a single probe request
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+var (
+	breakersMu sync.RWMutex
+	breakers   = make(map[string]*Breaker)
+)
+
+// Breaker is the circuit breaker of a single command
+type Breaker struct {
+	name       string
+	collectors []Collector
+
+	mu           sync.Mutex
+	state        State
+	forceOpen    bool
+	openedAt     time.Time
+	probing      bool
+	probeStarted time.Time
+	active       int
+	metrics      rollingMetrics
+}
+
+// Get returns the breaker of command name, it is created on first use
+func Get(name string) *Breaker {
+	breakersMu.RLock()
+	b, ok := breakers[name]
+	breakersMu.RUnlock()
+	if ok {
+		return b
+	}
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	if b, ok := breakers[name]; ok {
+		return b
+	}
+	b = &Breaker{
+		name:       name,
+		collectors: newCollectors(name),
+	}
+	breakers[name] = b
+	return b
+}
+
+// Breakers returns all breakers sorted by name
+func Breakers() []*Breaker {
+	breakersMu.RLock()
+	list := make([]*Breaker, 0, len(breakers))
+	for _, b := range breakers {
+		list = append(list, b)
+	}
+	breakersMu.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+	return list
+}
+
+// Flush drops all breakers along with their state and metrics, they are created again with the current settings
+func Flush() {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	breakers = make(map[string]*Breaker)
+}
+
+// Name returns the command name of this breaker
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State returns the state of the circuit
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// IsOpen returns true when the circuit is not closed
+func (b *Breaker) IsOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.forceOpen || b.state != Closed
+}
+
+// ForceOpen rejects all requests while toggle is true
+func (b *Breaker) ForceOpen(toggle bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.forceOpen = toggle
+}
+
+// AllowRequest is checked before a command runs, once the sleep window of an open circuit ends a single request
+// is allowed as a probe, its outcome has to be reported with ReportEvent
+func (b *Breaker) AllowRequest() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.forceOpen {
+		return false
+	}
+	if b.state == Closed {
+		return true
+	}
+	now := time.Now()
+	sleepWindow := GetSettings(b.name).SleepWindow
+	if b.state == Open {
+		if now.Sub(b.openedAt) < sleepWindow {
+			return false
+		}
+		b.state = HalfOpen
+		b.probing = false
+	}
+	// a probe that never reported is given up after a sleep window
+	if b.probing && now.Sub(b.probeStarted) < sleepWindow {
+		return false
+	}
+	b.probing = true
+	b.probeStarted = now
+	return true
+}
+
+// ReportEvent records the outcome of a command that started at start and ran for runDuration, events[0] is
+// the outcome (EventSuccess, EventFailure...) and the optional events[1] the outcome of its fallback
+func (b *Breaker) ReportEvent(events []string, start time.Time, runDuration time.Duration) {
+	if len(events) == 0 {
+		return
+	}
+	now := time.Now()
+	r := newResult(events, now.Sub(start), runDuration)
+
+	b.mu.Lock()
+	settings := GetSettings(b.name)
+	b.metrics.add(now, r)
+	switch b.state {
+	case Closed:
+		sum := b.metrics.counts(now)
+		if int(sum.Attempts) >= settings.RequestVolumeThreshold && errorPercent(sum) >= settings.ErrorPercentThreshold {
+			b.open(now)
+		}
+	case Open, HalfOpen:
+		switch events[0] {
+		case EventSuccess:
+			// the command recovered
+			b.state = Closed
+			b.probing = false
+			b.metrics.reset()
+		case EventFailure, EventTimeout, EventRejected:
+			if b.state == HalfOpen {
+				b.open(now)
+			}
+		case EventContextCanceled, EventContextDeadlineExceeded:
+			if b.state == HalfOpen {
+				// inconclusive, allow another probe
+				b.probing = false
+			}
+		}
+	}
+	r.CircuitOpen = b.forceOpen || b.state != Closed
+	r.ConcurrencyInUse = float64(b.active) / float64(settings.MaxConcurrentRequests)
+	b.mu.Unlock()
+
+	for _, c := range b.collectors {
+		c.Update(r)
+	}
+}
+
+// open opens the circuit, b.mu is held
+func (b *Breaker) open(now time.Time) {
+	b.state = Open
+	b.openedAt = now
+	b.probing = false
+}
+
+// acquire takes a concurrency slot, it returns false when all slots are taken
+func (b *Breaker) acquire() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.active >= GetSettings(b.name).MaxConcurrentRequests {
+		return false
+	}
+	b.active++
+	b.metrics.active(time.Now(), b.active)
+	return true
+}
+
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.active--
+}
@@ -0,0 +1,204 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTest = errors.New("test error")
+
+func fail() error { return errTest }
+
+func succeed() error { return nil }
+
+func TestBreakerOpensOnErrors(t *testing.T) {
+	name := "TestBreakerOpensOnErrors"
+	ConfigureCommand(name, CommandConfig{RequestVolumeThreshold: 4, ErrorPercentThreshold: 50, SleepWindow: 60000})
+	defer Flush()
+
+	assert.NoError(t, Do(name, succeed, nil))
+	assert.NoError(t, Do(name, succeed, nil))
+	assert.Equal(t, errTest, Do(name, fail, nil))
+	assert.False(t, Get(name).IsOpen(), "below the volume threshold")
+	assert.Equal(t, errTest, Do(name, fail, nil))
+	assert.True(t, Get(name).IsOpen())
+	assert.Equal(t, Open, Get(name).State())
+
+	called := false
+	err := Do(name, func() error {
+		called = true
+		return nil
+	}, nil)
+	assert.False(t, called)
+	assert.Equal(t, ErrCircuitOpen, err)
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	name := "TestBreakerHalfOpen"
+	ConfigureCommand(name, CommandConfig{RequestVolumeThreshold: 1, ErrorPercentThreshold: 50, SleepWindow: 50})
+	defer Flush()
+	b := Get(name)
+
+	assert.Equal(t, errTest, Do(name, fail, nil))
+	assert.Equal(t, Open, b.State())
+	assert.False(t, b.AllowRequest())
+
+	// a failing probe opens the circuit again
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, b.AllowRequest())
+	assert.Equal(t, HalfOpen, b.State())
+	assert.False(t, b.AllowRequest(), "only a single probe is allowed")
+	b.ReportEvent([]string{EventFailure}, time.Now(), 0)
+	assert.Equal(t, Open, b.State())
+	assert.False(t, b.AllowRequest())
+
+	// a canceled probe is inconclusive
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, b.AllowRequest())
+	b.ReportEvent([]string{EventContextCanceled}, time.Now(), 0)
+	assert.Equal(t, HalfOpen, b.State())
+
+	// a successful probe closes the circuit
+	assert.NoError(t, Do(name, succeed, nil))
+	assert.Equal(t, Closed, b.State())
+	assert.True(t, b.AllowRequest())
+}
+
+func TestBreakerForceOpen(t *testing.T) {
+	name := "TestBreakerForceOpen"
+	defer Flush()
+	b := Get(name)
+	b.ForceOpen(true)
+	assert.True(t, b.IsOpen())
+	assert.Equal(t, ErrCircuitOpen, Do(name, succeed, nil))
+	b.ForceOpen(false)
+	assert.NoError(t, Do(name, succeed, nil))
+}
+
+func TestDoTimeout(t *testing.T) {
+	name := "TestDoTimeout"
+	ConfigureCommand(name, CommandConfig{Timeout: 20})
+	defer Flush()
+
+	err := Do(name, func() error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}, nil)
+	assert.Equal(t, ErrTimeout, err)
+}
+
+func TestDoMaxConcurrency(t *testing.T) {
+	name := "TestDoMaxConcurrency"
+	ConfigureCommand(name, CommandConfig{MaxConcurrentRequests: 1})
+	defer Flush()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		Do(name, func() error {
+			close(started)
+			<-release
+			return nil
+		}, nil)
+	}()
+	<-started
+	assert.Equal(t, ErrMaxConcurrency, Do(name, succeed, nil))
+	close(release)
+	wg.Wait()
+	assert.NoError(t, Do(name, succeed, nil))
+}
+
+func TestDoFallback(t *testing.T) {
+	name := "TestDoFallback"
+	defer Flush()
+
+	var fallbackErr error
+	err := Do(name, fail, func(err error) error {
+		fallbackErr = err
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, errTest, fallbackErr)
+
+	other := errors.New("other")
+	assert.Equal(t, other, Do(name, fail, func(error) error { return other }))
+}
+
+func TestDoCCanceled(t *testing.T) {
+	name := "TestDoCCanceled"
+	ConfigureCommand(name, CommandConfig{RequestVolumeThreshold: 1})
+	defer Flush()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := DoC(ctx, name, func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return ctx.Err()
+	}, nil)
+	assert.Equal(t, context.Canceled, err)
+	assert.False(t, Get(name).IsOpen(), "canceled commands are not errors")
+}
+
+type testCollector struct {
+	mu      sync.Mutex
+	results []Result
+}
+
+func (c *testCollector) Update(r Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results = append(c.results, r)
+}
+
+func TestCollector(t *testing.T) {
+	name := "TestCollector"
+	ConfigureCommand(name, CommandConfig{RequestVolumeThreshold: 1})
+	defer Flush()
+	c := &testCollector{}
+	RegisterCollector(func(cmd string) Collector {
+		if cmd == name {
+			return c
+		}
+		return nil
+	})
+
+	Do(name, fail, func(error) error { return nil })
+	Do(name, succeed, nil)
+	if assert.Len(t, c.results, 2) {
+		assert.Equal(t, float64(1), c.results[0].Failures)
+		assert.Equal(t, float64(1), c.results[0].Errors)
+		assert.Equal(t, float64(1), c.results[0].FallbackSuccesses)
+		assert.True(t, c.results[0].CircuitOpen)
+		assert.Equal(t, float64(1), c.results[1].ShortCircuits)
+	}
+}
+
+func TestGetSettings(t *testing.T) {
+	ConfigureCommand("/svc/method", CommandConfig{Timeout: 10})
+	s := GetSettings("/svc/Method")
+	assert.Equal(t, 10*time.Millisecond, s.Timeout)
+	assert.Equal(t, DefaultMaxConcurrent, s.MaxConcurrentRequests)
+}
+
+func TestRollingMetrics(t *testing.T) {
+	var m rollingMetrics
+	now := time.Now()
+	m.add(now.Add(-11*time.Second), Result{Attempts: 1, Errors: 1, TotalDuration: time.Second})
+	m.add(now.Add(-time.Second), Result{Attempts: 1, Successes: 1, TotalDuration: 2 * time.Millisecond})
+	m.add(now, Result{Attempts: 1, Errors: 1, Failures: 1, TotalDuration: 4 * time.Millisecond})
+
+	counts := m.counts(now)
+	assert.Equal(t, Result{Attempts: 2, Errors: 1, Successes: 1, Failures: 1}, counts)
+	sum, total, _, _ := m.sum(now)
+	assert.Equal(t, counts, sum)
+	assert.ElementsMatch(t, []time.Duration{2 * time.Millisecond, 4 * time.Millisecond}, total)
+	assert.Equal(t, 50, errorPercent(counts))
+}
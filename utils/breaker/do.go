@@ -0,0 +1,74 @@
+package breaker
+
+import (
+	"context"
+	"time"
+)
+
+//Do runs run as command name and waits for it to complete, fallback (optional) is called with the error of run or a
+//CircuitError when the circuit is open, the command is running at max concurrency or times out, the error returned by
+//fallback is returned and nil when it recovers
+func Do(name string, run func() error, fallback func(error) error) error {
+	var fallbackC func(context.Context, error) error
+	if fallback != nil {
+		fallbackC = func(ctx context.Context, err error) error {
+			return fallback(err)
+		}
+	}
+	return DoC(context.Background(), name, func(ctx context.Context) error {
+		return run()
+	}, fallbackC)
+}
+
+//DoC is similar to Do, it stops waiting once ctx is done, commands that are canceled do not count as errors
+func DoC(ctx context.Context, name string, run func(context.Context) error, fallback func(context.Context, error) error) error {
+	b := Get(name)
+	start := time.Now()
+	if !b.AllowRequest() {
+		return b.fallback(ctx, start, 0, EventShortCircuit, ErrCircuitOpen, fallback)
+	}
+	if !b.acquire() {
+		return b.fallback(ctx, start, 0, EventRejected, ErrMaxConcurrency, fallback)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		// the slot is held until run returns, even when the caller stopped waiting
+		defer b.release()
+		done <- run(ctx)
+	}()
+
+	timeout := GetSettings(name).Timeout
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err == nil {
+			b.ReportEvent([]string{EventSuccess}, start, time.Since(start))
+			return nil
+		}
+		return b.fallback(ctx, start, time.Since(start), EventFailure, err, fallback)
+	case <-timer.C:
+		return b.fallback(ctx, start, timeout, EventTimeout, ErrTimeout, fallback)
+	case <-ctx.Done():
+		event := EventContextCanceled
+		if ctx.Err() == context.DeadlineExceeded {
+			event = EventContextDeadlineExceeded
+		}
+		return b.fallback(ctx, start, time.Since(start), event, ctx.Err(), fallback)
+	}
+}
+
+// fallback reports event along with the outcome of fallback and returns the resulting error
+func (b *Breaker) fallback(ctx context.Context, start time.Time, runDuration time.Duration, event string, err error, fallback func(context.Context, error) error) error {
+	if fallback == nil {
+		b.ReportEvent([]string{event}, start, runDuration)
+		return err
+	}
+	if ferr := fallback(ctx, err); ferr != nil {
+		b.ReportEvent([]string{event, EventFallbackFailure}, start, runDuration)
+		return ferr
+	}
+	b.ReportEvent([]string{event, EventFallbackSuccess}, start, runDuration)
+	return nil
+}
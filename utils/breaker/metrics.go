@@ -0,0 +1,232 @@
+package breaker
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// rollingWindow is the number of one second buckets the health of a command is measured over
+	rollingWindow = 10
+	// maxTimings is the number of durations kept per bucket for latency percentiles
+	maxTimings = 1000
+)
+
+var (
+	collectorsMu sync.RWMutex
+	collectors   []func(name string) Collector
+)
+
+//Result is the outcome of a single command execution as seen by collectors
+type Result struct {
+	Attempts                float64
+	Errors                  float64
+	Successes               float64
+	Failures                float64
+	Rejects                 float64
+	ShortCircuits           float64
+	Timeouts                float64
+	FallbackSuccesses       float64
+	FallbackFailures        float64
+	ContextCanceled         float64
+	ContextDeadlineExceeded float64
+	TotalDuration           time.Duration
+	RunDuration             time.Duration
+	ConcurrencyInUse        float64
+	// CircuitOpen is the state of the circuit after this execution
+	CircuitOpen bool
+}
+
+//Collector receives the outcome of every execution of a command e.g. to export metrics
+type Collector interface {
+	Update(Result)
+}
+
+//RegisterCollector adds a collector factory, it is called once for every command with its name,
+//commands that already exist are not updated
+func RegisterCollector(factory func(name string) Collector) {
+	if factory == nil {
+		return
+	}
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+	collectors = append(collectors, factory)
+}
+
+func newCollectors(name string) []Collector {
+	collectorsMu.RLock()
+	defer collectorsMu.RUnlock()
+	cs := make([]Collector, 0, len(collectors))
+	for _, factory := range collectors {
+		if c := factory(name); c != nil {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+// newResult maps reported events to a Result, the first event is the outcome and the second the fallback outcome
+func newResult(events []string, total, run time.Duration) Result {
+	r := Result{
+		Attempts:      1,
+		TotalDuration: total,
+		RunDuration:   run,
+	}
+	switch events[0] {
+	case EventSuccess:
+		r.Successes = 1
+	case EventFailure:
+		r.Failures = 1
+		r.Errors = 1
+	case EventRejected:
+		r.Rejects = 1
+		r.Errors = 1
+	case EventShortCircuit:
+		r.ShortCircuits = 1
+		r.Errors = 1
+	case EventTimeout:
+		r.Timeouts = 1
+		r.Errors = 1
+	case EventContextCanceled:
+		r.ContextCanceled = 1
+	case EventContextDeadlineExceeded:
+		r.ContextDeadlineExceeded = 1
+	}
+	if len(events) > 1 {
+		switch events[1] {
+		case EventFallbackSuccess:
+			r.FallbackSuccesses = 1
+		case EventFallbackFailure:
+			r.FallbackFailures = 1
+		}
+	}
+	return r
+}
+
+type bucket struct {
+	second  int64
+	result  Result
+	total   []time.Duration
+	run     []time.Duration
+	maxConc int
+}
+
+// rollingMetrics keeps the results of the last rollingWindow seconds, it is guarded by the breaker
+type rollingMetrics struct {
+	buckets [rollingWindow]bucket
+}
+
+func (m *rollingMetrics) bucket(now time.Time) *bucket {
+	second := now.Unix()
+	b := &m.buckets[second%rollingWindow]
+	if b.second != second {
+		*b = bucket{second: second}
+	}
+	return b
+}
+
+func (m *rollingMetrics) add(now time.Time, r Result) {
+	b := m.bucket(now)
+	b.result.Attempts += r.Attempts
+	b.result.Errors += r.Errors
+	b.result.Successes += r.Successes
+	b.result.Failures += r.Failures
+	b.result.Rejects += r.Rejects
+	b.result.ShortCircuits += r.ShortCircuits
+	b.result.Timeouts += r.Timeouts
+	b.result.FallbackSuccesses += r.FallbackSuccesses
+	b.result.FallbackFailures += r.FallbackFailures
+	b.result.ContextCanceled += r.ContextCanceled
+	b.result.ContextDeadlineExceeded += r.ContextDeadlineExceeded
+	if len(b.total) < maxTimings {
+		b.total = append(b.total, r.TotalDuration)
+		b.run = append(b.run, r.RunDuration)
+	}
+}
+
+func (m *rollingMetrics) active(now time.Time, active int) {
+	b := m.bucket(now)
+	if active > b.maxConc {
+		b.maxConc = active
+	}
+}
+
+func (m *rollingMetrics) reset() {
+	m.buckets = [rollingWindow]bucket{}
+}
+
+// counts returns the counts of the rolling window, it is cheap enough to call on every execution
+func (m *rollingMetrics) counts(now time.Time) (sum Result) {
+	second := now.Unix()
+	for i := range m.buckets {
+		b := &m.buckets[i]
+		if second-b.second >= rollingWindow || b.second > second {
+			continue
+		}
+		sum.Attempts += b.result.Attempts
+		sum.Errors += b.result.Errors
+		sum.Successes += b.result.Successes
+		sum.Failures += b.result.Failures
+		sum.Rejects += b.result.Rejects
+		sum.ShortCircuits += b.result.ShortCircuits
+		sum.Timeouts += b.result.Timeouts
+		sum.FallbackSuccesses += b.result.FallbackSuccesses
+		sum.FallbackFailures += b.result.FallbackFailures
+		sum.ContextCanceled += b.result.ContextCanceled
+		sum.ContextDeadlineExceeded += b.result.ContextDeadlineExceeded
+	}
+	return sum
+}
+
+// sum returns the counts of the rolling window along with copies of its durations for latency percentiles
+func (m *rollingMetrics) sum(now time.Time) (sum Result, total, run []time.Duration, maxConc int) {
+	second := now.Unix()
+	for i := range m.buckets {
+		b := &m.buckets[i]
+		if second-b.second >= rollingWindow || b.second > second {
+			continue
+		}
+		total = append(total, b.total...)
+		run = append(run, b.run...)
+		if b.maxConc > maxConc {
+			maxConc = b.maxConc
+		}
+	}
+	return m.counts(now), total, run, maxConc
+}
+
+// errorPercent returns the percentage of errors in r
+func errorPercent(r Result) int {
+	if r.Attempts == 0 {
+		return 0
+	}
+	return int(r.Errors/r.Attempts*100 + 0.5)
+}
+
+// percentiles returns the given percentiles of durations in milliseconds
+func percentiles(durations []time.Duration, ps ...float64) []uint32 {
+	values := make([]uint32, len(ps))
+	if len(durations) == 0 {
+		return values
+	}
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for i, p := range ps {
+		idx := int(p / 100 * float64(len(sorted)-1))
+		values[i] = uint32(sorted[idx] / time.Millisecond)
+	}
+	return values
+}
+
+// mean returns the mean of durations in milliseconds
+func mean(durations []time.Duration) uint32 {
+	if len(durations) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, d := range durations {
+		sum += d
+	}
+	return uint32(sum / time.Duration(len(durations)) / time.Millisecond)
+}
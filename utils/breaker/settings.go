@@ -0,0 +1,98 @@
+package breaker
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// DefaultTimeout is how long to wait for command to complete, in milliseconds
+	DefaultTimeout = 1000
+	// DefaultMaxConcurrent is how many commands of the same type can run at the same time
+	DefaultMaxConcurrent = 10
+	// DefaultVolumeThreshold is the minimum number of requests needed before a circuit can be tripped due to health
+	DefaultVolumeThreshold = 20
+	// DefaultSleepWindow is how long, in milliseconds, to wait after a circuit opens before testing for recovery
+	DefaultSleepWindow = 5000
+	// DefaultErrorPercentThreshold causes circuits to open once the rolling measure of errors exceeds this percent of requests
+	DefaultErrorPercentThreshold = 50
+
+	settingsMu sync.RWMutex
+	commands   = make(map[string]CommandConfig)
+)
+
+//CommandConfig is the configuration of a single command, zero values use the defaults.
+//When read from config the keys are the field names (e.g. MaxConcurrentRequests), matched case insensitively
+type CommandConfig struct {
+	// Timeout is how long to wait for command to complete, in milliseconds
+	Timeout int
+	// MaxConcurrentRequests is how many commands of the same type can run at the same time
+	MaxConcurrentRequests int
+	// RequestVolumeThreshold is the minimum number of requests needed before a circuit can be tripped due to health
+	RequestVolumeThreshold int
+	// SleepWindow is how long, in milliseconds, to wait after a circuit opens before testing for recovery
+	SleepWindow int
+	// ErrorPercentThreshold causes circuits to open once the rolling measure of errors exceeds this percent of requests
+	ErrorPercentThreshold int
+}
+
+//Settings are the effective settings of a command
+type Settings struct {
+	Timeout                time.Duration
+	MaxConcurrentRequests  int
+	RequestVolumeThreshold int
+	SleepWindow            time.Duration
+	ErrorPercentThreshold  int
+}
+
+//SetDefaults sets the defaults used by commands that do not configure a setting, zero values are ignored
+func SetDefaults(config CommandConfig) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	DefaultTimeout = orDefault(config.Timeout, DefaultTimeout)
+	DefaultMaxConcurrent = orDefault(config.MaxConcurrentRequests, DefaultMaxConcurrent)
+	DefaultVolumeThreshold = orDefault(config.RequestVolumeThreshold, DefaultVolumeThreshold)
+	DefaultSleepWindow = orDefault(config.SleepWindow, DefaultSleepWindow)
+	DefaultErrorPercentThreshold = orDefault(config.ErrorPercentThreshold, DefaultErrorPercentThreshold)
+}
+
+//Configure sets the configuration of commands, commands that are not present keep their configuration
+func Configure(cmds map[string]CommandConfig) {
+	for name, config := range cmds {
+		ConfigureCommand(name, config)
+	}
+}
+
+//ConfigureCommand sets the configuration of command name, it applies to running breakers as well
+func ConfigureCommand(name string, config CommandConfig) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	commands[name] = config
+}
+
+//GetSettings returns the effective settings of command name, command names are matched case insensitively
+//as a fallback since config keys are lower cased
+func GetSettings(name string) Settings {
+	settingsMu.RLock()
+	config, ok := commands[name]
+	if !ok {
+		config = commands[strings.ToLower(name)]
+	}
+	s := Settings{
+		Timeout:                time.Duration(orDefault(config.Timeout, DefaultTimeout)) * time.Millisecond,
+		MaxConcurrentRequests:  orDefault(config.MaxConcurrentRequests, DefaultMaxConcurrent),
+		RequestVolumeThreshold: orDefault(config.RequestVolumeThreshold, DefaultVolumeThreshold),
+		SleepWindow:            time.Duration(orDefault(config.SleepWindow, DefaultSleepWindow)) * time.Millisecond,
+		ErrorPercentThreshold:  orDefault(config.ErrorPercentThreshold, DefaultErrorPercentThreshold),
+	}
+	settingsMu.RUnlock()
+	return s
+}
+
+func orDefault(value, def int) int {
+	if value > 0 {
+		return value
+	}
+	return def
+}
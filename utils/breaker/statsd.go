@@ -0,0 +1,105 @@
+package breaker
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cactus/go-statsd-client/statsd"
+)
+
+// https://github.com/etsy/statsd/blob/master/docs/metric_types.md#multi-metric-packets
+const (
+	WANStatsdFlushBytes     = 512
+	LANStatsdFlushBytes     = 1432
+	GigabitStatsdFlushBytes = 8932
+)
+
+//StatsdConfig is the configuration of a StatsdCollector
+type StatsdConfig struct {
+	// Addr is the address of the statsd server
+	Addr string
+	// Prefix is prepended to all metrics, metrics are named {Prefix}.{command}.{metric}
+	Prefix string
+	// SampleRate is the statsd sample rate, 1 when zero
+	SampleRate float32
+	// FlushBytes is the size of statsd packets, LANStatsdFlushBytes when zero
+	FlushBytes int
+}
+
+//StatsdCollector sends the metrics of commands to statsd, the metric names match the hystrix statsd plugin
+type StatsdCollector struct {
+	client     statsd.Statter
+	sampleRate float32
+}
+
+//NewStatsdCollector creates a StatsdCollector, a collector that does not send anything is returned along with the
+//error when the client can not be created
+func NewStatsdCollector(config StatsdConfig) (*StatsdCollector, error) {
+	flushBytes := config.FlushBytes
+	if flushBytes == 0 {
+		flushBytes = LANStatsdFlushBytes
+	}
+	sampleRate := config.SampleRate
+	if sampleRate == 0 {
+		sampleRate = 1
+	}
+	c, err := statsd.NewBufferedClient(config.Addr, config.Prefix, time.Second, flushBytes)
+	if err != nil {
+		c, _ = statsd.NewNoopClient()
+	}
+	return &StatsdCollector{
+		client:     c,
+		sampleRate: sampleRate,
+	}, err
+}
+
+//Collector returns the collector of command name, it can be passed to RegisterCollector
+func (s *StatsdCollector) Collector(name string) Collector {
+	name = strings.NewReplacer("/", "-", ":", "-", ".", "-").Replace(name)
+	return &statsdCmdCollector{
+		client:     s.client,
+		sampleRate: s.sampleRate,
+		prefix:     name + ".",
+	}
+}
+
+//Close flushes and closes the statsd client
+func (s *StatsdCollector) Close() error {
+	return s.client.Close()
+}
+
+type statsdCmdCollector struct {
+	client     statsd.Statter
+	sampleRate float32
+	prefix     string
+}
+
+func (c *statsdCmdCollector) inc(metric string, i float64) {
+	if i == 0 {
+		return
+	}
+	c.client.Inc(c.prefix+metric, int64(i), c.sampleRate)
+}
+
+func (c *statsdCmdCollector) Update(r Result) {
+	var open int64
+	if r.CircuitOpen {
+		open = 1
+	}
+	c.client.Gauge(c.prefix+"circuitOpen", open, c.sampleRate)
+
+	c.inc("attempts", r.Attempts)
+	c.inc("errors", r.Errors)
+	c.inc("successes", r.Successes)
+	c.inc("failures", r.Failures)
+	c.inc("rejects", r.Rejects)
+	c.inc("shortCircuits", r.ShortCircuits)
+	c.inc("timeouts", r.Timeouts)
+	c.inc("fallbackSuccesses", r.FallbackSuccesses)
+	c.inc("fallbackFailures", r.FallbackFailures)
+	c.inc("contextCanceled", r.ContextCanceled)
+	c.inc("contextDeadlineExceeded", r.ContextDeadlineExceeded)
+	c.client.TimingDuration(c.prefix+"totalDuration", r.TotalDuration, c.sampleRate)
+	c.client.TimingDuration(c.prefix+"runDuration", r.RunDuration, c.sampleRate)
+	c.client.Timing(c.prefix+"concurrencyInUse", int64(100*r.ConcurrencyInUse), c.sampleRate)
+}
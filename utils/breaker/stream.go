@@ -0,0 +1,308 @@
+package breaker
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	streamEventBufferSize = 10
+	// rollingStatsWindow is the rolling window in milliseconds as reported to the dashboard
+	rollingStatsWindow = rollingWindow * 1000
+)
+
+//StreamHandler publishes the metrics of all commands once a second as server sent events,
+//the format is compatible with the hystrix dashboard
+type StreamHandler struct {
+	mu       sync.RWMutex
+	requests map[*http.Request]chan []byte
+	done     chan struct{}
+}
+
+//NewStreamHandler returns a StreamHandler, it does not publish anything until Start is called
+func NewStreamHandler() *StreamHandler {
+	return &StreamHandler{
+		requests: make(map[*http.Request]chan []byte),
+	}
+}
+
+//Start begins publishing the metrics of all commands
+func (sh *StreamHandler) Start() {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if sh.done != nil {
+		return
+	}
+	sh.done = make(chan struct{})
+	go sh.loop(sh.done)
+}
+
+//Stop stops publishing metrics
+func (sh *StreamHandler) Stop() {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if sh.done != nil {
+		close(sh.done)
+		sh.done = nil
+	}
+}
+
+var _ http.Handler = (*StreamHandler)(nil)
+
+func (sh *StreamHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	f, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "Streaming unsupported!", http.StatusInternalServerError)
+		return
+	}
+	events := sh.register(req)
+	defer sh.unregister(req)
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+	f.Flush()
+	for {
+		select {
+		case <-req.Context().Done():
+			// client is gone
+			return
+		case event := <-events:
+			if _, err := rw.Write(event); err != nil {
+				return
+			}
+			f.Flush()
+		}
+	}
+}
+
+func (sh *StreamHandler) loop(done chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			sh.publish()
+		case <-done:
+			return
+		}
+	}
+}
+
+// publish writes the metrics of all breakers to the connected clients
+func (sh *StreamHandler) publish() {
+	sh.mu.RLock()
+	clients := len(sh.requests)
+	sh.mu.RUnlock()
+	if clients == 0 {
+		return
+	}
+	for _, b := range Breakers() {
+		cmd, pool := b.streamMetrics(time.Now())
+		sh.write(cmd)
+		sh.write(pool)
+	}
+}
+
+func (sh *StreamHandler) write(metric interface{}) {
+	data, err := json.Marshal(metric)
+	if err != nil {
+		return
+	}
+	event := make([]byte, 0, len(data)+7)
+	event = append(event, "data:"...)
+	event = append(event, data...)
+	event = append(event, "\n\n"...)
+
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	for _, events := range sh.requests {
+		select {
+		case events <- event:
+		default:
+			// slow clients miss events
+		}
+	}
+}
+
+func (sh *StreamHandler) register(req *http.Request) <-chan []byte {
+	events := make(chan []byte, streamEventBufferSize)
+	sh.mu.Lock()
+	sh.requests[req] = events
+	sh.mu.Unlock()
+	return events
+}
+
+func (sh *StreamHandler) unregister(req *http.Request) {
+	sh.mu.Lock()
+	delete(sh.requests, req)
+	sh.mu.Unlock()
+}
+
+// streamMetrics returns the dashboard metrics of the command and its concurrency pool
+func (b *Breaker) streamMetrics(now time.Time) (streamCmdMetric, streamThreadPoolMetric) {
+	settings := GetSettings(b.name)
+	b.mu.Lock()
+	sum, total, run, maxConc := b.metrics.sum(now)
+	active := b.active
+	open := b.forceOpen || b.state != Closed
+	forceOpen := b.forceOpen
+	b.mu.Unlock()
+
+	cmd := streamCmdMetric{
+		Type:           "HystrixCommand",
+		Name:           b.name,
+		Group:          b.name,
+		Time:           now.UnixNano() / int64(time.Millisecond),
+		ReportingHosts: 1,
+
+		RequestCount:       uint32(sum.Attempts),
+		ErrorCount:         uint32(sum.Errors),
+		ErrorPct:           uint32(errorPercent(sum)),
+		CircuitBreakerOpen: open,
+
+		RollingCountSuccess:            uint32(sum.Successes),
+		RollingCountFailure:            uint32(sum.Failures),
+		RollingCountThreadPoolRejected: uint32(sum.Rejects),
+		RollingCountShortCircuited:     uint32(sum.ShortCircuits),
+		RollingCountTimeout:            uint32(sum.Timeouts),
+		RollingCountFallbackSuccess:    uint32(sum.FallbackSuccesses),
+		RollingCountFallbackFailure:    uint32(sum.FallbackFailures),
+
+		CurrentConcurrentExecutionCount: uint32(active),
+
+		LatencyTotal:       newStreamCmdLatency(total),
+		LatencyTotalMean:   mean(total),
+		LatencyExecute:     newStreamCmdLatency(run),
+		LatencyExecuteMean: mean(run),
+
+		CircuitBreakerRequestVolumeThreshold: uint32(settings.RequestVolumeThreshold),
+		CircuitBreakerSleepWindow:            uint32(settings.SleepWindow / time.Millisecond),
+		CircuitBreakerErrorThresholdPercent:  uint32(settings.ErrorPercentThreshold),
+		CircuitBreakerForceOpen:              forceOpen,
+		CircuitBreakerEnabled:                true,
+		ExecutionIsolationStrategy:           "THREAD",
+		ExecutionIsolationThreadTimeout:      uint32(settings.Timeout / time.Millisecond),
+		RollingStatsWindow:                   rollingStatsWindow,
+	}
+	executed := sum.Attempts - sum.Rejects - sum.ShortCircuits
+	pool := streamThreadPoolMetric{
+		Type:           "HystrixThreadPool",
+		Name:           b.name,
+		ReportingHosts: 1,
+
+		CurrentActiveCount:     uint32(active),
+		CurrentCorePoolSize:    uint32(settings.MaxConcurrentRequests),
+		CurrentLargestPoolSize: uint32(settings.MaxConcurrentRequests),
+		CurrentMaximumPoolSize: uint32(settings.MaxConcurrentRequests),
+		CurrentPoolSize:        uint32(settings.MaxConcurrentRequests),
+
+		RollingMaxActiveThreads:     uint32(maxConc),
+		RollingCountThreadsExecuted: uint32(executed),
+
+		RollingStatsWindow: rollingStatsWindow,
+	}
+	return cmd, pool
+}
+
+func newStreamCmdLatency(durations []time.Duration) streamCmdLatency {
+	p := percentiles(durations, 0, 25, 50, 75, 90, 95, 99, 99.5, 100)
+	return streamCmdLatency{
+		Timing0:   p[0],
+		Timing25:  p[1],
+		Timing50:  p[2],
+		Timing75:  p[3],
+		Timing90:  p[4],
+		Timing95:  p[5],
+		Timing99:  p[6],
+		Timing995: p[7],
+		Timing100: p[8],
+	}
+}
+
+type streamCmdMetric struct {
+	Type           string `json:"type"`
+	Name           string `json:"name"`
+	Group          string `json:"group"`
+	Time           int64  `json:"currentTime"`
+	ReportingHosts uint32 `json:"reportingHosts"`
+
+	// Health
+	RequestCount       uint32 `json:"requestCount"`
+	ErrorCount         uint32 `json:"errorCount"`
+	ErrorPct           uint32 `json:"errorPercentage"`
+	CircuitBreakerOpen bool   `json:"isCircuitBreakerOpen"`
+
+	RollingCountCollapsedRequests  uint32 `json:"rollingCountCollapsedRequests"`
+	RollingCountExceptionsThrown   uint32 `json:"rollingCountExceptionsThrown"`
+	RollingCountFailure            uint32 `json:"rollingCountFailure"`
+	RollingCountFallbackFailure    uint32 `json:"rollingCountFallbackFailure"`
+	RollingCountFallbackRejection  uint32 `json:"rollingCountFallbackRejection"`
+	RollingCountFallbackSuccess    uint32 `json:"rollingCountFallbackSuccess"`
+	RollingCountResponsesFromCache uint32 `json:"rollingCountResponsesFromCache"`
+	RollingCountSemaphoreRejected  uint32 `json:"rollingCountSemaphoreRejected"`
+	RollingCountShortCircuited     uint32 `json:"rollingCountShortCircuited"`
+	RollingCountSuccess            uint32 `json:"rollingCountSuccess"`
+	RollingCountThreadPoolRejected uint32 `json:"rollingCountThreadPoolRejected"`
+	RollingCountTimeout            uint32 `json:"rollingCountTimeout"`
+
+	CurrentConcurrentExecutionCount uint32 `json:"currentConcurrentExecutionCount"`
+
+	LatencyExecuteMean uint32           `json:"latencyExecute_mean"`
+	LatencyExecute     streamCmdLatency `json:"latencyExecute"`
+	LatencyTotalMean   uint32           `json:"latencyTotal_mean"`
+	LatencyTotal       streamCmdLatency `json:"latencyTotal"`
+
+	// Properties
+	CircuitBreakerRequestVolumeThreshold             uint32 `json:"propertyValue_circuitBreakerRequestVolumeThreshold"`
+	CircuitBreakerSleepWindow                        uint32 `json:"propertyValue_circuitBreakerSleepWindowInMilliseconds"`
+	CircuitBreakerErrorThresholdPercent              uint32 `json:"propertyValue_circuitBreakerErrorThresholdPercentage"`
+	CircuitBreakerForceOpen                          bool   `json:"propertyValue_circuitBreakerForceOpen"`
+	CircuitBreakerForceClosed                        bool   `json:"propertyValue_circuitBreakerForceClosed"`
+	CircuitBreakerEnabled                            bool   `json:"propertyValue_circuitBreakerEnabled"`
+	ExecutionIsolationStrategy                       string `json:"propertyValue_executionIsolationStrategy"`
+	ExecutionIsolationThreadTimeout                  uint32 `json:"propertyValue_executionIsolationThreadTimeoutInMilliseconds"`
+	ExecutionIsolationThreadInterruptOnTimeout       bool   `json:"propertyValue_executionIsolationThreadInterruptOnTimeout"`
+	ExecutionIsolationThreadPoolKeyOverride          string `json:"propertyValue_executionIsolationThreadPoolKeyOverride"`
+	ExecutionIsolationSemaphoreMaxConcurrentRequests uint32 `json:"propertyValue_executionIsolationSemaphoreMaxConcurrentRequests"`
+	FallbackIsolationSemaphoreMaxConcurrentRequests  uint32 `json:"propertyValue_fallbackIsolationSemaphoreMaxConcurrentRequests"`
+	RollingStatsWindow                               uint32 `json:"propertyValue_metricsRollingStatisticalWindowInMilliseconds"`
+	RequestCacheEnabled                              bool   `json:"propertyValue_requestCacheEnabled"`
+	RequestLogEnabled                                bool   `json:"propertyValue_requestLogEnabled"`
+}
+
+type streamCmdLatency struct {
+	Timing0   uint32 `json:"0"`
+	Timing25  uint32 `json:"25"`
+	Timing50  uint32 `json:"50"`
+	Timing75  uint32 `json:"75"`
+	Timing90  uint32 `json:"90"`
+	Timing95  uint32 `json:"95"`
+	Timing99  uint32 `json:"99"`
+	Timing995 uint32 `json:"99.5"`
+	Timing100 uint32 `json:"100"`
+}
+
+type streamThreadPoolMetric struct {
+	Type           string `json:"type"`
+	Name           string `json:"name"`
+	ReportingHosts uint32 `json:"reportingHosts"`
+
+	CurrentActiveCount        uint32 `json:"currentActiveCount"`
+	CurrentCompletedTaskCount uint32 `json:"currentCompletedTaskCount"`
+	CurrentCorePoolSize       uint32 `json:"currentCorePoolSize"`
+	CurrentLargestPoolSize    uint32 `json:"currentLargestPoolSize"`
+	CurrentMaximumPoolSize    uint32 `json:"currentMaximumPoolSize"`
+	CurrentPoolSize           uint32 `json:"currentPoolSize"`
+	CurrentQueueSize          uint32 `json:"currentQueueSize"`
+	CurrentTaskCount          uint32 `json:"currentTaskCount"`
+
+	RollingMaxActiveThreads     uint32 `json:"rollingMaxActiveThreads"`
+	RollingCountThreadsExecuted uint32 `json:"rollingCountThreadsExecuted"`
+
+	RollingStatsWindow          uint32 `json:"propertyValue_metricsRollingStatisticalWindowInMilliseconds"`
+	QueueSizeRejectionThreshold uint32 `json:"propertyValue_queueSizeRejectionThreshold"`
+}
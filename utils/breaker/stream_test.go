@@ -0,0 +1,62 @@
+package breaker
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamHandler(t *testing.T) {
+	name := "TestStreamHandler"
+	defer Flush()
+	Do(name, succeed, nil)
+	Do(name, fail, nil)
+
+	sh := NewStreamHandler()
+	sh.Start()
+	defer sh.Stop()
+	server := httptest.NewServer(sh)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	metrics := make(map[string]map[string]interface{})
+	reader := bufio.NewReader(resp.Body)
+	deadline := time.Now().Add(3 * time.Second)
+	for len(metrics) < 2 && time.Now().Before(deadline) {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return
+		}
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		metric := make(map[string]interface{})
+		assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &metric))
+		if metric["name"] == name {
+			metrics[metric["type"].(string)] = metric
+		}
+	}
+	if assert.Contains(t, metrics, "HystrixCommand") {
+		cmd := metrics["HystrixCommand"]
+		assert.Equal(t, float64(2), cmd["requestCount"])
+		assert.Equal(t, float64(1), cmd["errorCount"])
+		assert.Equal(t, float64(50), cmd["errorPercentage"])
+		assert.Equal(t, float64(1), cmd["rollingCountSuccess"])
+		assert.Equal(t, float64(1), cmd["rollingCountFailure"])
+	}
+	if assert.Contains(t, metrics, "HystrixThreadPool") {
+		assert.Equal(t, float64(2), metrics["HystrixThreadPool"]["rollingCountThreadsExecuted"])
+	}
+}
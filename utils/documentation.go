@@ -6,3 +6,4 @@ package utils
 //go:generate godoc2ghmd -ex -file=listenerutils/README.md github.com/carousell/Orion/utils/listenerutils
 //go:generate godoc2ghmd -ex -file=options/README.md github.com/carousell/Orion/utils/options
 //go:generate godoc2ghmd -ex -file=log/README.md github.com/carousell/Orion/utils/log
+//go:generate godoc2ghmd -ex -file=openapi/README.md github.com/carousell/Orion/utils/openapi
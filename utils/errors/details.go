@@ -0,0 +1,135 @@
+package errors
+
+import (
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+//FieldViolation describes a single invalid field of a request
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+//PreconditionViolation describes a single failed precondition, Type is a service specific type e.g. "TOS",
+//Subject is what failed e.g. "user:123"
+type PreconditionViolation struct {
+	Type        string
+	Subject     string
+	Description string
+}
+
+// newWithDetails creates an error with code and details attached to its status, skipping skip callers
+func newWithDetails(code codes.Code, msg string, skip int, details ...protoadapt.MessageV1) ErrorExt {
+	status := grpcstatus.New(code, msg)
+	if len(details) > 0 {
+		if s, err := status.WithDetails(details...); err == nil {
+			status = s
+		}
+	}
+	return NewWithSkipAndStatus(msg, skip+1, status)
+}
+
+//NotFound creates an error with codes.NotFound
+func NotFound(msg string) ErrorExt {
+	return newWithDetails(codes.NotFound, msg, 1)
+}
+
+//AlreadyExists creates an error with codes.AlreadyExists
+func AlreadyExists(msg string) ErrorExt {
+	return newWithDetails(codes.AlreadyExists, msg, 1)
+}
+
+//PermissionDenied creates an error with codes.PermissionDenied
+func PermissionDenied(msg string) ErrorExt {
+	return newWithDetails(codes.PermissionDenied, msg, 1)
+}
+
+//Unauthenticated creates an error with codes.Unauthenticated
+func Unauthenticated(msg string) ErrorExt {
+	return newWithDetails(codes.Unauthenticated, msg, 1)
+}
+
+//Internal creates an error with codes.Internal
+func Internal(msg string) ErrorExt {
+	return newWithDetails(codes.Internal, msg, 1)
+}
+
+//InvalidArgument creates an error with codes.InvalidArgument, violations are attached as google.rpc.BadRequest
+func InvalidArgument(msg string, violations ...FieldViolation) ErrorExt {
+	if len(violations) == 0 {
+		return newWithDetails(codes.InvalidArgument, msg, 1)
+	}
+	br := &errdetails.BadRequest{}
+	for _, v := range violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+	return newWithDetails(codes.InvalidArgument, msg, 1, br)
+}
+
+//PreconditionFailed creates an error with codes.FailedPrecondition, violations are attached as google.rpc.PreconditionFailure
+func PreconditionFailed(msg string, violations ...PreconditionViolation) ErrorExt {
+	if len(violations) == 0 {
+		return newWithDetails(codes.FailedPrecondition, msg, 1)
+	}
+	pf := &errdetails.PreconditionFailure{}
+	for _, v := range violations {
+		pf.Violations = append(pf.Violations, &errdetails.PreconditionFailure_Violation{
+			Type:        v.Type,
+			Subject:     v.Subject,
+			Description: v.Description,
+		})
+	}
+	return newWithDetails(codes.FailedPrecondition, msg, 1, pf)
+}
+
+//Unavailable creates an error with codes.Unavailable, retryAfter is attached as google.rpc.RetryInfo when set
+func Unavailable(msg string, retryAfter time.Duration) ErrorExt {
+	if retryAfter <= 0 {
+		return newWithDetails(codes.Unavailable, msg, 1)
+	}
+	return newWithDetails(codes.Unavailable, msg, 1, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+}
+
+//WithDetails attaches google.rpc error details to the status of err, errors without a status are treated as codes.Internal
+func WithDetails(err error, details ...protoadapt.MessageV1) ErrorExt {
+	return withDetails(err, 1, details...)
+}
+
+func withDetails(err error, skip int, details ...protoadapt.MessageV1) ErrorExt {
+	if err == nil {
+		return nil
+	}
+	status, ok := grpcstatus.FromError(err)
+	if !ok {
+		status = grpcstatus.New(codes.Internal, err.Error())
+	}
+	if s, e := status.WithDetails(details...); e == nil {
+		status = s
+	}
+	return WrapWithSkipAndStatus(err, "", skip+1, status)
+}
+
+//WithErrorInfo attaches google.rpc.ErrorInfo to err, reason is a constant identifying the error e.g. "QUOTA_EXCEEDED"
+//and domain identifies the service e.g. "listing.carousell.com"
+func WithErrorInfo(err error, reason, domain string, metadata map[string]string) ErrorExt {
+	return withDetails(err, 1, &errdetails.ErrorInfo{Reason: reason, Domain: domain, Metadata: metadata})
+}
+
+//WithRetryInfo attaches google.rpc.RetryInfo to err
+func WithRetryInfo(err error, retryAfter time.Duration) ErrorExt {
+	return withDetails(err, 1, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+}
+
+//WithLocalizedMessage attaches google.rpc.LocalizedMessage to err, locale follows BCP 47 e.g. "en-US"
+func WithLocalizedMessage(err error, locale, message string) ErrorExt {
+	return withDetails(err, 1, &errdetails.LocalizedMessage{Locale: locale, Message: message})
+}
@@ -0,0 +1,56 @@
+package errors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+func TestInvalidArgument(t *testing.T) {
+	err := InvalidArgument("invalid listing", FieldViolation{Field: "price", Description: "must be positive"})
+	s, ok := grpcstatus.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, s.Code())
+	assert.Equal(t, "invalid listing", s.Message())
+	if assert.Len(t, s.Details(), 1) {
+		br := s.Details()[0].(*errdetails.BadRequest)
+		assert.Equal(t, "price", br.GetFieldViolations()[0].GetField())
+	}
+	// stack starts at the caller
+	assert.Equal(t, "TestInvalidArgument", err.StackFrame()[0].Func)
+}
+
+func TestTypedErrors(t *testing.T) {
+	for code, err := range map[codes.Code]error{
+		codes.NotFound:           NotFound("missing"),
+		codes.AlreadyExists:      AlreadyExists("exists"),
+		codes.PermissionDenied:   PermissionDenied("denied"),
+		codes.Unauthenticated:    Unauthenticated("who"),
+		codes.Internal:           Internal("oops"),
+		codes.FailedPrecondition: PreconditionFailed("tos", PreconditionViolation{Type: "TOS", Subject: "user:1"}),
+		codes.Unavailable:        Unavailable("down", time.Second),
+	} {
+		assert.Equal(t, code, grpcstatus.Code(err), code.String())
+	}
+}
+
+func TestWithDetails(t *testing.T) {
+	err := WithErrorInfo(NotFound("missing listing"), "LISTING_NOT_FOUND", "listing.carousell.com", map[string]string{"id": "1"})
+	err = WithLocalizedMessage(err, "en-US", "This listing is no longer available")
+	err = WithRetryInfo(err, 2*time.Second)
+
+	s := grpcstatus.Convert(err)
+	assert.Equal(t, codes.NotFound, s.Code())
+	assert.Equal(t, "missing listing", s.Message())
+	if assert.Len(t, s.Details(), 3) {
+		assert.Equal(t, "LISTING_NOT_FOUND", s.Details()[0].(*errdetails.ErrorInfo).GetReason())
+		assert.Equal(t, "en-US", s.Details()[1].(*errdetails.LocalizedMessage).GetLocale())
+		assert.Equal(t, 2*time.Second, s.Details()[2].(*errdetails.RetryInfo).GetRetryDelay().AsDuration())
+	}
+	// the original stack is kept
+	assert.Equal(t, "TestWithDetails", err.StackFrame()[0].Func)
+}
@@ -0,0 +1,143 @@
+package notifier
+
+import (
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/carousell/Orion/utils/errors"
+	"github.com/stvp/rollbar"
+	gobrake "gopkg.in/airbrake/gobrake.v2"
+)
+
+const (
+	//AirbrakeReporter is the name of the reporter registered by InitAirbrake
+	AirbrakeReporter = "airbrake"
+	//BugsnagReporter is the name of the reporter registered by InitBugsnag
+	BugsnagReporter = "bugsnag"
+	//RollbarReporter is the name of the reporter registered by InitRollbar
+	RollbarReporter = "rollbar"
+	//SentryReporter is the name of the reporter registered by InitSentry
+	SentryReporter = "sentry"
+)
+
+// waitTimeout runs wait and returns once it finishes or timeout elapses
+func waitTimeout(wait func(), timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+func convToGoBrake(in []errors.StackFrame) []gobrake.StackFrame {
+	out := make([]gobrake.StackFrame, 0)
+	for _, s := range in {
+		out = append(out, gobrake.StackFrame{
+			File: s.File,
+			Func: s.Func,
+			Line: s.Line,
+		})
+	}
+	return out
+}
+
+type airbrakeReporter struct {
+	notifier *gobrake.Notifier
+}
+
+//NewAirbrakeReporter creates a Reporter that sends events to airbrake
+func NewAirbrakeReporter(projectID int64, projectKey string) Reporter {
+	return &airbrakeReporter{notifier: gobrake.NewNotifier(projectID, projectKey)}
+}
+
+func (a *airbrakeReporter) Name() string {
+	return AirbrakeReporter
+}
+
+func (a *airbrakeReporter) Report(event Event) error {
+	n := gobrake.NewNotice(event.Err, nil, 1)
+	n.Errors[0].Backtrace = convToGoBrake(event.Err.StackFrame())
+	for k, v := range event.Data {
+		n.Context[k] = v
+	}
+	if event.TraceID != "" {
+		n.Context["traceId"] = event.TraceID
+	}
+	if env := getEnvironment(); env != "" {
+		n.Context["environment"] = env
+	}
+	if event.Panic != nil {
+		// the process is about to crash, deliver before returning
+		_, err := a.notifier.SendNotice(n)
+		return err
+	}
+	a.notifier.SendNoticeAsync(n)
+	return nil
+}
+
+func (a *airbrakeReporter) Flush(timeout time.Duration) {
+	waitTimeout(a.notifier.Flush, timeout)
+}
+
+func (a *airbrakeReporter) Close() error {
+	return a.notifier.Close()
+}
+
+type bugsnagReporter struct{}
+
+//NewBugsnagReporter creates a Reporter that sends events to bugsnag, configured by config
+func NewBugsnagReporter(config bugsnag.Configuration) Reporter {
+	bugsnag.Configure(config)
+	return bugsnagReporter{}
+}
+
+func (bugsnagReporter) Name() string {
+	return BugsnagReporter
+}
+
+func (bugsnagReporter) Report(event Event) error {
+	raw := event.Raw
+	if event.Panic != nil {
+		raw = append([]interface{}{bugsnag.SeverityError}, raw...)
+	}
+	return bugsnag.Notify(event.Err, raw...)
+}
+
+type rollbarReporter struct{}
+
+//NewRollbarReporter creates a Reporter that sends events to rollbar
+func NewRollbarReporter(token, env string) Reporter {
+	rollbar.Token = token
+	rollbar.Environment = env
+	return rollbarReporter{}
+}
+
+func (rollbarReporter) Name() string {
+	return RollbarReporter
+}
+
+func (rollbarReporter) Report(event Event) error {
+	level := parseLevel(event.Severity).String()
+	fields := []*rollbar.Field{}
+	for k, v := range event.Data {
+		fields = append(fields, &rollbar.Field{Name: k, Data: v})
+	}
+	if event.TraceID != "" {
+		fields = append(fields, &rollbar.Field{Name: "traceId", Data: event.TraceID})
+	}
+	if event.Panic != nil {
+		level = rollbar.CRIT
+		fields = append(fields, &rollbar.Field{Name: "panic", Data: event.Panic})
+	}
+	fields = append(fields, &rollbar.Field{Name: "server", Data: map[string]interface{}{"hostname": getHostname(), "root": getServerRoot()}})
+	rollbar.ErrorWithStack(level, event.Err, convToRollbar(event.Err.StackFrame()), fields...)
+	return nil
+}
+
+func (rollbarReporter) Flush(timeout time.Duration) {
+	waitTimeout(rollbar.Wait, timeout)
+}
@@ -0,0 +1,169 @@
+package notifier
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carousell/Orion/utils/errors"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	//DefaultDedupWindow is the dedup window used when none is provided
+	DefaultDedupWindow = time.Minute
+	//DefaultDedupFrames is the number of top stack frames used for fingerprints when none is provided
+	DefaultDedupFrames = 3
+	//DefaultDedupMaxKeys is the number of fingerprints tracked when none is provided
+	DefaultDedupMaxKeys = 10000
+)
+
+//DedupConfig configures deduplication of reported errors, errors with the same fingerprint (cause type,
+//top stack frames and gRPC code) are reported Burst times per Window, further duplicates are counted and
+//reported as a single "N occurrences" notice at the end of the Window
+type DedupConfig struct {
+	Enabled bool
+	// Window is the duration in which duplicates are counted
+	Window time.Duration
+	// Burst is the number of duplicates reported per Window before counting (default 1)
+	Burst int
+	// Frames is the number of top stack frames in a fingerprint
+	Frames int
+	// MaxKeys is the number of fingerprints tracked, errors are reported as is when exceeded
+	MaxKeys int
+}
+
+type dedupEntry struct {
+	first Event
+	sent  int
+	count int
+	start time.Time
+	timer *time.Timer
+}
+
+type deduper struct {
+	mu      sync.Mutex
+	config  DedupConfig
+	entries map[string]*dedupEntry
+}
+
+var dedup = &deduper{entries: make(map[string]*dedupEntry)}
+
+//SetDedup enables or updates deduplication of reported errors, pending occurrences are reported when
+//dedup is disabled
+func SetDedup(config DedupConfig) {
+	if config.Window <= 0 {
+		config.Window = DefaultDedupWindow
+	}
+	if config.Burst <= 0 {
+		config.Burst = 1
+	}
+	if config.Frames <= 0 {
+		config.Frames = DefaultDedupFrames
+	}
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = DefaultDedupMaxKeys
+	}
+	dedup.mu.Lock()
+	dedup.config = config
+	dedup.mu.Unlock()
+	if !config.Enabled {
+		dedup.flush()
+	}
+}
+
+//Fingerprint identifies duplicates of err by its cause type, top frames of its stack and its gRPC code
+func Fingerprint(err errors.ErrorExt, frames int) string {
+	var b strings.Builder
+	if cause := err.Cause(); cause != nil {
+		b.WriteString(reflect.TypeOf(cause).String())
+	}
+	code := codes.Unknown
+	if s := err.GRPCStatus(); s != nil {
+		code = s.Code()
+	}
+	b.WriteString("|")
+	b.WriteString(code.String())
+	for i, f := range err.StackFrame() {
+		if i >= frames {
+			break
+		}
+		b.WriteString("|")
+		b.WriteString(f.Func)
+		b.WriteString(":")
+		b.WriteString(strconv.Itoa(f.Line))
+	}
+	return b.String()
+}
+
+// allow returns true when event should be reported now, duplicates are counted instead
+func (d *deduper) allow(event Event) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.config.Enabled {
+		return true
+	}
+	key := Fingerprint(event.Err, d.config.Frames)
+	if e, ok := d.entries[key]; ok {
+		if e.sent < d.config.Burst {
+			e.sent++
+			return true
+		}
+		e.count++
+		return false
+	}
+	if len(d.entries) >= d.config.MaxKeys {
+		return true
+	}
+	e := &dedupEntry{first: event, sent: 1, start: time.Now()}
+	e.timer = time.AfterFunc(d.config.Window, func() { d.expire(key, e) })
+	d.entries[key] = e
+	return true
+}
+
+func (d *deduper) expire(key string, e *dedupEntry) {
+	d.mu.Lock()
+	if d.entries[key] != e {
+		d.mu.Unlock()
+		return
+	}
+	delete(d.entries, key)
+	d.mu.Unlock()
+	d.report(e)
+}
+
+// flush reports all pending occurrences without waiting for their window to end
+func (d *deduper) flush() {
+	d.mu.Lock()
+	entries := d.entries
+	d.entries = make(map[string]*dedupEntry)
+	d.mu.Unlock()
+	for _, e := range entries {
+		e.timer.Stop()
+		d.report(e)
+	}
+}
+
+func (d *deduper) report(e *dedupEntry) {
+	if e.count == 0 {
+		return
+	}
+	event := e.first
+	occurrences := e.count + e.sent
+	event.Err = errors.WrapWithSkip(e.first.Err, fmt.Sprintf("%d occurrences in %s", occurrences, time.Since(e.start).Round(time.Second)), 1)
+	event.Data = make(map[string]interface{}, len(e.first.Data)+2)
+	for k, v := range e.first.Data {
+		event.Data[k] = v
+	}
+	event.Data["occurrences"] = occurrences
+	event.Data["suppressed"] = e.count
+	event.Tags = make(map[string]string, len(e.first.Tags)+1)
+	for k, v := range e.first.Tags {
+		event.Tags[k] = v
+	}
+	event.Tags["dedup"] = "aggregated"
+	dispatch(event, false)
+}
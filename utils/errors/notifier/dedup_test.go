@@ -0,0 +1,71 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/carousell/Orion/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func failing(code codes.Code) error {
+	return errors.NewWithGRPCCode("upstream unavailable", code)
+}
+
+func TestDedup(t *testing.T) {
+	mem := NewMemoryReporter("memory")
+	RegisterReporter(mem)
+	defer UnregisterReporter("memory")
+	SetDedup(DedupConfig{Enabled: true, Window: 50 * time.Millisecond})
+	defer SetDedup(DedupConfig{})
+
+	for i := 0; i < 5; i++ {
+		Notify(failing(codes.Unavailable))
+	}
+	// a different gRPC code is not a duplicate
+	Notify(failing(codes.Internal))
+	assert.Len(t, mem.Events(), 2)
+
+	// duplicates are reported as a single notice once the window ends
+	assert.Eventually(t, func() bool { return len(mem.Events()) == 3 }, time.Second, 10*time.Millisecond)
+	notice := mem.Events()[2]
+	assert.Equal(t, 5, notice.Data["occurrences"])
+	assert.Equal(t, 4, notice.Data["suppressed"])
+	assert.Equal(t, "aggregated", notice.Tags["dedup"])
+	assert.Contains(t, notice.Err.Error(), "5 occurrences in")
+	assert.Contains(t, notice.Err.Error(), "upstream unavailable")
+
+	// a new window starts after the notice
+	Notify(failing(codes.Unavailable))
+	assert.Len(t, mem.Events(), 4)
+}
+
+func TestDedupBurstAndFlush(t *testing.T) {
+	mem := NewMemoryReporter("memory")
+	RegisterReporter(mem)
+	defer UnregisterReporter("memory")
+	SetDedup(DedupConfig{Enabled: true, Window: time.Hour, Burst: 2})
+	defer SetDedup(DedupConfig{})
+
+	for i := 0; i < 4; i++ {
+		Notify(failing(codes.Unavailable))
+	}
+	assert.Len(t, mem.Events(), 2)
+
+	// pending occurrences are reported on flush
+	Flush(time.Second)
+	events := mem.Events()
+	if assert.Len(t, events, 3) {
+		assert.Equal(t, 4, events[2].Data["occurrences"])
+		assert.Equal(t, 2, events[2].Data["suppressed"])
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := errors.New("a").(errors.ErrorExt)
+	b := errors.New("b").(errors.ErrorExt)
+	// same cause type, different call sites
+	assert.NotEqual(t, Fingerprint(a, 3), Fingerprint(b, 3))
+	assert.Equal(t, Fingerprint(a, 0), Fingerprint(b, 0))
+}
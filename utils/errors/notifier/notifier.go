@@ -2,11 +2,14 @@ package notifier
 
 import (
 	"context"
+	"io"
+	"net/http"
 	"os"
 	"reflect"
-	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	bugsnag "github.com/bugsnag/bugsnag-go"
 	"github.com/carousell/Orion/utils/errors"
@@ -14,20 +17,18 @@ import (
 	"github.com/carousell/Orion/utils/log"
 	"github.com/carousell/Orion/utils/log/loggers"
 	"github.com/carousell/Orion/utils/options"
-	raven "github.com/getsentry/raven-go"
+	"github.com/getsentry/sentry-go"
 	stdopentracing "github.com/opentracing/opentracing-go"
 	"github.com/pborman/uuid"
 	"github.com/stvp/rollbar"
-	gobrake "gopkg.in/airbrake/gobrake.v2"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 var (
-	airbrake      *gobrake.Notifier
-	bugsnagInited bool
-	rollbarInited bool
-	sentryInited  bool
-	serverRoot    string
-	hostname      string
+	serverRoot  string
+	hostname    string
+	environment string
+	envMu       sync.RWMutex
 )
 
 const (
@@ -47,39 +48,45 @@ func (tags Tags) value() map[string]string {
 	return map[string]string(tags)
 }
 
-// InitAirbrake inits airbrake configuration
-func InitAirbrake(projectID int64, projectKey string) {
-	airbrake = gobrake.NewNotifier(projectID, projectKey)
+// InitAirbrake inits airbrake configuration and registers it as a reporter
+func InitAirbrake(projectID int64, projectKey string, opts ...ReporterOption) {
+	RegisterReporter(NewAirbrakeReporter(projectID, projectKey), opts...)
 }
 
-//InitBugsnag inits bugsnag configuration
-func InitBugsnag(config bugsnag.Configuration) {
-	bugsnag.Configure(config)
-	bugsnagInited = true
+//InitBugsnag inits bugsnag configuration and registers it as a reporter
+func InitBugsnag(config bugsnag.Configuration, opts ...ReporterOption) {
+	RegisterReporter(NewBugsnagReporter(config), opts...)
 }
 
-//InitRollbar inits rollbar configuration
-func InitRollbar(token, env string) {
-	rollbar.Token = token
-	rollbar.Environment = env
-	rollbarInited = true
+//InitRollbar inits rollbar configuration and registers it as a reporter
+func InitRollbar(token, env string, opts ...ReporterOption) {
+	RegisterReporter(NewRollbarReporter(token, env), opts...)
 }
 
-func InitSentry(dsn string) {
-	raven.SetDSN(dsn)
-	sentryInited = true
+//InitSentry inits sentry configuration and registers it as a reporter, logs made in a request scope
+//(see SetRequestScope) are recorded as breadcrumbs
+func InitSentry(dsn string, opts ...ReporterOption) {
+	err := InitSentryWithOptions(sentry.ClientOptions{
+		Dsn:         dsn,
+		Environment: getEnvironment(),
+		Release:     getRelease(),
+	}, opts...)
+	if err != nil {
+		log.Error(context.Background(), "msg", "could not init sentry", "error", err)
+	}
 }
 
-func convToGoBrake(in []errors.StackFrame) []gobrake.StackFrame {
-	out := make([]gobrake.StackFrame, 0)
-	for _, s := range in {
-		out = append(out, gobrake.StackFrame{
-			File: s.File,
-			Func: s.Func,
-			Line: s.Line,
-		})
-	}
-	return out
+//InitSentryWithOptions inits sentry using the provided client options and registers it as a reporter
+func InitSentryWithOptions(options sentry.ClientOptions, opts ...ReporterOption) error {
+	r, err := NewSentryReporterWithOptions(options)
+	if err != nil {
+		return err
+	}
+	// request scopes are cloned from the current hub
+	sentry.CurrentHub().BindClient(r.(*sentryReporter).client)
+	enableBreadcrumbs()
+	RegisterReporter(r, opts...)
+	return nil
 }
 
 func convToRollbar(in []errors.StackFrame) rollbar.Stack {
@@ -94,34 +101,6 @@ func convToRollbar(in []errors.StackFrame) rollbar.Stack {
 	return out
 }
 
-func convToSentry(in errors.ErrorExt) *raven.Stacktrace {
-	out := new(raven.Stacktrace)
-	pcs := in.Callers()
-	frames := make([]*raven.StacktraceFrame, 0)
-
-	callersFrames := runtime.CallersFrames(pcs)
-
-	for {
-		fr, more := callersFrames.Next()
-		if fr.Func != nil {
-			frame := raven.NewStacktraceFrame(fr.PC, fr.Function, fr.File, fr.Line, 3, []string{})
-			if frame != nil {
-				frame.InApp = true
-				frames = append(frames, frame)
-			}
-		}
-		if !more {
-			break
-		}
-	}
-	for i := len(frames)/2 - 1; i >= 0; i-- {
-		opp := len(frames) - 1 - i
-		frames[i], frames[opp] = frames[opp], frames[i]
-	}
-	out.Frames = frames
-	return out
-}
-
 func parseRawData(ctx context.Context, rawData ...interface{}) (extraData map[string]interface{}, tagData []map[string]string) {
 	extraData = make(map[string]interface{})
 
@@ -141,9 +120,64 @@ func parseRawData(ctx context.Context, rawData ...interface{}) (extraData map[st
 			extraData[k] = v
 		}
 	}
+	// reporters never see sensitive data
+	scrubber := loggers.GetScrubber()
+	extraData = scrubber.Fields(extraData)
+	for i := range tagData {
+		tagData[i] = scrubber.Value("", tagData[i]).(map[string]string)
+	}
 	return
 }
 
+// reporterPackages are the import paths of reporter SDKs, their values in rawData configure the SDK
+// e.g. bugsnag.User or bugsnag.ErrorClass
+var reporterPackages = []string{
+	"github.com/bugsnag/",
+	"github.com/getsentry/",
+	"github.com/stvp/rollbar",
+	"gopkg.in/airbrake/",
+}
+
+// isReporterValue returns true when data is a value of a reporter SDK
+func isReporterValue(data interface{}) bool {
+	t := reflect.TypeOf(data)
+	if t == nil {
+		return false
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for _, pkg := range reporterPackages {
+		if strings.HasPrefix(t.PkgPath(), pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// scrubRaw masks sensitive data in rawData passed as is to reporters, contexts, requests and values of
+// reporter SDKs are kept as is since reporters recognise them by their type
+func scrubRaw(rawData []interface{}) []interface{} {
+	scrubber := loggers.GetScrubber()
+	if scrubber == nil {
+		return rawData
+	}
+	scrubbed := make([]interface{}, len(rawData))
+	for i, data := range rawData {
+		switch data.(type) {
+		case context.Context, isTags, *http.Request:
+			scrubbed[i] = data
+		default:
+			if isReporterValue(data) {
+				scrubbed[i] = data
+			} else {
+				scrubbed[i] = scrubber.Value("", data)
+			}
+		}
+	}
+	return scrubbed
+}
+
 func Notify(err error, rawData ...interface{}) error {
 	return NotifyWithLevelAndSkip(err, 2, rollbar.ERR, rawData...)
 }
@@ -201,7 +235,9 @@ func doNotify(err error, skip int, level string, rawData ...interface{}) error {
 	ctx := context.Background()
 	for _, d := range list {
 		if c, ok := d.(context.Context); ok {
-			if span := stdopentracing.SpanFromContext(c); span != nil {
+			if sc := oteltrace.SpanContextFromContext(c); sc.HasTraceID() {
+				traceID = sc.TraceID().String()
+			} else if span := stdopentracing.SpanFromContext(c); span != nil {
 				traceID = span.BaggageItem("trace")
 			}
 			if strings.TrimSpace(traceID) == "" {
@@ -212,51 +248,19 @@ func doNotify(err error, skip int, level string, rawData ...interface{}) error {
 		}
 	}
 
-	if airbrake != nil {
-		var n *gobrake.Notice
-		n = gobrake.NewNotice(errWithStack, nil, 1)
-		n.Errors[0].Backtrace = convToGoBrake(errWithStack.StackFrame())
-		if len(list) > 0 {
-			m, _ := parseRawData(ctx, list...)
-			for k, v := range m {
-				n.Context[k] = v
-			}
-		}
-		if traceID != "" {
-			n.Context["traceId"] = traceID
-		}
-		airbrake.SendNoticeAsync(n)
-	}
-
-	if bugsnagInited {
-		bugsnag.Notify(errWithStack, list...)
-	}
 	parsedData, tagData := parseRawData(ctx, list...)
-	if rollbarInited {
-		fields := []*rollbar.Field{}
-		if len(list) > 0 {
-			for k, v := range parsedData {
-				fields = append(fields, &rollbar.Field{Name: k, Data: v})
-			}
-		}
-		if traceID != "" {
-			fields = append(fields, &rollbar.Field{Name: "traceId", Data: traceID})
-		}
-		fields = append(fields, &rollbar.Field{Name: "server", Data: map[string]interface{}{"hostname": getHostname(), "root": getServerRoot()}})
-		rollbar.ErrorWithStack(sev.String(), errWithStack, convToRollbar(errWithStack.StackFrame()), fields...)
+	event := Event{
+		Err:      errWithStack,
+		Severity: sev.String(),
+		Context:  ctx,
+		TraceID:  traceID,
+		Data:     parsedData,
+		Tags:     mergeTags(tagData),
+		Raw:      scrubRaw(list),
 	}
-
-	if sentryInited {
-		ravenExp := raven.NewException(errWithStack, convToSentry(errWithStack))
-		packet := raven.NewPacketWithExtra(errWithStack.Error(), parsedData, ravenExp)
-
-		for _, tags := range tagData {
-			packet.AddTags(tags)
-		}
-
-		// type assert directly since it's single use case so we don't consider about wrapping it for now
-		packet.Level = raven.Severity(sev)
-		raven.Capture(packet, nil)
+	// duplicates are counted and reported once their dedup window ends
+	if dedup.allow(event) {
+		dispatch(event, false)
 	}
 
 	log.GetLogger().Log(ctx, sev.LoggerLevel(), skip+1, "err", errWithStack, "stack", errWithStack.StackFrame())
@@ -289,13 +293,18 @@ func NotifyWithExclude(err error, rawData ...interface{}) error {
 }
 
 func NotifyOnPanic(rawData ...interface{}) {
-	if bugsnagInited {
-		defer bugsnag.AutoNotify(rawData...)
-	}
-	if airbrake != nil {
-		defer airbrake.NotifyOnPanic()
+	if r := recover(); r != nil {
+		panic(notifyPanic(r, 2, rawData...))
 	}
+}
 
+//NotifyPanic reports a recovered panic value r along with rawData and returns it as an error, reporters
+//receive it synchronously with Panic set
+func NotifyPanic(r interface{}, rawData ...interface{}) error {
+	return notifyPanic(r, 2, rawData...)
+}
+
+func notifyPanic(r interface{}, skip int, rawData ...interface{}) errors.ErrorExt {
 	ctx := context.Background()
 	for _, d := range rawData {
 		if c, ok := d.(context.Context); ok {
@@ -303,50 +312,67 @@ func NotifyOnPanic(rawData ...interface{}) {
 			break
 		}
 	}
-	if r := recover(); r != nil {
-		var e errors.ErrorExt
-		switch val := r.(type) {
-		case error:
-			e = errors.WrapWithSkip(val, "PANIC", 1)
-		case string:
-			e = errors.NewWithSkip("PANIC: "+val, 1)
-		default:
-			e = errors.NewWithSkip("Panic", 1)
-		}
-		parsedData, tagData := parseRawData(ctx, rawData...)
-		if rollbarInited {
-			rollbar.ErrorWithStack(rollbar.CRIT, e, convToRollbar(e.StackFrame()), &rollbar.Field{Name: "panic", Data: r})
-		}
-		if sentryInited {
-			ravenExp := raven.NewException(e, convToSentry(e))
-			packet := raven.NewPacketWithExtra(e.Error(), parsedData, ravenExp)
-
-			for _, tags := range tagData {
-				packet.AddTags(tags)
-			}
+	var e errors.ErrorExt
+	switch val := r.(type) {
+	case error:
+		e = errors.WrapWithSkip(val, "PANIC", skip)
+	case string:
+		e = errors.NewWithSkip("PANIC: "+val, skip)
+	default:
+		e = errors.NewWithSkip("Panic", skip)
+	}
+	parsedData, tagData := parseRawData(ctx, rawData...)
+	// deliver synchronously, the process may not survive this panic
+	dispatch(Event{
+		Err:      e,
+		Severity: FatalLevel,
+		Context:  ctx,
+		TraceID:  GetTraceId(ctx),
+		Data:     parsedData,
+		Tags:     mergeTags(tagData),
+		Raw:      scrubRaw(rawData),
+		Panic:    r,
+	}, true)
+	return e
+}
 
-			packet.Level = raven.FATAL
-			raven.Capture(packet, nil)
+func mergeTags(tagData []map[string]string) map[string]string {
+	if len(tagData) == 0 {
+		return nil
+	}
+	tags := make(map[string]string)
+	for _, t := range tagData {
+		for k, v := range t {
+			tags[k] = v
 		}
-		panic(e)
 	}
+	return tags
 }
 
+//Close flushes all reporters and releases their resources, reporters are unregistered
 func Close() {
-	if airbrake != nil {
-		airbrake.Close()
+	Flush(5 * time.Second)
+	for _, name := range Reporters() {
+		r := getReporter(name)
+		UnregisterReporter(name)
+		if c, ok := r.(io.Closer); ok {
+			c.Close()
+		}
 	}
 }
 
+//SetEnvironemnt sets the environment reported along with errors, it must be called before InitSentry
 func SetEnvironemnt(env string) {
-	if airbrake != nil {
-		airbrake.AddFilter(func(notice *gobrake.Notice) *gobrake.Notice {
-			notice.Context["environment"] = env
-			return notice
-		})
-	}
+	envMu.Lock()
+	environment = env
+	envMu.Unlock()
 	rollbar.Environment = env
-	raven.SetEnvironment(env)
+}
+
+func getEnvironment() string {
+	envMu.RLock()
+	defer envMu.RUnlock()
+	return environment
 }
 
 //SetTraceId updates the traceID based on context values
@@ -355,7 +381,9 @@ func SetTraceId(ctx context.Context) context.Context {
 		return ctx
 	}
 	var traceID string
-	if span := stdopentracing.SpanFromContext(ctx); span != nil {
+	if sc := oteltrace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		traceID = sc.TraceID().String()
+	} else if span := stdopentracing.SpanFromContext(ctx); span != nil {
 		traceID = span.BaggageItem("trace")
 	}
 	// if no trace id then create one
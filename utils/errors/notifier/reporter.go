@@ -0,0 +1,393 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/carousell/Orion/utils/errors"
+	"github.com/carousell/Orion/utils/log"
+)
+
+const (
+	//DefaultQueueSize is the queue size of async reporters when none is provided
+	DefaultQueueSize = 1000
+	//DefaultBatchSize is the batch size of async reporters when none is provided
+	DefaultBatchSize = 10
+	//DefaultFlushInterval is the longest an event waits in a partial batch when none is provided
+	DefaultFlushInterval = time.Second
+)
+
+var (
+	reportersMu sync.RWMutex
+	reporters   []*registeredReporter
+
+	severityRank = map[severity]int{
+		debugSeverity:   0,
+		infoSeverity:    1,
+		warningSeverity: 2,
+		errorSeverity:   3,
+		fatalSeverity:   4,
+	}
+)
+
+//Event is an error notification delivered to reporters
+type Event struct {
+	// Err is the reported error along with its stack
+	Err errors.ErrorExt
+	// Severity is one of ErrorLevel, WarningLevel, InfoLevel, DebugLevel or FatalLevel
+	Severity string
+	// Context is the context passed along with the error, or context.Background
+	Context context.Context
+	// TraceID is the trace id of the request the error belongs to
+	TraceID string
+	// Data is the extra data passed along with the error and the log fields of Context
+	Data map[string]interface{}
+	// Tags are the Tags passed along with the error
+	Tags map[string]string
+	// Raw is the data passed along with the error as is
+	Raw []interface{}
+	// Panic is the recovered value when the event is reported by NotifyOnPanic
+	Panic interface{}
+}
+
+//Reporter delivers events to an error reporting backend
+type Reporter interface {
+	// Name identifies the reporter in the registry, registering a reporter with the same name replaces it
+	Name() string
+	Report(event Event) error
+}
+
+//BatchReporter is implemented by reporters that can deliver many events at once, it is used by async reporters
+type BatchReporter interface {
+	Reporter
+	ReportBatch(events []Event) error
+}
+
+//Flusher is implemented by reporters that buffer events
+type Flusher interface {
+	Flush(timeout time.Duration)
+}
+
+//ReporterOption configures how events are delivered to a reporter
+type ReporterOption func(*reporterOptions)
+
+type reporterOptions struct {
+	minSeverity   severity
+	filters       []func(Event) bool
+	async         bool
+	queueSize     int
+	batchSize     int
+	flushInterval time.Duration
+}
+
+//WithMinSeverity only delivers events at or above level (DebugLevel < InfoLevel < WarningLevel < ErrorLevel < FatalLevel)
+func WithMinSeverity(level string) ReporterOption {
+	return func(o *reporterOptions) {
+		o.minSeverity = parseLevel(level)
+	}
+}
+
+//WithFilter only delivers events for which filter returns true
+func WithFilter(filter func(Event) bool) ReporterOption {
+	return func(o *reporterOptions) {
+		if filter != nil {
+			o.filters = append(o.filters, filter)
+		}
+	}
+}
+
+//WithAsync delivers events from a bounded queue in batches of up to batchSize, waiting at most flushInterval
+//for a batch to fill up, events are dropped when the queue is full
+func WithAsync(queueSize, batchSize int, flushInterval time.Duration) ReporterOption {
+	return func(o *reporterOptions) {
+		o.async = true
+		o.queueSize = queueSize
+		o.batchSize = batchSize
+		o.flushInterval = flushInterval
+	}
+}
+
+type registeredReporter struct {
+	reporter Reporter
+	opts     reporterOptions
+
+	queue   chan Event
+	flushes chan chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	dropped uint64
+}
+
+func (r *registeredReporter) accept(event Event) bool {
+	if severityRank[parseLevel(event.Severity)] < severityRank[r.opts.minSeverity] {
+		return false
+	}
+	for _, filter := range r.opts.filters {
+		if !filter(event) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *registeredReporter) deliver(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	if batch, ok := r.reporter.(BatchReporter); ok && len(events) > 1 {
+		if err := batch.ReportBatch(events); err != nil {
+			log.Warn(context.Background(), "reporter", r.reporter.Name(), "msg", "could not report errors", "error", err)
+		}
+		return
+	}
+	for _, event := range events {
+		if err := r.reporter.Report(event); err != nil {
+			log.Warn(context.Background(), "reporter", r.reporter.Name(), "msg", "could not report error", "error", err)
+		}
+	}
+}
+
+func (r *registeredReporter) report(event Event, sync bool) {
+	if !r.accept(event) {
+		return
+	}
+	if !r.opts.async || sync {
+		r.deliver([]Event{event})
+		return
+	}
+	select {
+	case r.queue <- event:
+	default:
+		if atomic.AddUint64(&r.dropped, 1)%100 == 1 {
+			log.Warn(context.Background(), "reporter", r.reporter.Name(), "msg", "queue full, dropping errors",
+				"dropped", atomic.LoadUint64(&r.dropped))
+		}
+	}
+}
+
+func (r *registeredReporter) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.opts.flushInterval)
+	defer ticker.Stop()
+	batch := make([]Event, 0, r.opts.batchSize)
+	drain := func() {
+		for {
+			select {
+			case event := <-r.queue:
+				batch = append(batch, event)
+				if len(batch) >= r.opts.batchSize {
+					r.deliver(batch)
+					batch = batch[:0]
+				}
+			default:
+				r.deliver(batch)
+				batch = batch[:0]
+				return
+			}
+		}
+	}
+	for {
+		select {
+		case event := <-r.queue:
+			batch = append(batch, event)
+			if len(batch) >= r.opts.batchSize {
+				r.deliver(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			r.deliver(batch)
+			batch = batch[:0]
+		case flushed := <-r.flushes:
+			drain()
+			close(flushed)
+		case <-r.stop:
+			drain()
+			return
+		}
+	}
+}
+
+func (r *registeredReporter) flush(timeout time.Duration) {
+	deadline := time.After(timeout)
+	if r.opts.async {
+		flushed := make(chan struct{})
+		select {
+		case r.flushes <- flushed:
+			select {
+			case <-flushed:
+			case <-deadline:
+				return
+			}
+		case <-r.done:
+		case <-deadline:
+			return
+		}
+	}
+	if f, ok := r.reporter.(Flusher); ok {
+		f.Flush(timeout)
+	}
+}
+
+func (r *registeredReporter) close() {
+	if r.opts.async {
+		close(r.stop)
+		<-r.done
+	}
+}
+
+//RegisterReporter adds a reporter that receives all notified errors, a reporter registered earlier
+//with the same name is flushed and replaced
+func RegisterReporter(reporter Reporter, opts ...ReporterOption) {
+	if reporter == nil {
+		return
+	}
+	r := &registeredReporter{
+		reporter: reporter,
+		opts:     reporterOptions{minSeverity: debugSeverity},
+	}
+	for _, opt := range opts {
+		opt(&r.opts)
+	}
+	if r.opts.async {
+		if r.opts.queueSize <= 0 {
+			r.opts.queueSize = DefaultQueueSize
+		}
+		if r.opts.batchSize <= 0 {
+			r.opts.batchSize = DefaultBatchSize
+		}
+		if r.opts.flushInterval <= 0 {
+			r.opts.flushInterval = DefaultFlushInterval
+		}
+		r.queue = make(chan Event, r.opts.queueSize)
+		r.flushes = make(chan chan struct{})
+		r.stop = make(chan struct{})
+		r.done = make(chan struct{})
+		go r.run()
+	}
+
+	reportersMu.Lock()
+	var old *registeredReporter
+	for i, existing := range reporters {
+		if existing.reporter.Name() == reporter.Name() {
+			old = existing
+			reporters[i] = r
+			break
+		}
+	}
+	if old == nil {
+		reporters = append(reporters, r)
+	}
+	reportersMu.Unlock()
+
+	if old != nil {
+		old.close()
+	}
+}
+
+//UnregisterReporter removes the reporter registered with name, pending events are delivered first
+func UnregisterReporter(name string) {
+	reportersMu.Lock()
+	var old *registeredReporter
+	for i, existing := range reporters {
+		if existing.reporter.Name() == name {
+			old = existing
+			reporters = append(reporters[:i:i], reporters[i+1:]...)
+			break
+		}
+	}
+	reportersMu.Unlock()
+	if old != nil {
+		old.close()
+	}
+}
+
+//Reporters returns the names of all registered reporters
+func Reporters() []string {
+	reportersMu.RLock()
+	defer reportersMu.RUnlock()
+	names := make([]string, 0, len(reporters))
+	for _, r := range reporters {
+		names = append(names, r.reporter.Name())
+	}
+	return names
+}
+
+func getReporter(name string) Reporter {
+	reportersMu.RLock()
+	defer reportersMu.RUnlock()
+	for _, r := range reporters {
+		if r.reporter.Name() == name {
+			return r.reporter
+		}
+	}
+	return nil
+}
+
+// dispatch delivers event to all registered reporters, sync bypasses async queues
+func dispatch(event Event, sync bool) {
+	reportersMu.RLock()
+	current := append([]*registeredReporter{}, reporters...)
+	reportersMu.RUnlock()
+	for _, r := range current {
+		r.report(event, sync)
+	}
+}
+
+//Flush reports pending duplicate counts, delivers all queued events and flushes reporters, waiting at most
+//timeout for each reporter
+func Flush(timeout time.Duration) {
+	dedup.flush()
+	reportersMu.RLock()
+	current := append([]*registeredReporter{}, reporters...)
+	reportersMu.RUnlock()
+	var wg sync.WaitGroup
+	for _, r := range current {
+		wg.Add(1)
+		go func(r *registeredReporter) {
+			defer wg.Done()
+			r.flush(timeout)
+		}(r)
+	}
+	wg.Wait()
+}
+
+//MemoryReporter keeps reported events in memory, it is meant for tests
+type MemoryReporter struct {
+	name   string
+	mu     sync.Mutex
+	events []Event
+}
+
+//NewMemoryReporter creates a new MemoryReporter
+func NewMemoryReporter(name string) *MemoryReporter {
+	return &MemoryReporter{name: name}
+}
+
+//Name returns the name of this reporter
+func (m *MemoryReporter) Name() string {
+	return m.name
+}
+
+//Report stores event
+func (m *MemoryReporter) Report(event Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+	return nil
+}
+
+//Events returns all events reported so far
+func (m *MemoryReporter) Events() []Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Event{}, m.events...)
+}
+
+//Reset drops all events reported so far
+func (m *MemoryReporter) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = nil
+}
@@ -0,0 +1,175 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/carousell/Orion/utils/errors"
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/stretchr/testify/assert"
+)
+
+type batchReporter struct {
+	*MemoryReporter
+	mu      sync.Mutex
+	batches [][]Event
+}
+
+func (b *batchReporter) ReportBatch(events []Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.batches = append(b.batches, events)
+	return nil
+}
+
+func TestReporter(t *testing.T) {
+	mem := NewMemoryReporter("memory")
+	RegisterReporter(mem)
+	defer UnregisterReporter("memory")
+	assert.Contains(t, Reporters(), "memory")
+
+	ctx := loggers.AddToLogContext(context.Background(), "trace", "abc")
+	err := errors.New("boom")
+	assert.Equal(t, err, NotifyWithLevel(err, WarningLevel, ctx, Tags{"team": "core"}, "extra"))
+
+	events := mem.Events()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, err, events[0].Err)
+		assert.Equal(t, WarningLevel, events[0].Severity)
+		assert.Equal(t, "abc", events[0].TraceID)
+		assert.Equal(t, "core", events[0].Tags["team"])
+		assert.Equal(t, "abc", events[0].Data["trace"])
+		assert.Equal(t, "extra", events[0].Data["string2"])
+	}
+
+	// errors reported once are not reported again
+	Notify(err)
+	assert.Len(t, mem.Events(), 1)
+}
+
+func TestReporterScrubbed(t *testing.T) {
+	mem := NewMemoryReporter("memory")
+	RegisterReporter(mem)
+	defer UnregisterReporter("memory")
+
+	ctx := loggers.AddToLogContext(context.Background(), "authorization", "Bearer abc")
+	ctx = loggers.AddToLogContext(ctx, "path", "/pay")
+	Notify(errors.New("payment failed"), ctx, Tags{"email": "someone@carousell.com"}, "card 4111 1111 1111 1111",
+		map[string]interface{}{"password": "hunter2"})
+
+	events := mem.Events()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, loggers.DefaultScrubMask, events[0].Data["authorization"])
+		assert.Equal(t, "/pay", events[0].Data["path"])
+		assert.Equal(t, "card "+loggers.DefaultScrubMask, events[0].Data["string2"])
+		assert.Equal(t, loggers.DefaultScrubMask, events[0].Tags["email"])
+		assert.Equal(t, "card "+loggers.DefaultScrubMask, events[0].Raw[2])
+		assert.Equal(t, map[string]interface{}{"password": loggers.DefaultScrubMask}, events[0].Raw[3])
+	}
+	// log fields of the request are not modified
+	assert.Equal(t, "Bearer abc", loggers.FromContext(ctx)["authorization"])
+}
+
+func TestBugsnagRawNotScrubbed(t *testing.T) {
+	var captured *bugsnag.Event
+	bugsnag.OnBeforeNotify(func(event *bugsnag.Event, config *bugsnag.Configuration) error {
+		captured = event
+		// never send test events
+		return errors.New("not sent")
+	})
+	RegisterReporter(NewBugsnagReporter(bugsnag.Configuration{
+		APIKey:              "00000000000000000000000000000000",
+		AutoCaptureSessions: false,
+		PanicHandler:        func() {},
+	}))
+	defer UnregisterReporter(BugsnagReporter)
+
+	req := httptest.NewRequest(http.MethodGet, "/pay", nil)
+	Notify(errors.New("payment failed"), bugsnag.User{Id: "1", Email: "someone@carousell.com"},
+		bugsnag.Context{String: "checkout"}, bugsnag.ErrorClass{Name: "PaymentError"}, bugsnag.SeverityWarning,
+		req, map[string]interface{}{"password": "hunter2"})
+
+	if assert.NotNil(t, captured) {
+		assert.Equal(t, &bugsnag.User{Id: "1", Email: "someone@carousell.com"}, captured.User)
+		assert.Equal(t, "checkout", captured.Context)
+		// bugsnag sets the class of the error after the one passed in rawData
+		assert.Contains(t, captured.RawData, bugsnag.ErrorClass{Name: "PaymentError"})
+		assert.Equal(t, bugsnag.SeverityWarning, captured.Severity)
+		assert.Equal(t, "http://example.com/pay", captured.Request.URL)
+		assert.Contains(t, captured.RawData, map[string]interface{}{"password": loggers.DefaultScrubMask})
+	}
+}
+
+func TestReporterSeverityAndFilter(t *testing.T) {
+	mem := NewMemoryReporter("memory")
+	RegisterReporter(mem, WithMinSeverity(ErrorLevel), WithFilter(func(e Event) bool {
+		return e.Tags["ignore"] == ""
+	}))
+	defer UnregisterReporter("memory")
+
+	NotifyWithLevel(errors.New("info"), InfoLevel)
+	NotifyWithLevel(errors.New("ignored"), ErrorLevel, Tags{"ignore": "yes"})
+	NotifyWithLevel(errors.New("critical"), "critical")
+	Notify(errors.New("error"))
+
+	events := mem.Events()
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "critical", events[0].Err.Error())
+		assert.Equal(t, "error", events[1].Err.Error())
+	}
+}
+
+func TestReporterAsync(t *testing.T) {
+	b := &batchReporter{MemoryReporter: NewMemoryReporter("batch")}
+	RegisterReporter(b, WithAsync(100, 10, time.Hour))
+	defer UnregisterReporter("batch")
+
+	for i := 0; i < 5; i++ {
+		Notify(errors.New("queued"))
+	}
+	// nothing is delivered until the batch is full or flushed
+	b.mu.Lock()
+	assert.Empty(t, b.batches)
+	b.mu.Unlock()
+
+	Flush(time.Second)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if assert.Len(t, b.batches, 1) {
+		assert.Len(t, b.batches[0], 5)
+	}
+	assert.Empty(t, b.Events())
+}
+
+func TestReporterReplace(t *testing.T) {
+	first, second := NewMemoryReporter("memory"), NewMemoryReporter("memory")
+	RegisterReporter(first)
+	RegisterReporter(second)
+	defer UnregisterReporter("memory")
+
+	Notify(errors.New("boom"))
+	assert.Empty(t, first.Events())
+	assert.Len(t, second.Events(), 1)
+}
+
+func TestNotifyOnPanic(t *testing.T) {
+	mem := NewMemoryReporter("memory")
+	RegisterReporter(mem, WithAsync(10, 10, time.Hour))
+	defer UnregisterReporter("memory")
+
+	assert.Panics(t, func() {
+		defer NotifyOnPanic(context.Background())
+		panic("oops")
+	})
+	// panics are delivered before re-panicking, even for async reporters
+	events := mem.Events()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, FatalLevel, events[0].Severity)
+		assert.Equal(t, "oops", events[0].Panic)
+	}
+}
@@ -0,0 +1,254 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carousell/Orion/utils/errors"
+	"github.com/carousell/Orion/utils/log"
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/getsentry/sentry-go"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	//UserIDKey is the metadata/header key read for the user reported along with errors
+	UserIDKey = "x-user-id"
+	//SessionIDKey is the metadata/header key read for the session reported along with errors
+	SessionIDKey = "x-session-id"
+)
+
+var (
+	release   string
+	releaseMu sync.RWMutex
+)
+
+//SetRelease sets the release reported by sentry, it must be called before InitSentry
+func SetRelease(r string) {
+	releaseMu.Lock()
+	defer releaseMu.Unlock()
+	release = r
+}
+
+func getRelease() string {
+	releaseMu.RLock()
+	defer releaseMu.RUnlock()
+	return release
+}
+
+type sentryReporter struct {
+	client *sentry.Client
+	hub    *sentry.Hub
+}
+
+//NewSentryReporter creates a Reporter that sends events to sentry, release and environment are taken from
+//SetRelease and SetEnvironemnt
+func NewSentryReporter(dsn string) (Reporter, error) {
+	return NewSentryReporterWithOptions(sentry.ClientOptions{
+		Dsn:         dsn,
+		Environment: getEnvironment(),
+		Release:     getRelease(),
+	})
+}
+
+//NewSentryReporterWithOptions creates a Reporter that sends events to sentry using the provided client options
+func NewSentryReporterWithOptions(options sentry.ClientOptions) (Reporter, error) {
+	client, err := sentry.NewClient(options)
+	if err != nil {
+		return nil, err
+	}
+	return &sentryReporter{
+		client: client,
+		hub:    sentry.NewHub(client, sentry.NewScope()),
+	}, nil
+}
+
+func (s *sentryReporter) Name() string {
+	return SentryReporter
+}
+
+func (s *sentryReporter) Report(event Event) error {
+	hub := s.hub
+	if event.Context != nil {
+		if h := sentry.GetHubFromContext(event.Context); h != nil && h.Client() != nil {
+			hub = h
+		}
+	}
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTags(event.Tags)
+		if event.TraceID != "" {
+			scope.SetTag("traceId", event.TraceID)
+		}
+		scope.SetExtras(event.Data)
+		level := sentry.Level(parseLevel(event.Severity))
+		if event.Panic != nil {
+			level = sentry.LevelFatal
+			scope.SetTag("panic", "true")
+		}
+		scope.SetLevel(level)
+		e := sentry.NewEvent()
+		e.Level = level
+		e.Exception = []sentry.Exception{{
+			Type:       reflect.TypeOf(event.Err.Cause()).String(),
+			Value:      event.Err.Error(),
+			Stacktrace: sentryStacktrace(event.Err),
+		}}
+		hub.CaptureEvent(e)
+	})
+	return nil
+}
+
+func (s *sentryReporter) Flush(timeout time.Duration) {
+	s.client.Flush(timeout)
+}
+
+func sentryStacktrace(err errors.ErrorExt) *sentry.Stacktrace {
+	frames := make([]sentry.Frame, 0)
+	callersFrames := runtime.CallersFrames(err.Callers())
+	for {
+		fr, more := callersFrames.Next()
+		if fr.Function != "" {
+			frames = append(frames, sentry.NewFrame(fr))
+		}
+		if !more {
+			break
+		}
+	}
+	// sentry expects the oldest frame first
+	for i := len(frames)/2 - 1; i >= 0; i-- {
+		opp := len(frames) - 1 - i
+		frames[i], frames[opp] = frames[opp], frames[i]
+	}
+	return &sentry.Stacktrace{Frames: frames}
+}
+
+//SetRequestScope creates a sentry hub for the request in ctx, errors and breadcrumbs of this request are
+//reported with the trace id, method, user and session of the request
+func SetRequestScope(ctx context.Context, method string) context.Context {
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil {
+		if sentry.CurrentHub().Client() == nil {
+			// sentry is not configured
+			return ctx
+		}
+		hub = sentry.CurrentHub().Clone()
+		ctx = sentry.SetHubOnContext(ctx, hub)
+	}
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		if method != "" {
+			scope.SetTag("method", method)
+		}
+		if traceID := GetTraceId(ctx); traceID != "" {
+			scope.SetTag("traceId", traceID)
+		}
+		user := sentry.User{}
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			user.ID = firstValue(md.Get(UserIDKey))
+			if session := firstValue(md.Get(SessionIDKey)); session != "" {
+				scope.SetTag("session", session)
+			}
+		}
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			user.IPAddress = hostOnly(p.Addr.String())
+		}
+		if user.ID != "" {
+			scope.SetUser(user)
+		}
+	})
+	return ctx
+}
+
+//SetHTTPRequestScope creates a sentry hub for the http request in ctx, similar to SetRequestScope, the request
+//is reported along with errors
+func SetHTTPRequestScope(ctx context.Context, req *http.Request) context.Context {
+	ctx = SetRequestScope(ctx, req.URL.Path)
+	if hub := sentry.GetHubFromContext(ctx); hub != nil {
+		hub.ConfigureScope(func(scope *sentry.Scope) {
+			scope.SetRequest(req)
+			if id := req.Header.Get(UserIDKey); id != "" {
+				scope.SetUser(sentry.User{ID: id, IPAddress: hostOnly(req.RemoteAddr)})
+			}
+			if session := req.Header.Get(SessionIDKey); session != "" {
+				scope.SetTag("session", session)
+			}
+		})
+	}
+	return ctx
+}
+
+func firstValue(values []string) string {
+	if len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func hostOnly(addr string) string {
+	if i := strings.LastIndex(addr, ":"); i > 0 {
+		return strings.Trim(addr[:i], "[]")
+	}
+	return addr
+}
+
+// recordBreadcrumb is a log.Hook that records a sentry breadcrumb for each log made with a context that has
+// a request scope (see SetRequestScope)
+func recordBreadcrumb(ctx context.Context, level loggers.Level, args []interface{}) bool {
+	if hub := sentry.GetHubFromContext(ctx); hub != nil {
+		hub.AddBreadcrumb(breadcrumb(level, args), nil)
+	}
+	return true
+}
+
+func breadcrumb(level loggers.Level, args []interface{}) *sentry.Breadcrumb {
+	crumb := &sentry.Breadcrumb{
+		Type:      "default",
+		Category:  "log",
+		Level:     sentryLevel(level),
+		Timestamp: time.Now(),
+	}
+	if len(args)%2 == 1 {
+		crumb.Message = fmt.Sprint(args[0])
+		args = args[1:]
+	}
+	for i := 0; i+1 < len(args); i += 2 {
+		key := fmt.Sprint(args[i])
+		if key == "msg" && crumb.Message == "" {
+			crumb.Message = fmt.Sprint(args[i+1])
+			continue
+		}
+		if crumb.Data == nil {
+			crumb.Data = make(map[string]interface{})
+		}
+		if err, ok := args[i+1].(error); ok {
+			crumb.Data[key] = err.Error()
+		} else {
+			crumb.Data[key] = args[i+1]
+		}
+	}
+	return crumb
+}
+
+func sentryLevel(level loggers.Level) sentry.Level {
+	switch level {
+	case loggers.DebugLevel:
+		return sentry.LevelDebug
+	case loggers.InfoLevel:
+		return sentry.LevelInfo
+	case loggers.WarnLevel:
+		return sentry.LevelWarning
+	default:
+		return sentry.LevelError
+	}
+}
+
+// enableBreadcrumbs records breadcrumbs from all loggers
+func enableBreadcrumbs() {
+	log.SetHook("sentry-breadcrumbs", recordBreadcrumb)
+}
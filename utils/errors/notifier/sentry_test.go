@@ -0,0 +1,83 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/carousell/Orion/utils/errors"
+	"github.com/carousell/Orion/utils/log"
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func initMockSentry(t *testing.T) *sentry.MockTransport {
+	transport := &sentry.MockTransport{}
+	assert.NoError(t, InitSentryWithOptions(sentry.ClientOptions{Transport: transport, Environment: "test"}))
+	t.Cleanup(func() {
+		UnregisterReporter(SentryReporter)
+		sentry.CurrentHub().BindClient(nil)
+	})
+	return transport
+}
+
+func TestSentryReporter(t *testing.T) {
+	transport := initMockSentry(t)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(UserIDKey, "42", SessionIDKey, "s1"))
+	ctx = UpdateTraceId(ctx, "abc")
+	ctx = SetRequestScope(ctx, "/pkg.Svc/Get")
+	log.Info(ctx, "msg", "loading", "id", 1)
+	Notify(errors.New("boom"), ctx, Tags{"team": "core"})
+	Flush(time.Second)
+
+	events := transport.Events()
+	if assert.Len(t, events, 1) {
+		e := events[0]
+		assert.Equal(t, sentry.LevelError, e.Level)
+		assert.Equal(t, "test", e.Environment)
+		assert.Equal(t, "/pkg.Svc/Get", e.Tags["method"])
+		assert.Equal(t, "abc", e.Tags["traceId"])
+		assert.Equal(t, "s1", e.Tags["session"])
+		assert.Equal(t, "core", e.Tags["team"])
+		assert.Equal(t, "42", e.User.ID)
+		if assert.Len(t, e.Exception, 1) {
+			assert.Equal(t, "boom", e.Exception[0].Value)
+			frames := e.Exception[0].Stacktrace.Frames
+			if assert.NotEmpty(t, frames) {
+				// newest frame last
+				assert.Equal(t, "TestSentryReporter", frames[len(frames)-1].Function)
+			}
+		}
+		if assert.NotEmpty(t, e.Breadcrumbs) {
+			assert.Equal(t, "loading", e.Breadcrumbs[0].Message)
+			assert.Equal(t, 1, e.Breadcrumbs[0].Data["id"])
+		}
+	}
+
+	// breadcrumbs are kept per request
+	other := SetRequestScope(context.Background(), "/pkg.Svc/List")
+	Notify(errors.New("other"), other)
+	events = transport.Events()
+	if assert.Len(t, events, 2) {
+		assert.Empty(t, events[1].Breadcrumbs)
+		assert.Equal(t, "/pkg.Svc/List", events[1].Tags["method"])
+	}
+}
+
+func TestSentryPanic(t *testing.T) {
+	transport := initMockSentry(t)
+
+	ctx := SetRequestScope(context.Background(), "/pkg.Svc/Get")
+	assert.Panics(t, func() {
+		defer NotifyOnPanic(ctx)
+		panic("oops")
+	})
+	events := transport.Events()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, sentry.LevelFatal, events[0].Level)
+		assert.Equal(t, "true", events[0].Tags["panic"])
+		assert.Equal(t, "PANIC: oops", events[0].Exception[0].Value)
+	}
+}
@@ -1,7 +1,7 @@
 package hystrixprometheus
 
 import (
-	"github.com/afex/hystrix-go/hystrix/metric_collector"
+	"github.com/carousell/Orion/utils/breaker"
 	"github.com/prometheus/client_golang/prometheus"
 	"time"
 )
@@ -149,7 +149,7 @@ type cmdCollector struct {
 	metrics     *PrometheusCollector
 }
 
-func (pc *PrometheusCollector) Collector(name string) metricCollector.MetricCollector {
+func (pc *PrometheusCollector) Collector(name string) breaker.Collector {
 	c := &cmdCollector{
 		commandName: name,
 		metrics:     pc,
@@ -188,11 +188,11 @@ func (c *cmdCollector) updateTimerMetric(ph *prometheus.HistogramVec, dur time.D
 	ph.WithLabelValues(c.commandName).Observe(dur.Seconds())
 }
 
-func (c *cmdCollector) Update(r metricCollector.MetricResult) {
-	if r.Successes > 0 {
-		c.setGaugeMetric(c.metrics.circuitOpen, 0)
-	} else if r.ShortCircuits > 0 {
+func (c *cmdCollector) Update(r breaker.Result) {
+	if r.CircuitOpen {
 		c.setGaugeMetric(c.metrics.circuitOpen, 1)
+	} else {
+		c.setGaugeMetric(c.metrics.circuitOpen, 0)
 	}
 
 	c.incrementCounterMetric(c.metrics.attempts, r.Attempts)
@@ -210,6 +210,3 @@ func (c *cmdCollector) Update(r metricCollector.MetricResult) {
 	c.updateTimerMetric(c.metrics.totalDuration, r.TotalDuration)
 	c.updateTimerMetric(c.metrics.runDuration, r.RunDuration)
 }
-
-// Reset is a noop operation in this collector.
-func (c *cmdCollector) Reset() {}
@@ -0,0 +1,293 @@
+package listenerutils
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+var (
+	//ErrMuxClosed is returned when accepting on a closed mux listener
+	ErrMuxClosed = errors.New("mux: listener closed")
+	//DefaultSniffTimeout is the maximum time spent identifying the protocol of a new connection
+	DefaultSniffTimeout = time.Second * 5
+)
+
+//Mux serves gRPC and HTTP on a single listener, each accepted connection is sniffed and dispatched
+//to either the gRPC or the HTTP listener, HTTP/2 connections with content-type application/grpc are gRPC
+//everything else is HTTP
+type Mux struct {
+	root      net.Listener
+	grpc      *muxListener
+	http      *muxListener
+	once      sync.Once
+	closeOnce sync.Once
+}
+
+//NewMux creates a new Mux listening on the given network and address
+func NewMux(network, laddr string) (*Mux, error) {
+	lis, err := net.Listen(network, laddr)
+	if err != nil {
+		return nil, err
+	}
+	return NewMuxWithListener(lis), nil
+}
+
+//NewMuxWithListener creates a new Mux on an existing net.Listener,
+//for TLS pass in a listener created by tls.NewListener
+func NewMuxWithListener(lis net.Listener) *Mux {
+	m := &Mux{root: lis}
+	m.grpc = newMuxListener(m)
+	m.http = newMuxListener(m)
+	return m
+}
+
+//GRPC returns the CustomListener that receives gRPC connections
+func (m *Mux) GRPC() CustomListener {
+	return newListener(m.grpc, make(chan *acceptValues, 0), time.Second)
+}
+
+//HTTP returns the CustomListener that receives all non gRPC connections
+func (m *Mux) HTTP() CustomListener {
+	return newListener(m.http, make(chan *acceptValues, 0), time.Second)
+}
+
+func (m *Mux) start() {
+	m.once.Do(func() {
+		go m.serve()
+	})
+}
+
+func (m *Mux) serve() {
+	for {
+		conn, err := m.root.Accept()
+		if err != nil {
+			if e, ok := err.(net.Error); ok && e.Temporary() {
+				time.Sleep(time.Millisecond * 10)
+				continue
+			}
+			m.grpc.fail(err)
+			m.http.fail(err)
+			return
+		}
+		go m.dispatch(conn)
+	}
+}
+
+func (m *Mux) dispatch(conn net.Conn) {
+	conn.SetReadDeadline(time.Now().Add(DefaultSniffTimeout))
+	sniffed, isGRPC, err := sniff(conn)
+	conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		conn.Close()
+		return
+	}
+	if isGRPC {
+		m.grpc.deliver(sniffed)
+	} else {
+		m.http.deliver(sniffed)
+	}
+}
+
+func (m *Mux) closeRoot() error {
+	var err error
+	m.closeOnce.Do(func() {
+		err = m.root.Close()
+	})
+	return err
+}
+
+// muxListener is a net.Listener fed by the mux
+type muxListener struct {
+	mux    *Mux
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+	mu     sync.Mutex
+	err    error
+}
+
+func newMuxListener(m *Mux) *muxListener {
+	return &muxListener{
+		mux:    m,
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+}
+
+func (l *muxListener) Accept() (net.Conn, error) {
+	l.mux.start()
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.err != nil {
+			return nil, l.err
+		}
+		return nil, ErrMuxClosed
+	}
+}
+
+func (l *muxListener) deliver(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.closed:
+		conn.Close()
+	}
+}
+
+func (l *muxListener) fail(err error) {
+	l.mu.Lock()
+	l.err = err
+	l.mu.Unlock()
+	l.once.Do(func() {
+		close(l.closed)
+	})
+}
+
+func (l *muxListener) Close() error {
+	l.once.Do(func() {
+		close(l.closed)
+	})
+	// close the root listener only when both gRPC and HTTP have been closed
+	select {
+	case <-l.mux.grpc.closed:
+	default:
+		return nil
+	}
+	select {
+	case <-l.mux.http.closed:
+	default:
+		return nil
+	}
+	return l.mux.closeRoot()
+}
+
+func (l *muxListener) Addr() net.Addr {
+	return l.mux.root.Addr()
+}
+
+// sniffConn replays the bytes read while sniffing before reading from the underlying conn
+type sniffConn struct {
+	net.Conn
+	reader io.Reader
+}
+
+func (c *sniffConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// recorder captures everything read through it
+type recorder struct {
+	r   io.Reader
+	buf bytes.Buffer
+}
+
+func (r *recorder) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.buf.Write(b[:n])
+	return n, err
+}
+
+func (r *recorder) take() []byte {
+	data := append([]byte(nil), r.buf.Bytes()...)
+	r.buf.Reset()
+	return data
+}
+
+// sniff identifies the protocol on conn and returns a conn that replays everything read
+func sniff(conn net.Conn) (net.Conn, bool, error) {
+	preface := []byte(http2.ClientPreface)
+	read := make([]byte, 0, len(preface))
+	buf := make([]byte, len(preface))
+	for len(read) < len(preface) {
+		n, err := conn.Read(buf[:len(preface)-len(read)])
+		read = append(read, buf[:n]...)
+		if !bytes.HasPrefix(preface, read) {
+			// not HTTP/2 prior knowledge, must be HTTP/1.x
+			return replay(conn, read), false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	// HTTP/2, look for the content-type of the first request
+	rec := &recorder{r: conn}
+	framer := http2.NewFramer(conn, rec)
+	framer.ReadMetaHeaders = hpack.NewDecoder(4096, nil)
+	// some clients (grpc-go included) wait for server settings before sending any request
+	if err := framer.WriteSettings(); err != nil {
+		return nil, false, err
+	}
+	acked := false
+	isGRPC := false
+	frames := bytes.NewBuffer(read)
+	for {
+		f, err := framer.ReadFrame()
+		if err != nil {
+			return nil, false, err
+		}
+		data := rec.take()
+		if s, ok := f.(*http2.SettingsFrame); ok && s.IsAck() && !acked {
+			// ack of our settings, the real server should never see it
+			acked = true
+		} else {
+			frames.Write(data)
+		}
+		if h, ok := f.(*http2.MetaHeadersFrame); ok {
+			isGRPC = isGRPCHeaders(h)
+			break
+		}
+	}
+	// gRPC tolerates stray acks, other HTTP/2 servers do not, wait for the ack before handing over
+	for !isGRPC && !acked {
+		f, err := framer.ReadFrame()
+		if err != nil {
+			return nil, false, err
+		}
+		data := rec.take()
+		if s, ok := f.(*http2.SettingsFrame); ok && s.IsAck() {
+			acked = true
+			continue
+		}
+		frames.Write(data)
+	}
+	return replay(conn, frames.Bytes()), isGRPC, nil
+}
+
+func isGRPCHeaders(h *http2.MetaHeadersFrame) bool {
+	for _, f := range h.RegularFields() {
+		if strings.ToLower(f.Name) == "content-type" {
+			return isGRPCContentType(f.Value)
+		}
+	}
+	return false
+}
+
+// isGRPCContentType matches application/grpc, application/grpc+<codec> and parameters of either,
+// gRPC-Web (application/grpc-web...) is served by the HTTP handler
+func isGRPCContentType(contentType string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if !strings.HasPrefix(contentType, "application/grpc") {
+		return false
+	}
+	rest := contentType[len("application/grpc"):]
+	return rest == "" || rest[0] == '+' || rest[0] == ';'
+}
+
+func replay(conn net.Conn, data []byte) net.Conn {
+	return &sniffConn{
+		Conn:   conn,
+		reader: io.MultiReader(bytes.NewReader(data), conn),
+	}
+}
@@ -0,0 +1,126 @@
+package listenerutils
+
+import (
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func serveMuxHTTP(lis net.Listener) *http.Server {
+	handler := http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		resp.Write([]byte(req.Proto))
+	})
+	svr := &http.Server{Handler: h2c.NewHandler(handler, &http2.Server{})}
+	go svr.Serve(lis)
+	return svr
+}
+
+func serveMuxGRPC(lis net.Listener) *grpc.Server {
+	svr := grpc.NewServer()
+	healthpb.RegisterHealthServer(svr, health.NewServer())
+	go svr.Serve(lis)
+	return svr
+}
+
+func checkHTTP(t *testing.T, client *http.Client, url, proto string) {
+	resp, err := client.Get(url)
+	if assert.NoError(t, err) {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		assert.Equal(t, proto, string(body))
+	}
+}
+
+func checkGRPC(t *testing.T, addr string) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	}
+}
+
+func newH2Client() *http.Client {
+	return &http.Client{
+		Timeout: time.Second * 5,
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+}
+
+func TestMux(t *testing.T) {
+	mux, err := NewMux("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	grpcLis, httpLis := mux.GRPC(), mux.HTTP()
+	grpcSvr := serveMuxGRPC(grpcLis)
+	httpSvr := serveMuxHTTP(httpLis)
+
+	addr := grpcLis.Addr().String()
+	url := "http://" + addr + "/"
+	h1 := &http.Client{Timeout: time.Second * 5}
+	h2 := newH2Client()
+
+	checkGRPC(t, addr)
+	checkHTTP(t, h1, url, "HTTP/1.1")
+	checkHTTP(t, h2, url, "HTTP/2.0")
+
+	// gRPC-Web over h2 belongs to the HTTP handler, connections are sniffed once so use a new one
+	resp, err := newH2Client().Post(url, "application/grpc-web+proto", nil)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "HTTP/2.0", string(body))
+	}
+
+	// reload gRPC the way orion does on SIGHUP, HTTP must not be affected
+	grpcLis.StopAccept()
+	grpcSvr.GracefulStop()
+	grpcLis = grpcLis.GetListener()
+	grpcSvr = serveMuxGRPC(grpcLis)
+	checkGRPC(t, addr)
+	checkHTTP(t, h1, url, "HTTP/1.1")
+
+	grpcLis.CanClose(true)
+	httpLis.CanClose(true)
+	grpcSvr.Stop()
+	httpSvr.Close()
+	_, err = net.DialTimeout("tcp", addr, time.Second)
+	assert.Error(t, err, "root listener should be closed once both listeners are closed")
+}
+
+func TestIsGRPCContentType(t *testing.T) {
+	for contentType, expected := range map[string]bool{
+		"application/grpc":                true,
+		"application/grpc+proto":          true,
+		"Application/GRPC+json":           true,
+		"application/grpc; charset=utf-8": true,
+		"application/grpc-web":            false,
+		"application/grpc-web+proto":      false,
+		"application/grpc-web-text":       false,
+		"application/grpcx":               false,
+		"application/json":                false,
+	} {
+		assert.Equal(t, expected, isGRPCContentType(contentType), contentType)
+	}
+}
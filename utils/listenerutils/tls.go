@@ -0,0 +1,135 @@
+package listenerutils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync"
+)
+
+var (
+	//ErrNoCertificate is returned when cert or key file is not provided
+	ErrNoCertificate = errors.New("tls: certificate and key file are required")
+)
+
+//CertReloader loads TLS certificates from disk and allows them to be reloaded without restarting listeners
+type CertReloader struct {
+	certFile          string
+	keyFile           string
+	clientCAFile      string
+	requireClientCert bool
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+//NewCertReloader creates a new CertReloader and loads the certificates,
+//when clientCAFile is provided client certificates are verified against it (mutual TLS)
+func NewCertReloader(certFile, keyFile, clientCAFile string, requireClientCert bool) (*CertReloader, error) {
+	if strings.TrimSpace(certFile) == "" || strings.TrimSpace(keyFile) == "" {
+		return nil, ErrNoCertificate
+	}
+	c := &CertReloader{
+		certFile:          certFile,
+		keyFile:           keyFile,
+		clientCAFile:      clientCAFile,
+		requireClientCert: requireClientCert,
+	}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+//Reload reads certificates from disk, on error previously loaded certificates are kept
+func (c *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("tls: could not load key pair: %s", err.Error())
+	}
+	var pool *x509.CertPool
+	if strings.TrimSpace(c.clientCAFile) != "" {
+		data, err := ioutil.ReadFile(c.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("tls: could not read client CA: %s", err.Error())
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("tls: no certificates found in client CA %s", c.clientCAFile)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cert = &cert
+	c.clientCAs = pool
+	return nil
+}
+
+//GetCertificate returns the currently loaded certificate, it can be used as tls.Config.GetCertificate
+func (c *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, nil
+}
+
+func (c *CertReloader) clientAuth() tls.ClientAuthType {
+	switch {
+	case c.clientCAs != nil && c.requireClientCert:
+		return tls.RequireAndVerifyClientCert
+	case c.clientCAs != nil:
+		return tls.VerifyClientCertIfGiven
+	case c.requireClientCert:
+		return tls.RequireAnyClientCert
+	}
+	return tls.NoClientCert
+}
+
+//TLSConfig returns a tls.Config that always uses the latest loaded certificates
+func (c *CertReloader) TLSConfig(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     nextProtos,
+		GetCertificate: c.GetCertificate,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   nextProtos,
+				Certificates: []tls.Certificate{*c.cert},
+				ClientCAs:    c.clientCAs,
+				ClientAuth:   c.clientAuth(),
+			}, nil
+		},
+	}
+}
+
+type tlsListener struct {
+	CustomListener
+	config *tls.Config
+}
+
+func (t *tlsListener) Accept() (net.Conn, error) {
+	conn, err := t.CustomListener.Accept()
+	if err != nil {
+		return conn, err
+	}
+	return tls.Server(conn, t.config), nil
+}
+
+func (t *tlsListener) GetListener() CustomListener {
+	return NewTLSListener(t.CustomListener.GetListener(), t.config)
+}
+
+//NewTLSListener wraps a CustomListener and terminates TLS on all accepted connections
+func NewTLSListener(lis CustomListener, config *tls.Config) CustomListener {
+	return &tlsListener{
+		CustomListener: lis,
+		config:         config,
+	}
+}
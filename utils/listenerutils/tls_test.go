@@ -0,0 +1,151 @@
+package listenerutils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeCert(t *testing.T, dir, name, cn string) (certFile, keyFile string, cert *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err = x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	assert.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile, cert
+}
+
+func serveTLS(t *testing.T, reloader *CertReloader) CustomListener {
+	lis, err := NewListener("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	lis.CanClose(true)
+	tlsLis := NewTLSListener(lis, reloader.TLSConfig())
+	go func() {
+		for {
+			conn, err := tlsLis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.(*tls.Conn).Handshake()
+				conn.Write([]byte("ok"))
+			}()
+		}
+	}()
+	return tlsLis
+}
+
+func dial(addr string, config *tls.Config) (*tls.ConnectionState, error) {
+	conn, err := tls.Dial("tcp", addr, config)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	buf := make([]byte, 2)
+	if _, err := conn.Read(buf); err != nil {
+		return nil, err
+	}
+	state := conn.ConnectionState()
+	return &state, nil
+}
+
+func TestTLSListenerReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "orion-tls")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile, first := writeCert(t, dir, "server", "first")
+	reloader, err := NewCertReloader(certFile, keyFile, "", false)
+	assert.NoError(t, err)
+	lis := serveTLS(t, reloader)
+	defer lis.Close()
+
+	state, err := dial(lis.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+	assert.NoError(t, err)
+	if assert.NotNil(t, state) {
+		assert.Equal(t, first.Raw, state.PeerCertificates[0].Raw)
+	}
+
+	// rotate certificate on disk and reload without restarting the listener
+	rotated, rotatedKey, second := writeCert(t, dir, "rotated", "second")
+	assert.NoError(t, os.Rename(rotated, certFile))
+	assert.NoError(t, os.Rename(rotatedKey, keyFile))
+	assert.NoError(t, reloader.Reload())
+
+	state, err = dial(lis.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+	assert.NoError(t, err)
+	if assert.NotNil(t, state) {
+		assert.Equal(t, second.Raw, state.PeerCertificates[0].Raw)
+	}
+
+	// failed reload keeps serving the previous certificate
+	assert.NoError(t, ioutil.WriteFile(certFile, []byte("garbage"), 0600))
+	assert.Error(t, reloader.Reload())
+	state, err = dial(lis.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+	assert.NoError(t, err)
+	if assert.NotNil(t, state) {
+		assert.Equal(t, second.Raw, state.PeerCertificates[0].Raw)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "orion-mtls")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile, _ := writeCert(t, dir, "server", "server")
+	clientCert, clientKey, _ := writeCert(t, dir, "client", "client")
+	reloader, err := NewCertReloader(certFile, keyFile, clientCert, true)
+	assert.NoError(t, err)
+	lis := serveTLS(t, reloader)
+	defer lis.Close()
+
+	// no client certificate
+	_, err = dial(lis.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+	assert.Error(t, err)
+
+	pair, err := tls.LoadX509KeyPair(clientCert, clientKey)
+	assert.NoError(t, err)
+	_, err = dial(lis.Addr().String(), &tls.Config{InsecureSkipVerify: true, Certificates: []tls.Certificate{pair}})
+	assert.NoError(t, err)
+}
+
+func TestNewCertReloader(t *testing.T) {
+	_, err := NewCertReloader("", "", "", false)
+	assert.Equal(t, ErrNoCertificate, err)
+	_, err = NewCertReloader("missing.crt", "missing.key", "", false)
+	assert.Error(t, err)
+}
@@ -13,6 +13,23 @@ You can also initialize a new logger by calling 'log.NewLogger' and passing a lo
 	logger := log.NewLogger(gokit.NewLogger())
 	logger.Info(ctx, "key", "value")
 
+Services using log/slog or zap can keep a single logging pipeline, loggers/slog and loggers/zap log to an existing slog.Handler or zap.Logger
+	logger := log.NewLogger(slog.NewLoggerWithHandler(handler))
+and 'wrap.ToSlogHandler' exposes an Orion logger as a slog.Handler
+	slogger := slog.New(wrap.ToSlogHandler(log.GetLogger()))
+
+'loggers.NewMultiLogger' writes to several BaseLoggers, each with its own level and field filters
+	logger := log.NewLogger(loggers.NewMultiLogger(
+		loggers.Sink{Logger: gokit.NewLogger(loggers.WithLevel(loggers.InfoLevel))},
+		loggers.Sink{Logger: gokit.NewLogger(loggers.WithLevel(loggers.DebugLevel),
+			loggers.WithWriter(loggers.NewRotatingFileWriter("/var/log/service.log", 100, 5, 7)))},
+	))
+
+'log.SetHook' registers a function that sees every log made through loggers created by 'log.NewLogger', hooks can drop logs and are kept when the global logger is changed
+	log.SetHook("audit", func(ctx context.Context, level loggers.Level, args []interface{}) bool {
+		return true
+	})
+
 Note:
 	Preferred logging output is in either logfmt or json format, so to facilitate these log function arguments should be in pairs of key-value
 
@@ -29,3 +46,6 @@ package log
 //go:generate godoc2ghmd -ex -file=log/loggers/README.md github.com/carousell/Orion/utils/log/loggers
 //go:generate godoc2ghmd -ex -file=log/loggers/gokit/README.md github.com/carousell/Orion/utils/log/loggers/gokit
 //go:generate godoc2ghmd -ex -file=log/loggers/logrus/README.md github.com/carousell/Orion/utils/log/loggers/logrus
+//go:generate godoc2ghmd -ex -file=log/loggers/slog/README.md github.com/carousell/Orion/utils/log/loggers/slog
+//go:generate godoc2ghmd -ex -file=log/loggers/zap/README.md github.com/carousell/Orion/utils/log/loggers/zap
+//go:generate godoc2ghmd -ex -file=log/loggers/sampler/README.md github.com/carousell/Orion/utils/log/loggers/sampler
@@ -12,8 +12,20 @@ var (
 	defaultLogger Logger
 	mu            sync.Mutex
 	once          sync.Once
+
+	hooksMu sync.RWMutex
+	hooks   []namedHook
 )
 
+//Hook is called with each log that passes the level check of a Logger created by NewLogger,
+//args are already scrubbed, returning false drops the log
+type Hook func(ctx context.Context, level loggers.Level, args []interface{}) bool
+
+type namedHook struct {
+	name string
+	hook Hook
+}
+
 type logger struct {
 	baseLog loggers.BaseLogger
 }
@@ -46,9 +58,49 @@ func (l *logger) Log(ctx context.Context, level loggers.Level, skip int, args ..
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	if l.GetLevel() >= level {
-		l.baseLog.Log(ctx, level, skip+1, args...)
+	if loggers.IsLevelEnabled(ctx, l.GetLevel(), level) {
+		// sinks never see sensitive data
+		ctx, args = loggers.Scrub(ctx, args)
+		if runHooks(ctx, level, args) {
+			l.baseLog.Log(ctx, level, skip+1, args...)
+		}
+	}
+}
+
+func runHooks(ctx context.Context, level loggers.Level, args []interface{}) bool {
+	hooksMu.RLock()
+	current := hooks
+	hooksMu.RUnlock()
+	for _, h := range current {
+		if !h.hook(ctx, level, args) {
+			return false
+		}
+	}
+	return true
+}
+
+//SetHook registers hook under name, replacing the hook already registered with that name, a nil hook removes it.
+//Hooks run in the order they were first registered and are kept when the global logger is changed with SetLogger
+func SetHook(name string, hook Hook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	updated := make([]namedHook, 0, len(hooks)+1)
+	found := false
+	for _, h := range hooks {
+		if h.name == name {
+			found = true
+			if hook == nil {
+				continue
+			}
+			h.hook = hook
+		}
+		updated = append(updated, h)
+	}
+	if !found && hook != nil {
+		updated = append(updated, namedHook{name: name, hook: hook})
 	}
+	// copy on write, hooks run without holding hooksMu
+	hooks = updated
 }
 
 //NewLogger creates a new logger with a provided BaseLogger
@@ -0,0 +1,57 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingLogger struct {
+	level loggers.Level
+	count int
+}
+
+func (c *countingLogger) Log(ctx context.Context, level loggers.Level, skip int, args ...interface{}) {
+	c.count++
+}
+
+func (c *countingLogger) SetLevel(level loggers.Level) {
+	c.level = level
+}
+
+func (c *countingLogger) GetLevel() loggers.Level {
+	return c.level
+}
+
+func TestHooks(t *testing.T) {
+	var seen []string
+	SetHook("record", func(ctx context.Context, level loggers.Level, args []interface{}) bool {
+		seen = append(seen, args[0].(string))
+		return true
+	})
+	SetHook("drop", func(ctx context.Context, level loggers.Level, args []interface{}) bool {
+		return args[0] != "dropped"
+	})
+	defer SetHook("record", nil)
+	defer SetHook("drop", nil)
+
+	first := &countingLogger{level: loggers.InfoLevel}
+	l := NewLogger(first)
+	l.Info(context.Background(), "kept")
+	l.Info(context.Background(), "dropped")
+	l.Debug(context.Background(), "filtered")
+	assert.Equal(t, []string{"kept", "dropped"}, seen)
+	assert.Equal(t, 1, first.count)
+
+	// hooks are not tied to a logger
+	second := &countingLogger{level: loggers.InfoLevel}
+	NewLogger(second).Info(context.Background(), "dropped")
+	assert.Equal(t, 0, second.count)
+	assert.Equal(t, []string{"kept", "dropped", "dropped"}, seen)
+
+	SetHook("drop", nil)
+	NewLogger(second).Info(context.Background(), "dropped")
+	assert.Equal(t, 1, second.count)
+}
@@ -4,6 +4,7 @@ package gokit
 import (
 	"context"
 	"fmt"
+	"io"
 	stdlog "log"
 	"os"
 
@@ -59,7 +60,11 @@ func NewLogger(options ...loggers.Option) loggers.BaseLogger {
 	}
 
 	l := logger{}
-	writer := log.NewSyncWriter(os.Stdout)
+	var writer io.Writer = os.Stdout
+	if opt.Writer != nil {
+		writer = opt.Writer
+	}
+	writer = log.NewSyncWriter(writer)
 
 	// check for json or logfmt
 	if opt.JSONLogs {
@@ -0,0 +1,34 @@
+package loggers
+
+import (
+	"context"
+)
+
+var (
+	levelContextKey logsContext = "LogsLevelContextKey"
+)
+
+//AddLevelToContext overrides the log level for all logs using this context,
+//the override can only make logs more verbose than the logger's level
+func AddLevelToContext(ctx context.Context, level Level) context.Context {
+	return context.WithValue(ctx, levelContextKey, level)
+}
+
+//LevelFromContext fetches the log level override from provided context
+func LevelFromContext(ctx context.Context) (Level, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	level, ok := ctx.Value(levelContextKey).(Level)
+	return level, ok
+}
+
+//IsLevelEnabled returns true when a log at level should be written by a logger set to loggerLevel,
+//taking the level override in context into account
+func IsLevelEnabled(ctx context.Context, loggerLevel Level, level Level) bool {
+	if loggerLevel >= level {
+		return true
+	}
+	override, ok := LevelFromContext(ctx)
+	return ok && override >= level
+}
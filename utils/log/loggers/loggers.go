@@ -5,6 +5,7 @@ package loggers
 import (
 	"context"
 	"fmt"
+	"io"
 	"runtime"
 	"strings"
 )
@@ -85,6 +86,8 @@ type Options struct {
 	CallerInfo         bool
 	CallerFileDepth    int
 	CallerFieldName    string
+	// Writer is where logs are written, defaults to os.Stdout
+	Writer io.Writer
 }
 
 //GetDefaultOptions fetches loggers default options
@@ -123,6 +126,22 @@ func WithJSONLogs(json bool) Option {
 	}
 }
 
+//WithLevel sets the level of the BaseLogger
+func WithLevel(level Level) Option {
+	return func(o *Options) {
+		o.Level = level
+	}
+}
+
+//WithWriter sets where logs are written, e.g. a file from NewRotatingFileWriter
+func WithWriter(w io.Writer) Option {
+	return func(o *Options) {
+		if w != nil {
+			o.Writer = w
+		}
+	}
+}
+
 //WithTimestampFieldName sets the name of the time stamp field in logs
 func WithTimestampFieldName(name string) Option {
 	return func(o *Options) {
@@ -4,16 +4,32 @@ package logrus
 import (
 	"context"
 	"fmt"
+	"io"
 	stdlog "log"
 	"os"
+	"sync"
 
 	"github.com/carousell/Orion/utils/log/loggers"
 	log "github.com/sirupsen/logrus"
 )
 
+// lockedWriter serializes writes of logger and verbose, logrus only locks the writes of a single logger
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
 type logger struct {
 	logger *log.Logger
-	opt    loggers.Options
+	// verbose shares output and formatting with logger, it writes logs escalated through the context level
+	verbose *log.Logger
+	opt     loggers.Options
 }
 
 func toLogrusLogLevel(level loggers.Level) log.Level {
@@ -47,7 +63,11 @@ func (l *logger) Log(ctx context.Context, level loggers.Level, skip int, args ..
 		fields[l.opt.CallerFieldName] = fmt.Sprintf("%s:%d", file, line)
 	}
 
-	logger := l.logger.WithFields(fields)
+	lgr := l.logger
+	if !lgr.IsLevelEnabled(toLogrusLogLevel(level)) && loggers.IsLevelEnabled(ctx, l.GetLevel(), level) {
+		lgr = l.verbose
+	}
+	logger := lgr.WithFields(fields)
 	switch level {
 	case loggers.DebugLevel:
 		logger.Debug(args...)
@@ -58,7 +78,7 @@ func (l *logger) Log(ctx context.Context, level loggers.Level, skip int, args ..
 	case loggers.ErrorLevel:
 		logger.Error(args...)
 	default:
-		l.logger.Error(args...)
+		lgr.Error(args...)
 	}
 }
 
@@ -93,6 +113,14 @@ func NewLogger(options ...loggers.Option) loggers.BaseLogger {
 	l := logger{}
 	l.logger = log.New()
 	l.logger.Out = os.Stdout
+	if opt.Writer != nil {
+		l.logger.Out = opt.Writer
+	}
+	// verbose writes to the same writer, a single write to a file is never interleaved and
+	// files are kept as is for terminal detection
+	if _, ok := l.logger.Out.(*os.File); !ok {
+		l.logger.Out = &lockedWriter{w: l.logger.Out}
+	}
 
 	l.logger.SetLevel(toLogrusLogLevel(opt.Level))
 
@@ -111,6 +139,15 @@ func NewLogger(options ...loggers.Option) loggers.BaseLogger {
 		}
 	}
 
+	l.verbose = &log.Logger{
+		Out:          l.logger.Out,
+		Hooks:        l.logger.Hooks,
+		Formatter:    l.logger.Formatter,
+		ReportCaller: l.logger.ReportCaller,
+		ExitFunc:     l.logger.ExitFunc,
+		Level:        log.DebugLevel,
+	}
+
 	l.opt = opt
 
 	if opt.ReplaceStdLogger {
@@ -0,0 +1,55 @@
+package logrus
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/stretchr/testify/assert"
+)
+
+// overlapWriter records writes that run at the same time
+type overlapWriter struct {
+	active   int32
+	overlaps int32
+	writes   int32
+}
+
+func (w *overlapWriter) Write(p []byte) (int, error) {
+	if atomic.AddInt32(&w.active, 1) > 1 {
+		atomic.AddInt32(&w.overlaps, 1)
+	}
+	time.Sleep(100 * time.Microsecond)
+	atomic.AddInt32(&w.active, -1)
+	atomic.AddInt32(&w.writes, 1)
+	return len(p), nil
+}
+
+func TestVerboseSharesLock(t *testing.T) {
+	w := &overlapWriter{}
+	l := NewLogger(loggers.WithWriter(w), loggers.WithJSONLogs(true), loggers.WithLevel(loggers.InfoLevel))
+	// debug logs escalated through the context are written by the verbose logger
+	verbose := loggers.AddLevelToContext(context.Background(), loggers.DebugLevel)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if i == 0 {
+					l.Log(verbose, loggers.DebugLevel, 0, "verbose")
+				} else {
+					l.Log(context.Background(), loggers.InfoLevel, 0, "normal")
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(100), atomic.LoadInt32(&w.writes))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&w.overlaps))
+}
@@ -0,0 +1,139 @@
+package loggers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/natefinch/lumberjack"
+)
+
+//Sink is a BaseLogger written to by a MultiLogger, logs are written when they are enabled
+//for the level of Logger (see WithLevel)
+type Sink struct {
+	Logger BaseLogger
+	// IncludeFields when set only writes these fields, from context and from log arguments
+	IncludeFields []string
+	// ExcludeFields are never written, from context and from log arguments
+	ExcludeFields []string
+}
+
+func (s Sink) hasFilters() bool {
+	return len(s.IncludeFields) > 0 || len(s.ExcludeFields) > 0
+}
+
+func (s Sink) allowField(key string) bool {
+	for _, f := range s.ExcludeFields {
+		if f == key {
+			return false
+		}
+	}
+	if len(s.IncludeFields) == 0 {
+		return true
+	}
+	for _, f := range s.IncludeFields {
+		if f == key {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns ctx and args with the fields not allowed by this sink removed
+func (s Sink) filter(ctx context.Context, args []interface{}) (context.Context, []interface{}) {
+	if ctxFields := FromContext(ctx); ctxFields != nil {
+		fields := make(LogFields, len(ctxFields))
+		for k, v := range ctxFields {
+			if s.allowField(k) {
+				fields[k] = v
+			}
+		}
+		// log fields are shared by the request, replace them only for this sink
+		ctx = context.WithValue(ctx, contextKey, fields)
+	}
+	filtered := make([]interface{}, 0, len(args))
+	if len(args)%2 == 1 {
+		// leading message
+		filtered = append(filtered, args[0])
+		args = args[1:]
+	}
+	for i := 0; i+1 < len(args); i += 2 {
+		if s.allowField(fmt.Sprint(args[i])) {
+			filtered = append(filtered, args[i], args[i+1])
+		}
+	}
+	return ctx, filtered
+}
+
+type multiLogger struct {
+	sinks []Sink
+	// level filters logs before sinks, it does not change the level of sinks,
+	// it is stored atomically since it can be set while logs are written
+	level atomic.Uint32
+}
+
+//NewMultiLogger returns a BaseLogger that writes each log to all sinks enabled for its level
+func NewMultiLogger(sinks ...Sink) BaseLogger {
+	m := &multiLogger{}
+	for _, s := range sinks {
+		if s.Logger != nil {
+			m.sinks = append(m.sinks, s)
+		}
+	}
+	m.level.Store(uint32(m.sinkLevel()))
+	return m
+}
+
+func (m *multiLogger) Log(ctx context.Context, level Level, skip int, args ...interface{}) {
+	if !IsLevelEnabled(ctx, Level(m.level.Load()), level) {
+		return
+	}
+	for _, s := range m.sinks {
+		if !IsLevelEnabled(ctx, s.Logger.GetLevel(), level) {
+			continue
+		}
+		if s.hasFilters() {
+			sinkCtx, sinkArgs := s.filter(ctx, args)
+			s.Logger.Log(sinkCtx, level, skip+1, sinkArgs...)
+		} else {
+			s.Logger.Log(ctx, level, skip+1, args...)
+		}
+	}
+}
+
+//SetLevel filters logs written to all sinks by level, sinks still drop logs below their own level
+func (m *multiLogger) SetLevel(level Level) {
+	m.level.Store(uint32(level))
+}
+
+//GetLevel returns the most verbose level written by any sink
+func (m *multiLogger) GetLevel() Level {
+	level := Level(m.level.Load())
+	if sinkLevel := m.sinkLevel(); sinkLevel < level {
+		return sinkLevel
+	}
+	return level
+}
+
+// sinkLevel returns the most verbose level of all sinks
+func (m *multiLogger) sinkLevel() Level {
+	var level Level
+	for _, s := range m.sinks {
+		if l := s.Logger.GetLevel(); l > level {
+			level = l
+		}
+	}
+	return level
+}
+
+//NewRotatingFileWriter returns a writer to filename that is rotated once it reaches maxSizeMB,
+//at most maxBackups rotated files are kept for maxAgeDays (0 keeps all), use with WithWriter
+func NewRotatingFileWriter(filename string, maxSizeMB, maxBackups, maxAgeDays int) io.WriteCloser {
+	return &lumberjack.Logger{
+		Filename:   filename,
+		MaxSize:    maxSizeMB,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAgeDays,
+	}
+}
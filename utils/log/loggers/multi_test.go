@@ -0,0 +1,132 @@
+package loggers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/carousell/Orion/utils/log"
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/carousell/Orion/utils/log/loggers/gokit"
+	"github.com/stretchr/testify/assert"
+)
+
+func entries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		result = append(result, entry)
+	}
+	return result
+}
+
+func TestMultiLogger(t *testing.T) {
+	stdout, file := new(bytes.Buffer), new(bytes.Buffer)
+	logger := log.NewLogger(loggers.NewMultiLogger(
+		loggers.Sink{
+			Logger:        gokit.NewLogger(loggers.WithWriter(stdout), loggers.WithLevel(loggers.InfoLevel)),
+			ExcludeFields: []string{"req"},
+		},
+		loggers.Sink{
+			Logger: gokit.NewLogger(loggers.WithWriter(file), loggers.WithLevel(loggers.DebugLevel)),
+		},
+	))
+	assert.Equal(t, loggers.Level(loggers.DebugLevel), logger.GetLevel())
+
+	ctx := loggers.AddToLogContext(context.Background(), "trace", "abc")
+	ctx = loggers.AddToLogContext(ctx, "req", "{...}")
+	logger.Info(ctx, "method", "/pkg.Svc/Get")
+	logger.Debug(ctx, "msg", "details")
+
+	out := entries(t, stdout)
+	if assert.Len(t, out, 1) {
+		assert.Equal(t, "/pkg.Svc/Get", out[0]["method"])
+		assert.Equal(t, "abc", out[0]["trace"])
+		assert.Nil(t, out[0]["req"])
+		// caller info points at the log call
+		assert.True(t, strings.HasPrefix(out[0]["caller"].(string), "loggers/multi_test.go:"), out[0]["caller"])
+	}
+	// the request log context is not modified by filters
+	assert.Equal(t, "{...}", loggers.FromContext(ctx)["req"])
+
+	all := entries(t, file)
+	if assert.Len(t, all, 2) {
+		assert.Equal(t, "{...}", all[0]["req"])
+		assert.Equal(t, "details", all[1]["msg"])
+		assert.Equal(t, out[0]["caller"], all[0]["caller"])
+	}
+}
+
+func TestMultiLoggerSetLevel(t *testing.T) {
+	stdout, file := new(bytes.Buffer), new(bytes.Buffer)
+	logger := log.NewLogger(loggers.NewMultiLogger(
+		loggers.Sink{Logger: gokit.NewLogger(loggers.WithWriter(stdout), loggers.WithLevel(loggers.WarnLevel))},
+		loggers.Sink{Logger: gokit.NewLogger(loggers.WithWriter(file), loggers.WithLevel(loggers.DebugLevel))},
+	))
+
+	// the global level filters all sinks
+	logger.SetLevel(loggers.InfoLevel)
+	assert.Equal(t, loggers.Level(loggers.InfoLevel), logger.GetLevel())
+	logger.Debug(context.Background(), "msg", "dropped")
+	logger.Info(context.Background(), "msg", "info")
+	assert.Len(t, entries(t, stdout), 0)
+	assert.Len(t, entries(t, file), 1)
+
+	// sinks keep their own levels
+	logger.SetLevel(loggers.DebugLevel)
+	assert.Equal(t, loggers.Level(loggers.DebugLevel), logger.GetLevel())
+	logger.Debug(context.Background(), "msg", "details")
+	logger.Info(context.Background(), "msg", "info")
+	assert.Len(t, entries(t, stdout), 0)
+	assert.Len(t, entries(t, file), 3)
+}
+
+func TestMultiLoggerSetLevelConcurrent(t *testing.T) {
+	logger := loggers.NewMultiLogger(loggers.Sink{Logger: gokit.NewLogger(loggers.WithWriter(io.Discard))})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			logger.SetLevel(loggers.Level(i % 4))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		logger.Log(context.Background(), loggers.InfoLevel, 0, "msg", "concurrent")
+		logger.GetLevel()
+	}
+	<-done
+}
+
+func TestSinkIncludeFields(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := log.NewLogger(loggers.NewMultiLogger(loggers.Sink{
+		Logger:        gokit.NewLogger(loggers.WithWriter(buf), loggers.WithCallerInfo(false)),
+		IncludeFields: []string{"method", "error"},
+	}))
+	logger.Error(context.Background(), "method", "/pkg.Svc/Get", "error", "boom", "req", "{...}")
+	out := entries(t, buf)
+	if assert.Len(t, out, 1) {
+		assert.Equal(t, "boom", out[0]["error"])
+		assert.Nil(t, out[0]["req"])
+	}
+}
+
+func TestRotatingFileWriter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "service.log")
+	w := loggers.NewRotatingFileWriter(filename, 1, 2, 0)
+	logger := log.NewLogger(gokit.NewLogger(loggers.WithWriter(w)))
+	logger.Info(context.Background(), "msg", "to file")
+	assert.NoError(t, w.Close())
+	data, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"msg":"to file"`)
+}
@@ -0,0 +1,285 @@
+//Package sampler provides a BaseLogger wrapper that samples and rate limits repeated logs,
+//Logger.Sample can be registered with log.SetHook to sample the global logger instead
+package sampler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carousell/Orion/utils/log/loggers"
+)
+
+const (
+	//DefaultInterval is the sampling window used when none is configured
+	DefaultInterval = time.Second
+	//DefaultFirst is the number of logs per key allowed in each window when none is configured
+	DefaultFirst = 100
+	//DefaultSummaryInterval is the time between two summaries of suppressed logs when none is configured
+	DefaultSummaryInterval = time.Minute
+
+	// logs with more keys than this start a new generation of counters
+	maxSamplerKeys = 10000
+	// longest key value kept, longer values are truncated
+	maxKeyLength = 256
+	// keys reported in a summary line
+	maxSummaryKeys = 10
+)
+
+//Rule decides how many logs with the same key are written in each sampling window
+type Rule struct {
+	// First logs are always written in each window
+	First int
+	// Thereafter every Mth log is written after First, 0 drops the rest of the window
+	Thereafter int
+}
+
+//Config is the configuration for a sampling logger
+type Config struct {
+	// Enabled turns on sampling, all logs are written when false
+	Enabled bool
+	// Interval is the sampling window
+	Interval time.Duration
+	// First logs with the same key are written in each window
+	First int
+	// Thereafter every Mth log with the same key is written after First, 0 drops the rest of the window
+	Thereafter int
+	// Levels overrides First/Thereafter for a level, keyed by level name ("error", "warning", "info", "debug")
+	Levels map[string]Rule
+	// SummaryInterval is the time between two summary lines reporting suppressed logs
+	SummaryInterval time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.Interval <= 0 {
+		c.Interval = DefaultInterval
+	}
+	if c.First <= 0 {
+		c.First = DefaultFirst
+	}
+	if c.Thereafter < 0 {
+		c.Thereafter = 0
+	}
+	if c.SummaryInterval <= 0 {
+		c.SummaryInterval = DefaultSummaryInterval
+	}
+	return c
+}
+
+func (c Config) rule(level loggers.Level) Rule {
+	for name, rule := range c.Levels {
+		if l, err := loggers.ParseLevel(name); err == nil && l == level {
+			return rule
+		}
+	}
+	return Rule{First: c.First, Thereafter: c.Thereafter}
+}
+
+// summaryKey marks the context of summary lines, they are never sampled
+type summaryKey struct{}
+
+type counter struct {
+	count   int
+	resetAt time.Time
+}
+
+//Logger is a loggers.BaseLogger that samples logs written to another BaseLogger,
+//logs sharing the same level and leading key value pair are counted together
+type Logger struct {
+	base loggers.BaseLogger
+	now  func() time.Time
+
+	mu          sync.Mutex
+	config      Config
+	rules       map[loggers.Level]Rule
+	counters    map[string]*counter
+	suppressed  map[string]uint64
+	lastSummary time.Time
+	ticker      *time.Ticker
+	done        chan struct{}
+}
+
+//NewLogger wraps base with a sampling logger
+func NewLogger(base loggers.BaseLogger, config Config) *Logger {
+	l := &Logger{
+		base: base,
+		now:  time.Now,
+	}
+	l.Update(config)
+	return l
+}
+
+//Update replaces the configuration of this Logger, sampling counters are reset,
+//summaries of suppressed logs are written every SummaryInterval while sampling is enabled
+func (l *Logger) Update(config Config) {
+	config = config.withDefaults()
+	rules := make(map[loggers.Level]Rule)
+	for _, level := range loggers.AllLevels {
+		rules[level] = config.rule(level)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.config = config
+	l.rules = rules
+	l.counters = make(map[string]*counter)
+	if l.suppressed == nil {
+		l.suppressed = make(map[string]uint64)
+	}
+	if l.lastSummary.IsZero() {
+		l.lastSummary = l.now()
+	}
+	switch {
+	case !config.Enabled:
+		l.stopTicker()
+	case l.ticker == nil:
+		l.ticker = time.NewTicker(config.SummaryInterval)
+		l.done = make(chan struct{})
+		go l.summarize(l.ticker, l.done)
+	default:
+		l.ticker.Reset(config.SummaryInterval)
+	}
+}
+
+//Stop stops writing periodic summaries and writes a summary of logs suppressed since the last one
+func (l *Logger) Stop() {
+	l.mu.Lock()
+	l.stopTicker()
+	l.mu.Unlock()
+	l.Flush()
+}
+
+// stopTicker must be called with l.mu held
+func (l *Logger) stopTicker() {
+	if l.ticker != nil {
+		l.ticker.Stop()
+		close(l.done)
+		l.ticker, l.done = nil, nil
+	}
+}
+
+func (l *Logger) summarize(ticker *time.Ticker, done chan struct{}) {
+	for {
+		select {
+		case <-ticker.C:
+			l.Flush()
+		case <-done:
+			return
+		}
+	}
+}
+
+func sampleKey(level loggers.Level, args []interface{}) string {
+	parts := []string{level.String()}
+	for i := 0; i < len(args) && i < 2; i++ {
+		if err, ok := args[i].(error); ok && err != nil {
+			parts = append(parts, err.Error())
+		} else {
+			parts = append(parts, fmt.Sprint(args[i]))
+		}
+	}
+	key := strings.Join(parts, "|")
+	if len(key) > maxKeyLength {
+		key = key[:maxKeyLength]
+	}
+	return key
+}
+
+// allow must be called with l.mu held
+func (l *Logger) allow(now time.Time, level loggers.Level, key string) bool {
+	c, ok := l.counters[key]
+	if !ok {
+		if len(l.counters) >= maxSamplerKeys {
+			// windows are short, starting over only lets a few extra logs through
+			l.counters = make(map[string]*counter)
+		}
+		c = &counter{}
+		l.counters[key] = c
+	}
+	if !now.Before(c.resetAt) {
+		c.count = 0
+		c.resetAt = now.Add(l.config.Interval)
+	}
+	c.count++
+	rule := l.rules[level]
+	if c.count <= rule.First {
+		return true
+	}
+	return rule.Thereafter > 0 && (c.count-rule.First)%rule.Thereafter == 0
+}
+
+// summary must be called with l.mu held, it returns the fields of a summary line when logs were suppressed
+func (l *Logger) summary(now time.Time) []interface{} {
+	if len(l.suppressed) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(l.suppressed))
+	total := uint64(0)
+	for key, count := range l.suppressed {
+		keys = append(keys, key)
+		total += count
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if l.suppressed[keys[i]] == l.suppressed[keys[j]] {
+			return keys[i] < keys[j]
+		}
+		return l.suppressed[keys[i]] > l.suppressed[keys[j]]
+	})
+	top := make(map[string]uint64)
+	for i := 0; i < len(keys) && i < maxSummaryKeys; i++ {
+		top[keys[i]] = l.suppressed[keys[i]]
+	}
+	fields := []interface{}{"msg", "logs suppressed by sampling", "suppressed", total, "keys", len(keys),
+		"top", top, "since", l.lastSummary}
+	l.suppressed = make(map[string]uint64)
+	l.lastSummary = now
+	return fields
+}
+
+//Sample counts the log and reports whether it should be written, it can be registered with log.SetHook
+//to sample the global logger
+func (l *Logger) Sample(ctx context.Context, level loggers.Level, args []interface{}) bool {
+	if ctx != nil && ctx.Value(summaryKey{}) != nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.config.Enabled {
+		return true
+	}
+	key := sampleKey(level, args)
+	if l.allow(l.now(), level, key) {
+		return true
+	}
+	l.suppressed[key]++
+	return false
+}
+
+//Log writes the log to the wrapped BaseLogger unless it is suppressed by sampling
+func (l *Logger) Log(ctx context.Context, level loggers.Level, skip int, args ...interface{}) {
+	if l.Sample(ctx, level, args) {
+		l.base.Log(ctx, level, skip+1, args...)
+	}
+}
+
+//Flush writes a summary of logs suppressed since the last summary right away
+func (l *Logger) Flush() {
+	l.mu.Lock()
+	summary := l.summary(l.now())
+	l.mu.Unlock()
+	if summary != nil {
+		l.base.Log(context.WithValue(context.Background(), summaryKey{}, true), loggers.WarnLevel, 1, summary...)
+	}
+}
+
+//SetLevel sets the level of the wrapped BaseLogger
+func (l *Logger) SetLevel(level loggers.Level) {
+	l.base.SetLevel(level)
+}
+
+//GetLevel returns the level of the wrapped BaseLogger
+func (l *Logger) GetLevel() loggers.Level {
+	return l.base.GetLevel()
+}
@@ -0,0 +1,171 @@
+package sampler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingLogger struct {
+	mu    sync.Mutex
+	level loggers.Level
+	args  [][]interface{}
+}
+
+func (r *recordingLogger) Log(ctx context.Context, level loggers.Level, skip int, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.args = append(r.args, args)
+}
+
+func (r *recordingLogger) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.args)
+}
+
+func (r *recordingLogger) SetLevel(level loggers.Level) {
+	r.level = level
+}
+
+func (r *recordingLogger) GetLevel() loggers.Level {
+	return r.level
+}
+
+func newTestLogger(config Config) (*Logger, *recordingLogger, *time.Time) {
+	now := time.Unix(1000, 0)
+	base := &recordingLogger{}
+	l := &Logger{base: base, now: func() time.Time { return now }}
+	l.Update(config)
+	return l, base, &now
+}
+
+func TestSamplingPeriodicSummary(t *testing.T) {
+	base := &recordingLogger{}
+	l := NewLogger(base, Config{Enabled: true, First: 1, SummaryInterval: 20 * time.Millisecond})
+	defer l.Stop()
+	for i := 0; i < 3; i++ {
+		l.Log(context.Background(), loggers.ErrorLevel, 0, "boom")
+	}
+	assert.Eventually(t, func() bool { return base.count() == 2 }, time.Second, 5*time.Millisecond)
+}
+
+func TestSamplingStopFlushes(t *testing.T) {
+	base := &recordingLogger{}
+	l := NewLogger(base, Config{Enabled: true, First: 1})
+	l.Log(context.Background(), loggers.ErrorLevel, 0, "boom")
+	l.Log(context.Background(), loggers.ErrorLevel, 0, "boom")
+	l.Stop()
+	assert.Equal(t, 2, base.count())
+	l.Stop()
+	assert.Equal(t, 2, base.count())
+}
+
+func TestSampling(t *testing.T) {
+	l, base, now := newTestLogger(Config{Enabled: true, First: 2, Thereafter: 3})
+	defer l.Stop()
+	ctx := context.Background()
+	for i := 0; i < 8; i++ {
+		l.Log(ctx, loggers.ErrorLevel, 0, "err", errors.New("boom"))
+	}
+	// first 2, then every 3rd: 1, 2, 5, 8
+	assert.Len(t, base.args, 4)
+
+	// other keys are counted separately
+	l.Log(ctx, loggers.ErrorLevel, 0, "err", errors.New("other"))
+	assert.Len(t, base.args, 5)
+
+	// new window
+	*now = now.Add(DefaultInterval)
+	l.Log(ctx, loggers.ErrorLevel, 0, "err", errors.New("boom"))
+	assert.Len(t, base.args, 6)
+}
+
+func TestSamplingLevelRules(t *testing.T) {
+	l, base, _ := newTestLogger(Config{Enabled: true, First: 5, Levels: map[string]Rule{"info": {First: 1}}})
+	defer l.Stop()
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		l.Log(ctx, loggers.InfoLevel, 0, "method", "/pkg.Svc/Get", "took", i)
+		l.Log(ctx, loggers.WarnLevel, 0, "method", "/pkg.Svc/Get", "took", i)
+	}
+	assert.Len(t, base.args, 4)
+}
+
+func TestSamplingSummary(t *testing.T) {
+	l, base, _ := newTestLogger(Config{Enabled: true, First: 1, SummaryInterval: time.Hour})
+	defer l.Stop()
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		l.Log(ctx, loggers.ErrorLevel, 0, "boom")
+	}
+	l.Log(ctx, loggers.ErrorLevel, 0, "other")
+	assert.Len(t, base.args, 2)
+
+	// written by the ticker at the end of each summary interval
+	l.Flush()
+	if assert.Len(t, base.args, 3) {
+		summary := base.args[2]
+		assert.Equal(t, "logs suppressed by sampling", summary[1])
+		assert.Equal(t, uint64(3), summary[3])
+		assert.Equal(t, map[string]uint64{"error|boom": 3}, summary[7])
+	}
+
+	// nothing suppressed since the last summary
+	l.Flush()
+	assert.Len(t, base.args, 3)
+	l.Log(ctx, loggers.ErrorLevel, 0, "other")
+	l.Flush()
+	assert.Len(t, base.args, 4)
+}
+
+func TestSamplingDisabled(t *testing.T) {
+	l, base, _ := newTestLogger(Config{First: 1})
+	for i := 0; i < 3; i++ {
+		l.Log(context.Background(), loggers.ErrorLevel, 0, "boom")
+	}
+	assert.Len(t, base.args, 3)
+}
+
+func TestSampleSummaryNotSampled(t *testing.T) {
+	base := &recordingLogger{}
+	// summaries go back through the hook when Sample is registered on the global logger
+	hook := &hookLogger{base: base}
+	l := NewLogger(hook, Config{Enabled: true, First: 1})
+	defer l.Stop()
+	hook.sample = l.Sample
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		l.Sample(ctx, loggers.WarnLevel, []interface{}{"msg", "logs suppressed by sampling"})
+	}
+	assert.True(t, l.Sample(ctx, loggers.ErrorLevel, []interface{}{"boom"}))
+	assert.False(t, l.Sample(ctx, loggers.ErrorLevel, []interface{}{"boom"}))
+	l.Flush()
+	if assert.Equal(t, 1, base.count()) {
+		assert.Equal(t, "logs suppressed by sampling", base.args[0][1])
+	}
+}
+
+// hookLogger applies a sampler the way the global logger applies hooks
+type hookLogger struct {
+	sample func(context.Context, loggers.Level, []interface{}) bool
+	base   loggers.BaseLogger
+}
+
+func (h *hookLogger) Log(ctx context.Context, level loggers.Level, skip int, args ...interface{}) {
+	if h.sample(ctx, level, args) {
+		h.base.Log(ctx, level, skip+1, args...)
+	}
+}
+
+func (h *hookLogger) SetLevel(level loggers.Level) {}
+
+func (h *hookLogger) GetLevel() loggers.Level {
+	return loggers.DebugLevel
+}
@@ -0,0 +1,307 @@
+package loggers
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	//DefaultScrubMask replaces scrubbed data when no mask is provided
+	DefaultScrubMask = "[REDACTED]"
+	//CardNumberPattern matches card numbers written in groups separated by spaces or dashes e.g. "4111 1111 1111 1111",
+	//matches are only scrubbed when they pass the Luhn check
+	CardNumberPattern = `\b(?:\d{4}[ -]){3}\d{1,7}\b|\b\d{4}[ -]\d{6}[ -]\d{4,5}\b`
+	//CardNumberDigitsPattern matches card numbers of 13 to 19 digits without separators, it is not a default value
+	//pattern since it matches numeric ids and timestamps as well, matches are only scrubbed when they pass the Luhn check
+	CardNumberDigitsPattern = `\b\d{13,19}\b`
+)
+
+var (
+	//DefaultScrubKeys are the field name patterns that are always scrubbed unless DisableDefaults is set
+	DefaultScrubKeys = []string{"password", "passwd", "secret", "token", "authorization", "cookie", "email", "dsn", "apikey", "api_key"}
+	//DefaultScrubValues are the value regexes that are always scrubbed unless DisableDefaults is set
+	DefaultScrubValues = []string{
+		CardNumberPattern,
+		`(?i)\bbearer\s+[a-z0-9\-._~+/]+=*`,
+	}
+
+	scrubber atomic.Value
+)
+
+func init() {
+	s, _ := NewScrubber(ScrubConfig{Enabled: true})
+	SetScrubber(s)
+}
+
+//ScrubConfig configures scrubbing of sensitive data from log fields and error reports
+type ScrubConfig struct {
+	Enabled bool
+	// Keys are case insensitive field name patterns whose values are masked, "token" masks "authToken" as well
+	Keys []string
+	// Values are regular expressions, their matches in string values are masked,
+	// add CardNumberDigitsPattern to mask card numbers written without separators
+	Values []string
+	// Mask replaces scrubbed data, DefaultScrubMask when empty
+	Mask string
+	// DisableDefaults drops DefaultScrubKeys and DefaultScrubValues
+	DisableDefaults bool
+}
+
+type valuePattern struct {
+	re   *regexp.Regexp
+	luhn bool
+}
+
+//Scrubber masks sensitive fields and values, a nil Scrubber does not scrub anything
+type Scrubber struct {
+	keys   []string
+	values []valuePattern
+	mask   string
+}
+
+//NewScrubber creates a Scrubber from config, it returns nil when config is not enabled
+func NewScrubber(config ScrubConfig) (*Scrubber, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+	keys, values := config.Keys, config.Values
+	if !config.DisableDefaults {
+		keys = append(append([]string{}, DefaultScrubKeys...), keys...)
+		values = append(append([]string{}, DefaultScrubValues...), values...)
+	}
+	s := &Scrubber{mask: config.Mask}
+	if s.mask == "" {
+		s.mask = DefaultScrubMask
+	}
+	for _, key := range keys {
+		if key = normalizeKey(key); key != "" {
+			s.keys = append(s.keys, key)
+		}
+	}
+	for _, value := range values {
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scrub pattern %q: %s", value, err)
+		}
+		s.values = append(s.values, valuePattern{re: re, luhn: value == CardNumberPattern || value == CardNumberDigitsPattern})
+	}
+	return s, nil
+}
+
+//SetScrubber sets the scrubber applied to all logs and error reports, nil disables scrubbing
+func SetScrubber(s *Scrubber) {
+	scrubber.Store(s)
+}
+
+//GetScrubber returns the scrubber applied to all logs and error reports
+func GetScrubber() *Scrubber {
+	s, _ := scrubber.Load().(*Scrubber)
+	return s
+}
+
+// normalizeKey lowercases key and drops separators so that "api-key", "api_key" and "apiKey" look alike
+func normalizeKey(key string) string {
+	return strings.NewReplacer("-", "", "_", "", ".", "", " ", "").Replace(strings.ToLower(key))
+}
+
+//IsSensitiveKey returns true when the value of key has to be masked
+func (s *Scrubber) IsSensitiveKey(key string) bool {
+	if s == nil {
+		return false
+	}
+	key = normalizeKey(key)
+	for _, k := range s.keys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+//String masks the matches of value patterns in v
+func (s *Scrubber) String(v string) string {
+	if s == nil {
+		return v
+	}
+	for _, p := range s.values {
+		if p.luhn {
+			v = p.re.ReplaceAllStringFunc(v, func(match string) string {
+				if luhnValid(match) {
+					return s.mask
+				}
+				return match
+			})
+		} else {
+			v = p.re.ReplaceAllString(v, s.mask)
+		}
+	}
+	return v
+}
+
+//Value returns value with sensitive data masked, key is the field name of value
+func (s *Scrubber) Value(key string, value interface{}) interface{} {
+	if s == nil || value == nil {
+		return value
+	}
+	if s.IsSensitiveKey(key) {
+		return s.mask
+	}
+	switch v := value.(type) {
+	case string:
+		return s.String(v)
+	case []string:
+		scrubbed := make([]string, len(v))
+		for i := range v {
+			scrubbed[i] = s.String(v[i])
+		}
+		return scrubbed
+	case map[string]string:
+		scrubbed := make(map[string]string, len(v))
+		for k, val := range v {
+			if s.IsSensitiveKey(k) {
+				scrubbed[k] = s.mask
+			} else {
+				scrubbed[k] = s.String(val)
+			}
+		}
+		return scrubbed
+	case map[string][]string:
+		scrubbed := make(map[string][]string, len(v))
+		for k, val := range v {
+			if s.IsSensitiveKey(k) {
+				scrubbed[k] = []string{s.mask}
+			} else {
+				scrubbed[k] = s.Value("", val).([]string)
+			}
+		}
+		return scrubbed
+	case http.Header:
+		return http.Header(s.Value("", map[string][]string(v)).(map[string][]string))
+	case map[string]interface{}:
+		return s.Fields(v)
+	case LogFields:
+		return LogFields(s.Fields(v))
+	case []interface{}:
+		scrubbed := make([]interface{}, len(v))
+		for i := range v {
+			scrubbed[i] = s.Value("", v[i])
+		}
+		return scrubbed
+	case error:
+		// errors are kept as is unless their message has to be masked
+		if msg := v.Error(); s.String(msg) != msg {
+			return s.String(msg)
+		}
+		return value
+	case json.Marshaler, encoding.TextMarshaler:
+		// e.g. time.Time, encoded as a single value
+		return value
+	}
+	if isStruct(value) {
+		return s.structValue(value)
+	}
+	return value
+}
+
+// isStruct returns true for structs and pointers to structs, protos included
+func isStruct(value interface{}) bool {
+	t := reflect.TypeOf(value)
+	if t.Kind() == reflect.Ptr {
+		if reflect.ValueOf(value).IsNil() {
+			return false
+		}
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// structValue scrubs a struct through its JSON encoding, fields are matched by their JSON names
+func (s *Scrubber) structValue(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return s.mask
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return s.mask
+	}
+	return s.Value("", decoded)
+}
+
+//Fields returns a copy of fields with sensitive data masked
+func (s *Scrubber) Fields(fields map[string]interface{}) map[string]interface{} {
+	if s == nil || fields == nil {
+		return fields
+	}
+	scrubbed := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		scrubbed[k] = s.Value(k, v)
+	}
+	return scrubbed
+}
+
+//Args returns a copy of key value log arguments with sensitive data masked, a leading message is scrubbed as well
+func (s *Scrubber) Args(args []interface{}) []interface{} {
+	if s == nil || len(args) == 0 {
+		return args
+	}
+	scrubbed := make([]interface{}, len(args))
+	i := 0
+	if len(args)%2 == 1 {
+		scrubbed[0] = s.Value("", args[0])
+		i = 1
+	}
+	for ; i+1 < len(args); i += 2 {
+		scrubbed[i] = args[i]
+		scrubbed[i+1] = s.Value(fmt.Sprint(args[i]), args[i+1])
+	}
+	return scrubbed
+}
+
+//Context returns ctx with the log fields of ctx scrubbed, log fields are shared by the request and are not modified
+func (s *Scrubber) Context(ctx context.Context) context.Context {
+	if s == nil {
+		return ctx
+	}
+	if fields := FromContext(ctx); len(fields) > 0 {
+		return context.WithValue(ctx, contextKey, LogFields(s.Fields(fields)))
+	}
+	return ctx
+}
+
+//Scrub masks sensitive data in the log fields of ctx and in args using the global scrubber
+func Scrub(ctx context.Context, args []interface{}) (context.Context, []interface{}) {
+	s := GetScrubber()
+	if s == nil {
+		return ctx, args
+	}
+	return s.Context(ctx), s.Args(args)
+}
+
+// luhnValid returns true when the digits of number pass the Luhn checksum
+func luhnValid(number string) bool {
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
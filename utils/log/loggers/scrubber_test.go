@@ -0,0 +1,107 @@
+package loggers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrubberKeys(t *testing.T) {
+	s, err := NewScrubber(ScrubConfig{Enabled: true, Keys: []string{"ssn"}})
+	assert.NoError(t, err)
+	assert.True(t, s.IsSensitiveKey("Authorization"))
+	assert.True(t, s.IsSensitiveKey("user_password"))
+	assert.True(t, s.IsSensitiveKey("authToken"))
+	assert.True(t, s.IsSensitiveKey("API-Key"))
+	assert.True(t, s.IsSensitiveKey("SSN"))
+	assert.False(t, s.IsSensitiveKey("method"))
+
+	assert.Equal(t, map[string]interface{}{
+		"email":   DefaultScrubMask,
+		"method":  "/listing.Listing/Create",
+		"headers": map[string][]string{"Cookie": {DefaultScrubMask}, "Accept": {"application/json"}},
+		"user":    map[string]interface{}{"id": 1, "password": DefaultScrubMask},
+	}, s.Fields(map[string]interface{}{
+		"email":   "someone@carousell.com",
+		"method":  "/listing.Listing/Create",
+		"headers": map[string][]string{"Cookie": {"session=1"}, "Accept": {"application/json"}},
+		"user":    map[string]interface{}{"id": 1, "password": "hunter2"},
+	}))
+}
+
+func TestScrubberValues(t *testing.T) {
+	s, err := NewScrubber(ScrubConfig{Enabled: true, Values: []string{`\bS\d{7}[A-Z]\b`}, Mask: "***"})
+	assert.NoError(t, err)
+	assert.Equal(t, "paid with ***", s.String("paid with 4111 1111 1111 1111"))
+	assert.Equal(t, "paid with ***", s.String("paid with 4111-1111-1111-1111"))
+	// not a valid card number
+	assert.Equal(t, "listing 1234567890123456", s.String("listing 1234567890123456"))
+	assert.Equal(t, "header ***", s.String("header Bearer abc.def-ghi"))
+	assert.Equal(t, "nric ***", s.String("nric S1234567D"))
+	assert.Equal(t, "card ***", s.Value("", errors.New("card 4111 1111 1111 1111")))
+	// numeric ids are only checked when card numbers without separators are opted in
+	assert.Equal(t, "listing 4111111111111111", s.String("listing 4111111111111111"))
+	digits, err := NewScrubber(ScrubConfig{Enabled: true, Values: []string{CardNumberDigitsPattern}, Mask: "***"})
+	assert.NoError(t, err)
+	assert.Equal(t, "card ***", digits.String("card 4111111111111111"))
+	assert.Equal(t, "at 1700000000000", digits.String("at 1700000000000"))
+	e := errors.New("not found")
+	assert.Equal(t, e, s.Value("", e))
+
+	_, err = NewScrubber(ScrubConfig{Enabled: true, Values: []string{"("}})
+	assert.Error(t, err)
+}
+
+type scrubUser struct {
+	ID       int    `json:"id"`
+	Password string `json:"password"`
+	Note     string `json:"note"`
+}
+
+func TestScrubberStructs(t *testing.T) {
+	s, err := NewScrubber(ScrubConfig{Enabled: true})
+	assert.NoError(t, err)
+	user := scrubUser{ID: 1, Password: "hunter2", Note: "Bearer abc"}
+	expected := map[string]interface{}{"id": float64(1), "password": DefaultScrubMask, "note": DefaultScrubMask}
+	assert.Equal(t, expected, s.Value("", user))
+	assert.Equal(t, expected, s.Value("", &user))
+	assert.Equal(t, []interface{}{expected}, s.Value("", []interface{}{user}))
+
+	var missing *scrubUser
+	assert.Equal(t, missing, s.Value("", missing))
+	now := time.Now()
+	assert.Equal(t, now, s.Value("", now))
+}
+
+func TestScrubberDisabled(t *testing.T) {
+	s, err := NewScrubber(ScrubConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+	assert.Equal(t, "hunter2", s.Value("password", "hunter2"))
+
+	s, err = NewScrubber(ScrubConfig{Enabled: true, DisableDefaults: true, Keys: []string{"ssn"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", s.Value("password", "hunter2"))
+	assert.Equal(t, DefaultScrubMask, s.Value("ssn", "S1234567D"))
+}
+
+func TestScrub(t *testing.T) {
+	defer SetScrubber(GetScrubber())
+	s, _ := NewScrubber(ScrubConfig{Enabled: true})
+	SetScrubber(s)
+
+	ctx := AddToLogContext(context.Background(), "token", "abc")
+	ctx = AddToLogContext(ctx, "path", "/login")
+	scrubbedCtx, args := Scrub(ctx, []interface{}{"login", "password", "hunter2", "user", 1})
+	assert.Equal(t, []interface{}{"login", "password", DefaultScrubMask, "user", 1}, args)
+	assert.Equal(t, LogFields{"token": DefaultScrubMask, "path": "/login"}, FromContext(scrubbedCtx))
+	// fields of the request are not modified
+	assert.Equal(t, "abc", FromContext(ctx)["token"])
+
+	SetScrubber(nil)
+	_, args = Scrub(ctx, []interface{}{"password", "hunter2"})
+	assert.Equal(t, []interface{}{"password", "hunter2"}, args)
+}
@@ -0,0 +1,161 @@
+//Package slog provides BaseLogger implementation for log/slog
+package slog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	log "log/slog"
+	"os"
+
+	"github.com/carousell/Orion/utils/log/loggers"
+)
+
+type logger struct {
+	logger *log.Logger
+	level  loggers.Level
+	opt    loggers.Options
+}
+
+func toSlogLevel(level loggers.Level) log.Level {
+	switch level {
+	case loggers.DebugLevel:
+		return log.LevelDebug
+	case loggers.InfoLevel:
+		return log.LevelInfo
+	case loggers.WarnLevel:
+		return log.LevelWarn
+	default:
+		return log.LevelError
+	}
+}
+
+func fromSlogLevel(level log.Level) loggers.Level {
+	switch {
+	case level < log.LevelInfo:
+		return loggers.DebugLevel
+	case level < log.LevelWarn:
+		return loggers.InfoLevel
+	case level < log.LevelError:
+		return loggers.WarnLevel
+	default:
+		return loggers.ErrorLevel
+	}
+}
+
+// splitArgs treats a single or leading odd argument, or a leading "msg" pair, as the message
+// and the rest as key value pairs
+func splitArgs(args []interface{}) (string, []interface{}) {
+	if len(args)%2 == 1 {
+		return fmt.Sprint(args[0]), args[1:]
+	}
+	if len(args) >= 2 && args[0] == "msg" {
+		return fmt.Sprint(args[1]), args[2:]
+	}
+	return "", args
+}
+
+func (l *logger) Log(ctx context.Context, level loggers.Level, skip int, args ...interface{}) {
+	// the handler accepts all levels, filtering here honors the level override in context
+	if !loggers.IsLevelEnabled(ctx, l.level, level) {
+		return
+	}
+	attrs := make([]log.Attr, 0, len(args)/2+1)
+
+	if l.opt.CallerInfo {
+		_, file, line := loggers.FetchCallerInfo(skip+1, l.opt.CallerFileDepth)
+		attrs = append(attrs, log.String(l.opt.CallerFieldName, fmt.Sprintf("%s:%d", file, line)))
+	}
+
+	// fetch fields from context and add them to slog attributes
+	ctxFields := loggers.FromContext(ctx)
+	if ctxFields != nil {
+		for k, v := range ctxFields {
+			attrs = append(attrs, log.Any(k, v))
+		}
+	}
+
+	msg, kv := splitArgs(args)
+	for i := 0; i+1 < len(kv); i += 2 {
+		attrs = append(attrs, log.Any(fmt.Sprint(kv[i]), kv[i+1]))
+	}
+	l.logger.LogAttrs(ctx, toSlogLevel(level), msg, attrs...)
+}
+
+func (l *logger) SetLevel(level loggers.Level) {
+	l.level = level
+}
+
+func (l *logger) GetLevel() loggers.Level {
+	return l.level
+}
+
+func replaceAttr(opt loggers.Options) func(groups []string, a log.Attr) log.Attr {
+	return func(groups []string, a log.Attr) log.Attr {
+		if len(groups) > 0 {
+			return a
+		}
+		switch a.Key {
+		case log.TimeKey:
+			a.Key = opt.TimestampFieldName
+		case log.LevelKey:
+			a.Key = opt.LevelFieldName
+			if level, ok := a.Value.Any().(log.Level); ok {
+				// same level names as the other BaseLoggers
+				a.Value = log.StringValue(fromSlogLevel(level).String())
+			}
+		}
+		return a
+	}
+}
+
+//NewLogger returns a BaseLogger impl for log/slog
+func NewLogger(options ...loggers.Option) loggers.BaseLogger {
+	// default options
+	opt := loggers.GetDefaultOptions()
+	// read options
+	for _, f := range options {
+		f(&opt)
+	}
+
+	var w io.Writer = os.Stdout
+	if opt.Writer != nil {
+		w = opt.Writer
+	}
+
+	handlerOpts := &log.HandlerOptions{
+		Level:       log.LevelDebug,
+		ReplaceAttr: replaceAttr(opt),
+	}
+	var handler log.Handler
+	// check for json or text
+	if opt.JSONLogs {
+		handler = log.NewJSONHandler(w, handlerOpts)
+	} else {
+		handler = log.NewTextHandler(w, handlerOpts)
+	}
+	return newLoggerWithHandler(handler, opt)
+}
+
+func newLoggerWithHandler(handler log.Handler, opt loggers.Options) loggers.BaseLogger {
+	l := logger{
+		logger: log.New(handler),
+		level:  opt.Level,
+		opt:    opt,
+	}
+	if opt.ReplaceStdLogger {
+		// output of the std "log" package is sent to the handler as well
+		log.SetDefault(l.logger)
+	}
+	return &l
+}
+
+//NewLoggerWithHandler returns a BaseLogger impl for log/slog that writes to an existing slog.Handler,
+//formatting options (JSONLogs, TimestampFieldName, LevelFieldName) are decided by the handler
+func NewLoggerWithHandler(handler log.Handler, options ...loggers.Option) loggers.BaseLogger {
+	opt := loggers.GetDefaultOptions()
+	for _, f := range options {
+		f(&opt)
+	}
+	return newLoggerWithHandler(handler, opt)
+}
@@ -0,0 +1,46 @@
+package slog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := NewLogger(loggers.WithWriter(buf), loggers.WithTimestampFieldName("ts"), loggers.WithCallerFieldName("src"))
+	ctx := loggers.AddToLogContext(context.Background(), "trace", "abc")
+
+	l.Log(ctx, loggers.InfoLevel, 0, "msg", "hello", "count", 2)
+	entry := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "hello", entry["msg"])
+	assert.Equal(t, "info", entry["level"])
+	assert.Equal(t, "abc", entry["trace"])
+	assert.Equal(t, float64(2), entry["count"])
+	assert.NotNil(t, entry["ts"])
+	assert.True(t, strings.HasPrefix(entry["src"].(string), "slog/slog_test.go:"), entry["src"])
+
+	// filtered by level unless escalated through context
+	buf.Reset()
+	l.Log(ctx, loggers.DebugLevel, 0, "hidden")
+	assert.Empty(t, buf.String())
+	l.Log(loggers.AddLevelToContext(ctx, loggers.DebugLevel), loggers.DebugLevel, 0, "visible")
+	assert.Contains(t, buf.String(), `"msg":"visible"`)
+	assert.Contains(t, buf.String(), `"level":"debug"`)
+}
+
+func TestTextLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := NewLogger(loggers.WithWriter(buf), loggers.WithJSONLogs(false), loggers.WithCallerInfo(false))
+	l.Log(context.Background(), loggers.WarnLevel, 0, "disk full", "free", "1%")
+	assert.Contains(t, buf.String(), "level=warning")
+	assert.Contains(t, buf.String(), `msg="disk full"`)
+	assert.Contains(t, buf.String(), "free=1%")
+	assert.NotContains(t, buf.String(), "caller")
+}
@@ -21,7 +21,7 @@ func (l *logger) GetLevel() loggers.Level {
 }
 
 func (l *logger) Log(ctx context.Context, level loggers.Level, skip int, args ...interface{}) {
-	if l.level >= level {
+	if loggers.IsLevelEnabled(ctx, l.level, level) {
 		// fetch fields from context and add them to logrus fields
 		ctxFields := loggers.FromContext(ctx)
 		if ctxFields != nil {
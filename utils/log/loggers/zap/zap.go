@@ -0,0 +1,155 @@
+//Package zap provides BaseLogger implementation for uber-go/zap
+package zap
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/carousell/Orion/utils/log/loggers"
+	log "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type logger struct {
+	logger *log.Logger
+	level  loggers.Level
+	opt    loggers.Options
+}
+
+func toZapLevel(level loggers.Level) zapcore.Level {
+	switch level {
+	case loggers.DebugLevel:
+		return zapcore.DebugLevel
+	case loggers.InfoLevel:
+		return zapcore.InfoLevel
+	case loggers.WarnLevel:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+func fromZapLevel(level zapcore.Level) loggers.Level {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return loggers.DebugLevel
+	case level == zapcore.InfoLevel:
+		return loggers.InfoLevel
+	case level == zapcore.WarnLevel:
+		return loggers.WarnLevel
+	default:
+		return loggers.ErrorLevel
+	}
+}
+
+// levelEncoder uses the same level names as the other BaseLoggers
+func levelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(fromZapLevel(level).String())
+}
+
+// splitArgs treats a single or leading odd argument, or a leading "msg" pair, as the message
+// and the rest as key value pairs
+func splitArgs(args []interface{}) (string, []interface{}) {
+	if len(args)%2 == 1 {
+		return fmt.Sprint(args[0]), args[1:]
+	}
+	if len(args) >= 2 && args[0] == "msg" {
+		return fmt.Sprint(args[1]), args[2:]
+	}
+	return "", args
+}
+
+func (l *logger) Log(ctx context.Context, level loggers.Level, skip int, args ...interface{}) {
+	// the core accepts all levels, filtering here honors the level override in context
+	if !loggers.IsLevelEnabled(ctx, l.level, level) {
+		return
+	}
+	msg, kv := splitArgs(args)
+	ce := l.logger.Check(toZapLevel(level), msg)
+	if ce == nil {
+		return
+	}
+	fields := make([]zapcore.Field, 0, len(kv)/2+1)
+
+	if l.opt.CallerInfo {
+		_, file, line := loggers.FetchCallerInfo(skip+1, l.opt.CallerFileDepth)
+		fields = append(fields, log.String(l.opt.CallerFieldName, fmt.Sprintf("%s:%d", file, line)))
+	}
+
+	// fetch fields from context and add them to zap fields
+	ctxFields := loggers.FromContext(ctx)
+	if ctxFields != nil {
+		for k, v := range ctxFields {
+			fields = append(fields, log.Any(k, v))
+		}
+	}
+
+	for i := 0; i+1 < len(kv); i += 2 {
+		fields = append(fields, log.Any(fmt.Sprint(kv[i]), kv[i+1]))
+	}
+	ce.Write(fields...)
+}
+
+func (l *logger) SetLevel(level loggers.Level) {
+	l.level = level
+}
+
+func (l *logger) GetLevel() loggers.Level {
+	return l.level
+}
+
+//NewLogger returns a BaseLogger impl for zap
+func NewLogger(options ...loggers.Option) loggers.BaseLogger {
+	// default options
+	opt := loggers.GetDefaultOptions()
+	// read options
+	for _, f := range options {
+		f(&opt)
+	}
+
+	ws := zapcore.Lock(os.Stdout)
+	if opt.Writer != nil {
+		ws = zapcore.Lock(zapcore.AddSync(opt.Writer))
+	}
+
+	encoderConfig := zapcore.EncoderConfig{
+		MessageKey:     "msg",
+		LevelKey:       opt.LevelFieldName,
+		TimeKey:        opt.TimestampFieldName,
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    levelEncoder,
+		EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	}
+	var encoder zapcore.Encoder
+	// check for json or console
+	if opt.JSONLogs {
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	} else {
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	}
+	return newLoggerWithZap(log.New(zapcore.NewCore(encoder, ws, zapcore.DebugLevel)), opt)
+}
+
+func newLoggerWithZap(zl *log.Logger, opt loggers.Options) loggers.BaseLogger {
+	l := logger{
+		logger: zl,
+		level:  opt.Level,
+		opt:    opt,
+	}
+	if opt.ReplaceStdLogger {
+		log.RedirectStdLog(l.logger)
+	}
+	return &l
+}
+
+//NewLoggerWithZap returns a BaseLogger impl that writes to an existing zap.Logger,
+//formatting options (JSONLogs, TimestampFieldName, LevelFieldName) are decided by the zap.Logger
+func NewLoggerWithZap(zl *log.Logger, options ...loggers.Option) loggers.BaseLogger {
+	opt := loggers.GetDefaultOptions()
+	for _, f := range options {
+		f(&opt)
+	}
+	return newLoggerWithZap(zl, opt)
+}
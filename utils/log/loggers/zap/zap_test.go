@@ -0,0 +1,37 @@
+package zap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := NewLogger(loggers.WithWriter(buf), loggers.WithLevelFieldName("severity"))
+	ctx := loggers.AddToLogContext(context.Background(), "trace", "abc")
+
+	l.Log(ctx, loggers.WarnLevel, 0, "hello", "count", 2)
+	entry := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "hello", entry["msg"])
+	assert.Equal(t, "warning", entry["severity"])
+	assert.Equal(t, "abc", entry["trace"])
+	assert.Equal(t, float64(2), entry["count"])
+	assert.NotNil(t, entry["@timestamp"])
+	assert.True(t, strings.HasPrefix(entry["caller"].(string), "zap/zap_test.go:"), entry["caller"])
+
+	// filtered by level unless escalated through context
+	buf.Reset()
+	l.Log(ctx, loggers.DebugLevel, 0, "hidden")
+	assert.Empty(t, buf.String())
+	l.SetLevel(loggers.DebugLevel)
+	l.Log(ctx, loggers.DebugLevel, 0, "msg", "visible")
+	assert.Contains(t, buf.String(), `"msg":"visible"`)
+	assert.Equal(t, loggers.Level(loggers.DebugLevel), l.GetLevel())
+}
@@ -0,0 +1,88 @@
+package wrap
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/carousell/Orion/utils/log"
+	"github.com/carousell/Orion/utils/log/loggers"
+)
+
+/*
+ * slogwrap wraps the log.Logger impl with slog.Handler
+ */
+type slogwrap struct {
+	l      log.Logger
+	attrs  []interface{}
+	prefix string
+}
+
+func fromSlogLevel(level slog.Level) loggers.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return loggers.DebugLevel
+	case level < slog.LevelWarn:
+		return loggers.InfoLevel
+	case level < slog.LevelError:
+		return loggers.WarnLevel
+	default:
+		return loggers.ErrorLevel
+	}
+}
+
+func (s *slogwrap) Enabled(ctx context.Context, level slog.Level) bool {
+	return loggers.IsLevelEnabled(ctx, s.l.GetLevel(), fromSlogLevel(level))
+}
+
+// appendAttr flattens groups into dotted keys
+func appendAttr(vals []interface{}, prefix string, attr slog.Attr) []interface{} {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, a := range value.Group() {
+			vals = appendAttr(vals, prefix, a)
+		}
+		return vals
+	}
+	if attr.Key == "" {
+		return vals
+	}
+	return append(vals, prefix+attr.Key, value.Any())
+}
+
+func (s *slogwrap) Handle(ctx context.Context, r slog.Record) error {
+	vals := make([]interface{}, 0, 2+len(s.attrs)+r.NumAttrs()*2)
+	vals = append(vals, "msg", r.Message)
+	vals = append(vals, s.attrs...)
+	r.Attrs(func(attr slog.Attr) bool {
+		vals = appendAttr(vals, s.prefix, attr)
+		return true
+	})
+	// skip slog.Logger.log and slog.Logger.<Level> to report the caller of slog
+	s.l.Log(ctx, fromSlogLevel(r.Level), 3, vals...)
+	return nil
+}
+
+func (s *slogwrap) WithAttrs(attrs []slog.Attr) slog.Handler {
+	vals := append([]interface{}{}, s.attrs...)
+	for _, attr := range attrs {
+		vals = appendAttr(vals, s.prefix, attr)
+	}
+	return &slogwrap{l: s.l, attrs: vals, prefix: s.prefix}
+}
+
+func (s *slogwrap) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return s
+	}
+	return &slogwrap{l: s.l, attrs: s.attrs, prefix: s.prefix + name + "."}
+}
+
+// ToSlogHandler wraps a log.Logger to slog.Handler
+func ToSlogHandler(l log.Logger) slog.Handler {
+	return &slogwrap{
+		l: l,
+	}
+}
@@ -0,0 +1,49 @@
+package wrap
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/carousell/Orion/utils/log"
+	"github.com/carousell/Orion/utils/log/loggers"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingLogger struct {
+	level  loggers.Level
+	levels []loggers.Level
+	args   [][]interface{}
+	caller string
+}
+
+func (r *recordingLogger) Log(ctx context.Context, level loggers.Level, skip int, args ...interface{}) {
+	_, r.caller, _ = loggers.FetchCallerInfo(skip+1, 1)
+	r.levels = append(r.levels, level)
+	r.args = append(r.args, args)
+}
+
+func (r *recordingLogger) SetLevel(level loggers.Level) {
+	r.level = level
+}
+
+func (r *recordingLogger) GetLevel() loggers.Level {
+	return r.level
+}
+
+func TestToSlogHandler(t *testing.T) {
+	base := &recordingLogger{level: loggers.InfoLevel}
+	logger := slog.New(ToSlogHandler(log.NewLogger(base)))
+
+	logger.Debug("hidden")
+	logger.With("svc", "orders").WithGroup("req").Warn("slow", "took", 3, slog.Group("db", "rows", 10))
+	if assert.Len(t, base.args, 1) {
+		assert.Equal(t, loggers.Level(loggers.WarnLevel), base.levels[0])
+		assert.Equal(t, []interface{}{"msg", "slow", "svc", "orders", "req.took", int64(3), "req.db.rows", int64(10)}, base.args[0])
+		assert.Equal(t, "slogwrap_test.go", base.caller)
+	}
+
+	// debug logs are enabled through the context level override
+	logger.DebugContext(loggers.AddLevelToContext(context.Background(), loggers.DebugLevel), "visible")
+	assert.Len(t, base.args, 2)
+}
@@ -0,0 +1,332 @@
+//Package openapi builds OpenAPI 3 documents for unary gRPC methods served over HTTP by Orion,
+//schemas are resolved from proto descriptors
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//Version is the version of the OpenAPI specification generated
+const Version = "3.0.3"
+
+var pathParamRegex = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+//Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+//Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+//Components holds the schemas referenced by operations
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+//Operation is a single HTTP method on a path
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+//Parameter is a path parameter of an operation
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+//RequestBody is the body of an operation
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+//Response is a response of an operation
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+//MediaType is the schema of a body for a content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+//Schema is a subset of the OpenAPI schema object
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+}
+
+//Route is an HTTP route served by a unary gRPC method
+type Route struct {
+	// Service is the full name of the gRPC service
+	Service string
+	Method  string
+	// HTTPMethods served on Path, gorilla/mux path variables ({name:pattern}) are allowed
+	HTTPMethods []string
+	Path        string
+	Summary     string
+}
+
+//Resolver finds descriptors by their full name, protoregistry.Files implements it
+type Resolver interface {
+	FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error)
+}
+
+//Options configures how a Document is built
+type Options struct {
+	// JSONPB describes bodies encoded by jsonpb instead of encoding/json
+	JSONPB bool
+	// Comment returns the description of a message or field, nil leaves descriptions empty
+	Comment func(protoreflect.Descriptor) string
+}
+
+//NewDocument creates an empty Document
+func NewDocument(title, description string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       title,
+			Description: description,
+			Version:     "1.0.0",
+		},
+		Paths: make(map[string]map[string]*Operation),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+		},
+	}
+}
+
+//Build creates a Document describing routes, request and response schemas are looked up in resolver
+func Build(title, description string, routes []Route, resolver Resolver, opts Options) *Document {
+	doc := NewDocument(title, description)
+	for _, route := range routes {
+		doc.Add(route, resolver, opts)
+	}
+	return doc
+}
+
+//Add adds the operations of route to the Document
+func (doc *Document) Add(route Route, resolver Resolver, opts Options) {
+	b := &builder{schemas: doc.Components.Schemas, opts: opts}
+	var input, output protoreflect.MessageDescriptor
+	if d, err := resolver.FindDescriptorByName(protoreflect.FullName(route.Service)); err == nil {
+		if sd, ok := d.(protoreflect.ServiceDescriptor); ok {
+			if md := sd.Methods().ByName(protoreflect.Name(route.Method)); md != nil {
+				input, output = md.Input(), md.Output()
+			}
+		}
+	}
+
+	// gorilla/mux allows {name:pattern}, OpenAPI only allows {name}
+	params := make([]*Parameter, 0)
+	for _, match := range pathParamRegex.FindAllStringSubmatch(route.Path, -1) {
+		p := &Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		}
+		if fd := findPathField(input, match[1]); fd != nil {
+			p.Schema = b.scalarSchema(fd)
+		}
+		params = append(params, p)
+	}
+	path := pathParamRegex.ReplaceAllString(route.Path, "{$1}")
+
+	for _, method := range route.HTTPMethods {
+		method = strings.ToLower(strings.TrimSpace(method))
+		if method == "" {
+			continue
+		}
+		op := &Operation{
+			OperationID: route.Service + "_" + route.Method,
+			Summary:     route.Summary,
+			Tags:        []string{route.Service},
+			Responses: map[string]*Response{
+				"200": {
+					Description: "OK",
+					Content:     jsonContent(b.messageSchema(output)),
+				},
+				"default": {
+					Description: "Error",
+					Content: map[string]*MediaType{
+						"text/plain": {Schema: &Schema{Type: "string"}},
+					},
+				},
+			},
+		}
+		if len(route.HTTPMethods) > 1 {
+			op.OperationID += "_" + strings.ToUpper(method)
+		}
+		if len(params) > 0 {
+			op.Parameters = params
+		}
+		if method != "get" && method != "head" && method != "delete" {
+			op.RequestBody = &RequestBody{
+				Content: jsonContent(b.messageSchema(input)),
+			}
+		}
+		if _, ok := doc.Paths[path]; !ok {
+			doc.Paths[path] = make(map[string]*Operation)
+		}
+		doc.Paths[path][method] = op
+	}
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{
+		"application/json": {Schema: schema},
+	}
+}
+
+// findPathField finds the field that a gorilla/mux path variable is decoded into,
+// mapstructure matches go field names case insensitively
+func findPathField(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if md == nil {
+		return nil
+	}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if strings.EqualFold(string(fd.Name()), name) || strings.EqualFold(fd.JSONName(), name) ||
+			strings.EqualFold(strings.Replace(string(fd.Name()), "_", "", -1), name) {
+			return fd
+		}
+	}
+	return nil
+}
+
+type builder struct {
+	schemas map[string]*Schema
+	opts    Options
+}
+
+func (b *builder) comment(d protoreflect.Descriptor) string {
+	if b.opts.Comment == nil {
+		return ""
+	}
+	return b.opts.Comment(d)
+}
+
+// messageSchema adds the schema of md and all messages it references, and returns a reference to it
+func (b *builder) messageSchema(md protoreflect.MessageDescriptor) *Schema {
+	if md == nil {
+		// unknown message, allow anything
+		return &Schema{Type: "object"}
+	}
+	name := string(md.FullName())
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if _, ok := b.schemas[name]; ok {
+		return ref
+	}
+	s := &Schema{
+		Type:        "object",
+		Description: b.comment(md),
+		Properties:  make(map[string]*Schema),
+	}
+	// add before walking fields to handle recursive messages
+	b.schemas[name] = s
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		fs := b.fieldSchema(fd)
+		if desc := b.comment(fd); desc != "" && fs.Ref == "" {
+			fs.Description = desc
+		}
+		// encoding/json uses the json tag generated by protoc-gen-go, which is the proto field name
+		fieldName := string(fd.Name())
+		if b.opts.JSONPB {
+			fieldName = fd.JSONName()
+		}
+		s.Properties[fieldName] = fs
+	}
+	return ref
+}
+
+func (b *builder) fieldSchema(fd protoreflect.FieldDescriptor) *Schema {
+	if fd.IsMap() {
+		return &Schema{
+			Type:                 "object",
+			AdditionalProperties: b.scalarSchema(fd.MapValue()),
+		}
+	}
+	if fd.IsList() {
+		return &Schema{
+			Type:  "array",
+			Items: b.scalarSchema(fd),
+		}
+	}
+	return b.scalarSchema(fd)
+}
+
+func (b *builder) scalarSchema(fd protoreflect.FieldDescriptor) *Schema {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return b.messageSchema(fd.Message())
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		s := &Schema{Type: "integer", Format: "int32"}
+		if b.opts.JSONPB {
+			s = &Schema{Type: "string"}
+		}
+		names := make([]string, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			v := values.Get(i)
+			if b.opts.JSONPB {
+				s.Enum = append(s.Enum, string(v.Name()))
+			} else {
+				s.Enum = append(s.Enum, v.Number())
+				names = append(names, fmt.Sprintf("%d=%s", v.Number(), v.Name()))
+			}
+		}
+		if len(names) > 0 {
+			s.Description = string(fd.Enum().FullName()) + ": " + strings.Join(names, ", ")
+		}
+		return s
+	case protoreflect.BoolKind:
+		return &Schema{Type: "boolean"}
+	case protoreflect.StringKind:
+		return &Schema{Type: "string"}
+	case protoreflect.BytesKind:
+		return &Schema{Type: "string", Format: "byte"}
+	case protoreflect.DoubleKind:
+		return &Schema{Type: "number", Format: "double"}
+	case protoreflect.FloatKind:
+		return &Schema{Type: "number", Format: "float"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if b.opts.JSONPB {
+			// jsonpb encodes 64 bit integers as strings
+			return &Schema{Type: "string", Format: "int64"}
+		}
+		return &Schema{Type: "integer", Format: "int64"}
+	default:
+		return &Schema{Type: "integer", Format: "int32"}
+	}
+}
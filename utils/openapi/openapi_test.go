@@ -0,0 +1,51 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	_ "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+func TestBuild(t *testing.T) {
+	routes := []Route{
+		{Service: "grpc.health.v1.Health", Method: "Check", HTTPMethods: []string{"GET", "POST"}, Path: "/check/{service:[a-z]+}", Summary: "check"},
+		{Service: "unknown.Svc", Method: "Get", HTTPMethods: []string{"POST"}, Path: "/unknown"},
+	}
+	doc := Build("test", "", routes, protoregistry.GlobalFiles, Options{})
+	assert.Equal(t, Version, doc.OpenAPI)
+
+	get := doc.Paths["/check/{service}"]["get"]
+	if assert.NotNil(t, get) {
+		assert.Equal(t, "grpc.health.v1.Health_Check_GET", get.OperationID)
+		assert.Equal(t, "check", get.Summary)
+		assert.Nil(t, get.RequestBody)
+		if assert.Len(t, get.Parameters, 1) {
+			assert.Equal(t, "service", get.Parameters[0].Name)
+			assert.Equal(t, "string", get.Parameters[0].Schema.Type)
+		}
+	}
+	assert.NotNil(t, doc.Paths["/check/{service}"]["post"].RequestBody)
+	assert.Equal(t, &Schema{Type: "object"}, doc.Paths["/unknown"]["post"].RequestBody.Content["application/json"].Schema)
+
+	status := doc.Components.Schemas["grpc.health.v1.HealthCheckResponse"].Properties["status"]
+	assert.Equal(t, "integer", status.Type)
+	assert.Contains(t, status.Description, "1=SERVING")
+}
+
+func TestBuildJSONPB(t *testing.T) {
+	comment := func(d protoreflect.Descriptor) string {
+		return "about " + string(d.Name())
+	}
+	routes := []Route{{Service: "grpc.health.v1.Health", Method: "Check", HTTPMethods: []string{"POST"}, Path: "/check"}}
+	doc := Build("test", "", routes, protoregistry.GlobalFiles, Options{JSONPB: true, Comment: comment})
+
+	resp := doc.Components.Schemas["grpc.health.v1.HealthCheckResponse"]
+	assert.Equal(t, "about HealthCheckResponse", resp.Description)
+	status := resp.Properties["status"]
+	assert.Equal(t, "string", status.Type)
+	assert.Contains(t, status.Enum, "SERVING")
+	assert.Equal(t, "about status", status.Description)
+}